@@ -11,38 +11,125 @@ const DefaultMetricsHTML = `<!DOCTYPE html>
         th { background-color: #f2f2f2; }
         .gauge { color: #2196F3; font-weight: bold; }
         .counter { color: #4CAF50; font-weight: bold; }
+        .histogram { color: #9C27B0; font-weight: bold; }
+        .summary { color: #FF5722; font-weight: bold; }
         .metric-name { font-family: monospace; }
+        .label { font-family: monospace; background-color: #eceff1; border-radius: 3px; padding: 1px 4px; }
+        .stale { color: #999; background-color: #fff3e0; }
+        .description { color: #666; font-size: 0.9em; }
     </style>
 </head>
 <body>
     <h1>Metrics Dashboard</h1>
-    <p>Total metrics: <strong>{{len .}}</strong></p>
+    <p>Total metrics: <strong id="total">{{len .}}</strong></p>
     
-    {{if .}}
-    <table>
+    <table id="metrics"{{if not .}} hidden{{end}}>
         <tr>
             <th>Name</th>
+            <th>Labels</th>
             <th>Type</th>
             <th>Value</th>
+            <th>Owner</th>
+            <th>Updated</th>
         </tr>
         {{range .}}
-        <tr>
-            <td class="metric-name">{{.ID}}</td>
+        <tr data-key="{{.MType}}|{{.ID}}|{{range $k, $v := .Labels}}{{$k}}={{$v}},{{end}}"{{if .Stale}} class="stale"{{end}}>
+            <td><span class="metric-name">{{.ID}}</span>{{with .Meta.Description}}<div class="description">{{.}}</div>{{end}}</td>
+            <td class="metric-labels">{{range $k, $v := .Labels}}<span class="label">{{$k}}={{$v}}</span> {{end}}</td>
             <td class="{{.MType}}">{{.MType}}</td>
-        <td>
+        <td><span class="metric-value">
             {{if eq .MType "gauge"}}
                 {{if .Value}}{{printf "%.6g" (derefFloat .Value)}}{{end}}
             {{else if eq .MType "counter"}}
                 {{if .Delta}}{{printf "%d" (derefInt .Delta)}}{{end}}
+            {{else if eq .MType "histogram"}}
+                {{with .Histogram}}count {{.Count}}, sum {{printf "%.6g" .Sum}}, mean {{printf "%.6g" .Mean}}{{end}}
+            {{else if eq .MType "summary"}}
+                {{with .Summary}}count {{.Count}}, p50 {{printf "%.6g" (.Quantile 0.5)}}, p99 {{printf "%.6g" (.Quantile 0.99)}}{{end}}
             {{end}}
+            </span>{{.Meta.Unit}}
         </td>
-
+        <td>{{.Meta.Owner}}</td>
+        <td class="metric-updated">{{if not .UpdatedAt.IsZero}}{{.Age}} ago{{if .Stale}} (stale){{end}}{{end}}</td>
         </tr>
         {{end}}
     </table>
-    {{else}}
-    <p>No metrics available</p>
-    {{end}}
+    {{if not .}}<p id="empty">No metrics available</p>{{end}}
+    <script>
+        // Живое обновление по потоку /api/stream: значения меняются в строках
+        // таблицы, новые серии добавляются в конец
+        (function () {
+            if (!window.EventSource) return;
+            var table = document.getElementById("metrics");
+            var fmt = function (v) { return String(+Number(v).toPrecision(6)); };
+            var key = function (m) {
+                var labels = m.labels || {};
+                return m.type + "|" + m.id + "|" + Object.keys(labels).sort().map(function (k) {
+                    return k + "=" + labels[k] + ",";
+                }).join("");
+            };
+            var quantile = function (s, q) {
+                var g = (1 + s.accuracy) / (1 - s.accuracy), rank = q * (s.count - 1), acc = 0, i;
+                var clamp = function (v) { return Math.min(Math.max(v, s.min), s.max); };
+                var value = function (i) { return 2 * Math.pow(g, i) / (g + 1); };
+                var neg = s.negative.counts || [], pos = s.positive.counts || [];
+                for (i = neg.length - 1; i >= 0; i--) {
+                    if ((acc += neg[i]) > rank) return clamp(-value(s.negative.offset + i));
+                }
+                if ((acc += s.zero) > rank) return clamp(0);
+                for (i = 0; i < pos.length; i++) {
+                    if ((acc += pos[i]) > rank) return clamp(value(s.positive.offset + i));
+                }
+                return s.max;
+            };
+            var display = function (m) {
+                switch (m.type) {
+                case "gauge": return m.value == null ? "" : fmt(m.value);
+                case "counter": return m.delta == null ? "" : String(m.delta);
+                case "histogram":
+                    var h = m.histogram;
+                    return h ? "count " + h.count + ", sum " + fmt(h.sum) + ", mean " + fmt(h.count ? h.sum / h.count : 0) : "";
+                case "summary":
+                    var s = m.summary;
+                    return s ? "count " + s.count + ", p50 " + fmt(quantile(s, 0.5)) + ", p99 " + fmt(quantile(s, 0.99)) : "";
+                }
+                return "";
+            };
+            var addRow = function (m) {
+                var row = table.insertRow(-1), labels = m.labels || {};
+                row.dataset.key = key(m);
+                row.insertCell(-1).innerHTML = '<span class="metric-name"></span>';
+                row.cells[0].firstChild.textContent = m.id;
+                row.insertCell(-1).className = "metric-labels";
+                Object.keys(labels).sort().forEach(function (k) {
+                    var span = document.createElement("span");
+                    span.className = "label";
+                    span.textContent = k + "=" + labels[k];
+                    row.cells[1].append(span, " ");
+                });
+                row.insertCell(-1).className = m.type;
+                row.cells[2].textContent = m.type;
+                row.insertCell(-1).innerHTML = '<span class="metric-value"></span>';
+                row.insertCell(-1);
+                row.insertCell(-1).className = "metric-updated";
+                document.getElementById("total").textContent = table.rows.length - 1;
+                var empty = document.getElementById("empty");
+                if (empty) empty.remove();
+                table.hidden = false;
+                return row;
+            };
+            new EventSource("/api/stream").addEventListener("update", function (e) {
+                var m = JSON.parse(e.data), k = key(m), row;
+                for (var i = 1; i < table.rows.length && !row; i++) {
+                    if (table.rows[i].dataset.key === k) row = table.rows[i];
+                }
+                row = row || addRow(m);
+                row.querySelector(".metric-value").textContent = display(m);
+                row.querySelector(".metric-updated").textContent = "0s ago";
+                row.classList.remove("stale");
+            });
+        })();
+    </script>
 </body>
 </html>
 `
@@ -25,5 +25,5 @@ func InitializeApp() (*app.Agent, error) {
 	zl.InitLogger(cfg.Env)
 	defer zl.Log.Sync()
 
-	return app.NewAgent(cfg), nil
+	return app.NewAgent(cfg)
 }
@@ -13,8 +13,17 @@ func initializeApp() (*app.Server, error) {
 		app.WithStore(),
 		app.WithService(),
 		app.WithAuditService(),
+		app.WithHistoryService(),
+		app.WithCompactionService(),
+		app.WithSilenceService(),
+		app.WithMetadataService(),
+		app.WithIdempotencyService(),
+		app.WithAlertService(),
+		app.WithStatsdService(),
+		app.WithGraphiteService(),
 		app.WithCache(),
 		app.WithHandler(),
+		app.WithGRPCServer(),
 		app.WithRestoreData(),
 		app.WithBootstrap())
 	if err != nil {
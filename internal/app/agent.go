@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/signal"
 	"sync"
@@ -16,12 +17,23 @@ import (
 type Agent struct {
 	Cfg       *config.AgentConfig
 	Collector *agent.MetricsCollector
-	Sender    *agent.MetricsSender
+	Sender    agent.Transport
 	Sem       *semaphore.Semaphore
 }
 
-func NewAgent(cfg *config.AgentConfig) *Agent {
-	return &Agent{Cfg: cfg, Collector: agent.NewMetricsCollector(), Sender: agent.NewMetricsSender(cfg.Addr), Sem: semaphore.NewSemaphore(int(cfg.RateLimit))}
+func NewAgent(cfg *config.AgentConfig) (*Agent, error) {
+	var sender agent.Transport = agent.NewMetricsSender(cfg.Addr)
+	switch cfg.Transport {
+	case config.TransportGRPC:
+		s, err := agent.NewGRPCSender(cfg.GRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		sender = s
+	case config.TransportWS:
+		sender = agent.NewWSSender(cfg.Addr)
+	}
+	return &Agent{Cfg: cfg, Collector: agent.NewMetricsCollector(), Sender: sender, Sem: semaphore.NewSemaphore(int(cfg.RateLimit))}, nil
 }
 
 func (a *Agent) Run() error {
@@ -31,12 +43,17 @@ func (a *Agent) Run() error {
 	defer stop()
 
 	go a.Collector.RunProcess(ctx, &wg, a.Cfg.PollInterval)
-	go a.Sender.RunProcess(ctx, &wg, a.Cfg.ReportInterval, a.Collector, a.Sem, a.Cfg.Key)
+	go agent.RunSendProcess(ctx, &wg, a.Cfg.ReportInterval, a.Collector, a.Sem, a.Cfg.Key, a.Sender)
 
 	<-ctx.Done()
 	wg.Wait()
 
 	zl.Log.Info("shutting down agent ...")
+	if c, ok := a.Sender.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
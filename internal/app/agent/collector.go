@@ -16,6 +16,12 @@ import (
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 )
 
+// pollCountID - counter числа опросов агента.
+const pollCountID = "PollCount"
+
+// MetricsCollector собирает gauge метрики и считает опросы. Сервер
+// суммирует приращения counter, поэтому pollCount хранит только опросы,
+// еще не отданные на отправку.
 type MetricsCollector struct {
 	metrics   map[string]domain.Metrics
 	pollCount int64
@@ -117,25 +123,41 @@ func (c *MetricsCollector) CollectRuntimeMetrics() {
 
 	// Counter метрики
 	c.pollCount++
-	c.metrics["PollCount"] = domain.Metrics{
-		ID:    "PollCount",
-		MType: domain.Counter,
-		Delta: &c.pollCount,
-	}
 	zl.Log.Debug("collected runtime metrics")
 }
 
+// GetMetrics возвращает текущие gauge метрики и приращение PollCount с
+// прошлого вызова. Приращение забирается из сборщика: если сервер отклонил
+// отчет, его нужно вернуть через Restore.
 func (c *MetricsCollector) GetMetrics() []domain.Metrics {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	result := make([]domain.Metrics, 0, len(c.metrics))
+	result := make([]domain.Metrics, 0, len(c.metrics)+1)
 	for _, metric := range c.metrics {
 		result = append(result, metric)
 	}
+	if c.pollCount > 0 {
+		delta := c.pollCount
+		c.pollCount = 0
+		result = append(result, domain.Metrics{ID: pollCountID, MType: domain.Counter, Delta: &delta})
+	}
 	return result
 }
 
+// Restore возвращает в сборщик приращения counter из батча, который сервер
+// отклонил и не применил, чтобы они ушли со следующим отчетом.
+func (c *MetricsCollector) Restore(metrics []domain.Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range metrics {
+		if m.ID == pollCountID && m.MType == domain.Counter && m.Delta != nil {
+			c.pollCount += *m.Delta
+		}
+	}
+}
+
 func (c *MetricsCollector) RunProcess(ctx context.Context, wg *sync.WaitGroup, pollInterval uint) {
 	ticker := time.NewTicker(time.Duration(pollInterval) * time.Second)
 	defer ticker.Stop()
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	metricpb "github.com/bigsm0uk/metrics-alert-server/pkg/proto/metric"
+)
+
+const sendTimeout = 10 * time.Second
+
+// GRPCSender отправляет метрики через gRPC-метод UpdateMetrics со сжатием gzip.
+type GRPCSender struct {
+	conn   *grpc.ClientConn
+	client metricpb.MetricsClient
+}
+
+// NewGRPCSender создает клиент gRPC API сервера. Соединение устанавливается лениво.
+func NewGRPCSender(addr string) (*GRPCSender, error) {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCSender{conn: conn, client: metricpb.NewMetricsClient(conn)}, nil
+}
+
+// SendMetricsV2 отправляет батч метрик, подписывая запрос ключом key.
+// Непустой idempotencyKey передается в метаданных idempotency-key, поэтому
+// повтор после таймаута не применяет батч второй раз. Временные ошибки
+// повторяются до maxRetries раз.
+func (s *GRPCSender) SendMetricsV2(metrics []domain.Metrics, key, idempotencyKey string) error {
+	if len(metrics) == 0 {
+		zl.Log.Debug("no metrics to send, skipping")
+		return nil
+	}
+
+	req := &metricpb.UpdateMetricsRequest{Metrics: make([]*metricpb.Metric, len(metrics))}
+	for i, m := range metrics {
+		req.Metrics[i] = &metricpb.Metric{Id: m.ID, Type: m.MType, Labels: m.Labels, Delta: m.Delta, Value: m.Value}
+	}
+	if key != "" {
+		if err := req.Sign(key); err != nil {
+			zl.Log.Error("failed to sign metrics", zap.Error(err))
+			return err
+		}
+	}
+
+	base := context.Background()
+	if idempotencyKey != "" {
+		base = metadata.AppendToOutgoingContext(base, metricpb.IdempotencyKeyMetadataKey, idempotencyKey)
+	}
+
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryDelay)
+		}
+		ctx, cancel := context.WithTimeout(base, sendTimeout)
+		_, err = s.client.UpdateMetrics(ctx, req)
+		cancel()
+		if err == nil || !isRetryable(err) {
+			break
+		}
+	}
+	if isRejected(err) {
+		err = fmt.Errorf("%w: %w", errRejected, err)
+	}
+	if err != nil {
+		zl.Log.Error("failed to send metrics batch",
+			zap.Int("metrics_count", len(metrics)),
+			zap.Error(err))
+		return err
+	}
+
+	zl.Log.Debug("metrics batch sent", zap.Int("metrics_count", len(metrics)))
+	return nil
+}
+
+// isRetryable сообщает, стоит ли повторить запрос. Aborted сервер
+// возвращает, пока запрос с тем же ключом идемпотентности еще выполняется.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// isRejected сообщает, что сервер отклонил батч, не применив его.
+func isRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.FailedPrecondition:
+		return true
+	}
+	return false
+}
+
+// Close закрывает соединение с сервером.
+func (s *GRPCSender) Close() error {
+	return s.conn.Close()
+}
@@ -2,7 +2,10 @@ package agent
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -17,6 +20,9 @@ import (
 	"github.com/bigsm0uk/metrics-alert-server/pkg/util/hasher"
 )
 
+// errRejected - сервер отклонил батч и не применил его; повтор не поможет.
+var errRejected = errors.New("rejected by server")
+
 type MetricsSender struct {
 	client    *resty.Client
 	serverURL string
@@ -27,6 +33,11 @@ const (
 	retryDelay = time.Second
 )
 
+// idempotencyKeyHeader - заголовок ключа идемпотентности. Ключ создается на
+// отчет, и повторы resty и reporter передают тот же ключ, поэтому сервер не
+// применяет приращения counter дважды, если потерялся только ответ.
+const idempotencyKeyHeader = "Idempotency-Key"
+
 func NewMetricsSender(serverURL string) *MetricsSender {
 	c := resty.New()
 	c.SetRetryCount(maxRetries)
@@ -37,7 +48,9 @@ func NewMetricsSender(serverURL string) *MetricsSender {
 	}
 }
 
-func (s *MetricsSender) SendMetricsV2(metrics []domain.Metrics, key string) error {
+// SendMetricsV2 отправляет батч на /updates. Непустой idempotencyKey
+// передается в заголовке Idempotency-Key.
+func (s *MetricsSender) SendMetricsV2(metrics []domain.Metrics, key, idempotencyKey string) error {
 	if len(metrics) == 0 {
 		zl.Log.Debug("no metrics to send, skipping")
 		return nil
@@ -59,11 +72,17 @@ func (s *MetricsSender) SendMetricsV2(metrics []domain.Metrics, key string) erro
 	req := s.client.R(). // TODO если ключ пустой, то не нужно устанавливать хеш
 				SetHeader("Content-Type", "application/json").
 				SetHeader("Content-Encoding", "gzip")
+	if idempotencyKey != "" {
+		req.SetHeader(idempotencyKeyHeader, idempotencyKey)
+	}
 	if key != "" {
 		req.SetHeader("HashSHA256", hasher.Hash(string(jsonMetrics), key))
 	}
 	req.SetBody(compressedData)
 	resp, err := req.Post(url)
+	if err == nil && resp.IsError() {
+		err = responseError(resp)
+	}
 	if err != nil {
 		zl.Log.Error("failed to send metrics batch",
 			zap.Int("metrics_count", len(metrics)),
@@ -79,6 +98,17 @@ func (s *MetricsSender) SendMetricsV2(metrics []domain.Metrics, key string) erro
 	return nil
 }
 
+// responseError возвращает ошибку для ответа с кодом ошибки. Ответ 4xx
+// означает, что сервер запрос не применил; исключение - 409, который
+// сервер отдает, пока запрос с тем же ключом идемпотентности еще выполняется.
+func responseError(resp *resty.Response) error {
+	code := resp.StatusCode()
+	if code >= http.StatusBadRequest && code < http.StatusInternalServerError && code != http.StatusConflict {
+		return fmt.Errorf("%w: server responded with %s", errRejected, resp.Status())
+	}
+	return fmt.Errorf("server responded with %s", resp.Status())
+}
+
 // SendMetricV2 отправляет одну метрику со сжатием
 func (s *MetricsSender) SendMetricV2(metric domain.Metrics, key string) error {
 	jsonMetric, err := json.Marshal(metric)
@@ -97,12 +127,16 @@ func (s *MetricsSender) SendMetricV2(metric domain.Metrics, key string) error {
 	url := fmt.Sprintf("%s/update", s.serverURL)
 	req := s.client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Content-Encoding", "gzip")
+		SetHeader("Content-Encoding", "gzip").
+		SetHeader(idempotencyKeyHeader, rand.Text())
 	if key != "" {
 		req.SetHeader("HashSHA256", hasher.Hash(string(jsonMetric), key))
 	}
 	req.SetBody(compressedData)
 	resp, err := req.Post(url)
+	if err == nil && resp.IsError() {
+		err = responseError(resp)
+	}
 	if err != nil {
 		zl.Log.Error("failed to send metric",
 			zap.String("metric", metric.ID),
@@ -118,11 +152,28 @@ func (s *MetricsSender) SendMetricV2(metric domain.Metrics, key string) error {
 	return nil
 }
 
+// Collector отдает метрики для отправки. GetMetrics забирает приращения
+// counter, а Restore возвращает приращения батча, который сервер отклонил.
 type Collector interface {
 	GetMetrics() []domain.Metrics
+	Restore(metrics []domain.Metrics)
+}
+
+// Transport отправляет батч метрик на сервер (HTTP, gRPC или WebSocket).
+// Повторная отправка с тем же idempotencyKey применяется сервером один раз.
+// Ошибка, обернутая в errRejected, означает, что сервер батч не применил.
+type Transport interface {
+	SendMetricsV2(metrics []domain.Metrics, key, idempotencyKey string) error
 }
 
 func (s *MetricsSender) RunProcess(ctx context.Context, wg *sync.WaitGroup, reportInterval uint, collector Collector, sem *semaphore.Semaphore, key string) {
+	RunSendProcess(ctx, wg, reportInterval, collector, sem, key, s)
+}
+
+// RunSendProcess раз в reportInterval секунд отправляет собранные метрики через транспорт t,
+// ограничивая число одновременных отправок семафором.
+func RunSendProcess(ctx context.Context, wg *sync.WaitGroup, reportInterval uint, collector Collector, sem *semaphore.Semaphore, key string, t Transport) {
+	r := newReporter(collector, t, key)
 	ticker := time.NewTicker(time.Duration(reportInterval) * time.Second)
 	defer ticker.Stop()
 	for {
@@ -130,16 +181,75 @@ func (s *MetricsSender) RunProcess(ctx context.Context, wg *sync.WaitGroup, repo
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			metrics := collector.GetMetrics()
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
 				sem.Acquire()
 				defer sem.Release()
-				if err := s.SendMetricsV2(metrics, key); err != nil {
-					zl.Log.Error("failed to send metrics", zap.Error(err))
-				}
+				r.report()
 			}()
 		}
 	}
 }
+
+// pendingReport - отчет, доставка которого не подтверждена, вместе с его
+// ключом идемпотентности.
+type pendingReport struct {
+	metrics        []domain.Metrics
+	idempotencyKey string
+}
+
+// reporter отправляет отчеты по одному. Если ответ на отчет потерян,
+// сервер мог его уже применить, поэтому отчет повторяется целиком с тем же
+// ключом идемпотентности, а новые приращения counter забираются из
+// сборщика только после подтверждения.
+type reporter struct {
+	mu        sync.Mutex
+	collector Collector
+	transport Transport
+	key       string
+	pending   *pendingReport
+}
+
+func newReporter(collector Collector, t Transport, key string) *reporter {
+	return &reporter{collector: collector, transport: t, key: key}
+}
+
+// report повторяет неподтвержденный отчет, а после его доставки отправляет
+// новый. Пока предыдущая отправка не завершилась, тик пропускается: опросы
+// копятся в сборщике и уйдут со следующим отчетом.
+func (r *reporter) report() {
+	if !r.mu.TryLock() {
+		zl.Log.Debug("previous report is still in flight, skipping")
+		return
+	}
+	defer r.mu.Unlock()
+
+	if r.pending != nil {
+		if !r.send(r.pending) {
+			return
+		}
+	}
+	r.send(&pendingReport{metrics: r.collector.GetMetrics(), idempotencyKey: rand.Text()})
+}
+
+// send отправляет отчет и сообщает, завершена ли работа с ним. Отклоненный
+// сервером отчет не применен, поэтому его приращения возвращаются в
+// сборщик; отчет с неизвестным исходом остается в pending.
+func (r *reporter) send(p *pendingReport) bool {
+	err := r.transport.SendMetricsV2(p.metrics, r.key, p.idempotencyKey)
+	switch {
+	case err == nil:
+		r.pending = nil
+		return true
+	case errors.Is(err, errRejected):
+		zl.Log.Error("metrics report rejected", zap.Error(err))
+		r.collector.Restore(p.metrics)
+		r.pending = nil
+		return true
+	default:
+		zl.Log.Error("failed to send metrics, will retry with the same idempotency key", zap.Error(err))
+		r.pending = p
+		return false
+	}
+}
@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/cache"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/router"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/handler"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+	"github.com/bigsm0uk/metrics-alert-server/internal/service"
+)
+
+// flakyServer проксирует запросы на настоящий роутер сервера. Запросы с
+// номерами из fail отклоняются без передачи на сервер, а с номерами из lost
+// применяются сервером, но ответ на них теряется.
+type flakyServer struct {
+	mu       sync.Mutex
+	requests int
+	fail     map[int]int
+	lost     map[int]bool
+	next     http.Handler
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	n := s.requests
+	s.mu.Unlock()
+
+	if code := s.fail[n]; code != 0 {
+		w.WriteHeader(code)
+		return
+	}
+	if s.lost[n] {
+		s.next.ServeHTTP(httptest.NewRecorder(), r)
+		w.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+	s.next.ServeHTTP(w, r)
+}
+
+// newMetricsServer создает роутер сервера поверх репозитория в памяти с
+// сервисом идемпотентности, как в cmd/server.
+func newMetricsServer(t *testing.T) (http.Handler, *mem.MemRepository) {
+	t.Helper()
+	cfg := config.InitDefaultConfig()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	svc := service.NewService(repo, nil)
+	as := service.NewAuditService(&cfg.Audit, zap.NewNop())
+	idem := service.NewIdempotencyService(mem.NewIdempotencyRepository(0), &cfg.Idempotency, zap.NewNop())
+	h := handler.NewMetricHandler(svc, "", "", as, cache.New(cache.DefaultExpiration, 0), handler.WithIdempotencyService(idem))
+	return router.NewRouter(h, ""), repo
+}
+
+func TestReporter_CounterTotalEqualsPolls(t *testing.T) {
+	tests := []struct {
+		name string
+		fail map[int]int
+		lost map[int]bool
+	}{
+		{name: "all reports delivered"},
+		{name: "failed reports are retried", fail: map[int]int{1: http.StatusInternalServerError, 2: http.StatusBadGateway, 5: http.StatusInternalServerError}},
+		{name: "lost responses are not applied twice", lost: map[int]bool{1: true, 3: true, 4: true, 7: true}},
+		{name: "rejected reports roll forward", fail: map[int]int{2: http.StatusBadRequest, 6: http.StatusBadRequest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, repo := newMetricsServer(t)
+			server := httptest.NewServer(&flakyServer{fail: tt.fail, lost: tt.lost, next: next})
+			defer server.Close()
+
+			c := NewMetricsCollector()
+			r := newReporter(c, NewMetricsSender(server.URL), "")
+			polls := 0
+			for report := 1; report <= 8; report++ {
+				for range report % 3 {
+					c.CollectRuntimeMetrics()
+					polls++
+				}
+				r.report()
+			}
+			// Досылаем отчет, если последний остался неподтвержденным
+			r.report()
+
+			stored, err := repo.Metric(context.Background(), pollCountID, domain.Counter, nil)
+			require.NoError(t, err)
+			assert.Equal(t, int64(polls), *stored.Delta)
+			assert.Nil(t, r.pending)
+			assert.Zero(t, c.pollCount)
+		})
+	}
+}
+
+func TestReporter_ResendsPendingWithSameKey(t *testing.T) {
+	var keys []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c := NewMetricsCollector()
+	r := newReporter(c, NewMetricsSender(server.URL), "")
+	c.CollectRuntimeMetrics()
+	r.report()
+	require.NotNil(t, r.pending)
+	assert.Equal(t, int64(1), pollDelta(t, r.pending.metrics))
+
+	// Новые опросы не попадают в неподтвержденный отчет
+	c.CollectRuntimeMetrics()
+	r.report()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.NotEqual(t, keys[1], keys[2])
+	assert.Nil(t, r.pending)
+}
+
+func TestMetricsCollector_RestoreRollsForward(t *testing.T) {
+	c := NewMetricsCollector()
+	c.CollectRuntimeMetrics()
+	c.CollectRuntimeMetrics()
+
+	first := c.GetMetrics()
+	assert.Equal(t, int64(2), pollDelta(t, first))
+	// Приращение забрано: следующий отчет без новых опросов не содержит PollCount
+	assert.Zero(t, pollDelta(t, c.GetMetrics()))
+
+	c.Restore(first)
+	c.CollectRuntimeMetrics()
+	assert.Equal(t, int64(3), pollDelta(t, c.GetMetrics()))
+}
+
+func pollDelta(t *testing.T, metrics []domain.Metrics) int64 {
+	t.Helper()
+	for _, m := range metrics {
+		if m.ID == pollCountID {
+			require.NotNil(t, m.Delta)
+			return *m.Delta
+		}
+	}
+	return 0
+}
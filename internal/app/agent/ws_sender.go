@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/ws"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// WSSender отправляет батчи метрик по одному долгоживущему WebSocket
+// соединению: агент аутентифицируется при подключении, а каждый батч
+// подтверждается сервером. При разрыве соединение устанавливается заново
+// при следующей отправке.
+type WSSender struct {
+	url   string
+	agent string
+
+	mu   sync.Mutex // Сериализует обмен батч-подтверждение
+	conn *websocket.Conn
+	seq  uint64
+}
+
+// NewWSSender создает отправителя для сервера serverURL (http или https).
+// Соединение устанавливается лениво.
+func NewWSSender(serverURL string) *WSSender {
+	agent, err := os.Hostname()
+	if err != nil {
+		agent = "agent"
+	}
+	return &WSSender{url: strings.TrimSuffix(serverURL, "/") + ws.Path, agent: agent}
+}
+
+// SendMetricsV2 отправляет батч и ждет подтверждения. Ошибки соединения
+// повторяются до maxRetries раз с переподключением; key используется для
+// аутентификации при подключении. Непустой idempotencyKey передается с
+// батчем, поэтому повтор после потерянного подтверждения сервер не
+// применяет второй раз.
+func (s *WSSender) SendMetricsV2(metrics []domain.Metrics, key, idempotencyKey string) error {
+	if len(metrics) == 0 {
+		zl.Log.Debug("no metrics to send, skipping")
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryDelay)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = s.send(ctx, metrics, key, idempotencyKey)
+		cancel()
+		if err == nil || errors.Is(err, errRejected) {
+			break
+		}
+		s.reset()
+	}
+	if err != nil {
+		zl.Log.Error("failed to send metrics batch",
+			zap.Int("metrics_count", len(metrics)),
+			zap.Error(err))
+		return err
+	}
+
+	zl.Log.Debug("metrics batch sent", zap.Int("metrics_count", len(metrics)))
+	return nil
+}
+
+func (s *WSSender) send(ctx context.Context, metrics []domain.Metrics, key, idempotencyKey string) error {
+	if s.conn == nil {
+		if err := s.connect(ctx, key); err != nil {
+			return err
+		}
+	}
+	s.seq++
+	batch := ws.Message{Type: ws.TypeBatch, Seq: s.seq, IdempotencyKey: idempotencyKey, Metrics: metrics}
+	if err := wsjson.Write(ctx, s.conn, batch); err != nil {
+		return fmt.Errorf("write batch: %w", err)
+	}
+	var reply ws.Message
+	if err := wsjson.Read(ctx, s.conn, &reply); err != nil {
+		return fmt.Errorf("read ack: %w", err)
+	}
+	switch {
+	case reply.Seq != s.seq:
+		return fmt.Errorf("ack for batch %d, expected %d", reply.Seq, s.seq)
+	case reply.Type == ws.TypeNack && reply.Retry:
+		return fmt.Errorf("batch not applied: %s", reply.Error)
+	case reply.Type == ws.TypeNack:
+		return fmt.Errorf("%w: %s", errRejected, reply.Error)
+	case reply.Type != ws.TypeAck:
+		return fmt.Errorf("unexpected message type %q", reply.Type)
+	}
+	return nil
+}
+
+// connect подключается к серверу и проходит аутентификацию, подписывая
+// приветствие вместе с nonce соединения.
+func (s *WSSender) connect(ctx context.Context, key string) error {
+	conn, _, err := websocket.Dial(ctx, s.url, &websocket.DialOptions{CompressionMode: websocket.CompressionContextTakeover})
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	conn.SetReadLimit(ws.MaxMessageSize)
+	var challenge ws.Message
+	if err := wsjson.Read(ctx, conn, &challenge); err != nil {
+		conn.CloseNow()
+		return fmt.Errorf("read challenge: %w", err)
+	}
+	if challenge.Type != ws.TypeChallenge {
+		conn.CloseNow()
+		return fmt.Errorf("auth: unexpected message type %q", challenge.Type)
+	}
+	if err := wsjson.Write(ctx, conn, ws.NewAuth(s.agent, key, challenge.Nonce, time.Now())); err != nil {
+		conn.CloseNow()
+		return fmt.Errorf("write auth: %w", err)
+	}
+	var reply ws.Message
+	if err := wsjson.Read(ctx, conn, &reply); err != nil {
+		conn.CloseNow()
+		if websocket.CloseStatus(err) == websocket.StatusPolicyViolation {
+			return fmt.Errorf("%w: %w", errRejected, err)
+		}
+		return fmt.Errorf("read auth ack: %w", err)
+	}
+	if reply.Type != ws.TypeAck {
+		conn.CloseNow()
+		return fmt.Errorf("auth: unexpected message type %q", reply.Type)
+	}
+	zl.Log.Info("websocket connected", zap.String("url", s.url))
+	s.conn, s.seq = conn, 0
+	return nil
+}
+
+// reset разрывает соединение после ошибки обмена.
+func (s *WSSender) reset() {
+	if s.conn != nil {
+		s.conn.CloseNow()
+		s.conn = nil
+	}
+}
+
+// Close закрывает соединение с сервером.
+func (s *WSSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close(websocket.StatusNormalClosure, "agent is shutting down")
+	s.conn = nil
+	return err
+}
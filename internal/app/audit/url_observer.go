@@ -24,13 +24,18 @@ func (o *URLObserver) GetID() string {
 	return fmt.Sprintf("url-observer-%s", o.url)
 }
 
-func NewURLObserver(url string, log *zap.Logger) *URLObserver {
-	logger := log.Named("audit-url-observer")
-	restyClient := resty.New().SetBaseURL(url).
+// NewRestyClient создает HTTP-клиент для отправки JSON на url с повторами при ошибках.
+func NewRestyClient(url string) *resty.Client {
+	return resty.New().SetBaseURL(url).
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Content-Encoding", "gzip").
 		SetRetryCount(3).
 		SetRetryWaitTime(time.Second)
+}
+
+func NewURLObserver(url string, log *zap.Logger) *URLObserver {
+	logger := log.Named("audit-url-observer")
+	restyClient := NewRestyClient(url).
+		SetHeader("Content-Encoding", "gzip")
 
 	return &URLObserver{url: url, log: logger, client: restyClient}
 }
@@ -118,3 +118,12 @@ func (c *Mem) Set(key string, value any, duration time.Duration) {
 		ExpiresAt: time.Unix(0, expiration),
 	}
 }
+
+// Delete удаляет ключ, не дожидаясь истечения срока
+func (c *Mem) Delete(key string) {
+	c.mu.Lock()
+
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -14,8 +15,17 @@ type AgentConfig struct {
 	PollInterval   uint   `env:"POLL_INTERVAL"`
 	RateLimit      uint   `env:"RATE_LIMIT"`
 	Key            string `env:"KEY"`
+	Transport      string `env:"TRANSPORT"`    // http, grpc или ws
+	GRPCAddr       string `env:"GRPC_ADDRESS"` // Адрес gRPC API сервера, используется при Transport=grpc
 }
 
+// Транспорты отправки метрик агентом.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+	TransportWS   = "ws" // WebSocket соединение с сервером по адресу Addr
+)
+
 func LoadAgentConfig() (*AgentConfig, error) {
 	cfg := &AgentConfig{}
 	flag.StringVar(&cfg.Env, "e", EnvDevelopment, "environment")
@@ -24,9 +34,14 @@ func LoadAgentConfig() (*AgentConfig, error) {
 	flag.UintVar(&cfg.PollInterval, "p", 2, "poll interval")
 	flag.UintVar(&cfg.RateLimit, "l", 1, "rate limit")
 	flag.StringVar(&cfg.Key, "k", "1234567890", "key")
+	flag.StringVar(&cfg.Transport, "t", TransportHTTP, "transport (http, grpc or ws)")
+	flag.StringVar(&cfg.GRPCAddr, "g", "localhost:3200", "grpc server address")
 	flag.Parse()
 
 	err := cleanenv.ReadEnv(cfg)
+	if err == nil && cfg.Transport != TransportHTTP && cfg.Transport != TransportGRPC && cfg.Transport != TransportWS {
+		err = fmt.Errorf("unknown transport %q", cfg.Transport)
+	}
 
 	if !isValidURL(cfg.Addr) {
 		cfg.Addr = "http://" + cfg.Addr
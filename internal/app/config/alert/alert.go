@@ -0,0 +1,75 @@
+package alert
+
+import "time"
+
+// AlertConfig описывает правила алертинга и периодичность их вычисления.
+type AlertConfig struct {
+	EvaluationInterval time.Duration       `yaml:"evaluation_interval" env:"ALERT_EVALUATION_INTERVAL" env-default:"10s"`
+	Rules              []RuleConfig        `yaml:"rules"`
+	Absence            []AbsenceRuleConfig `yaml:"absence"`
+	Channels           []ChannelConfig     `yaml:"channels"`
+	SilencesFile       string              `yaml:"silences_file" env:"ALERT_SILENCES_FILE" env-default:"silences.json"` // Файл подавлений при хранении в памяти
+	StaleAfter         time.Duration       `yaml:"stale_after" env:"ALERT_STALE_AFTER" env-default:"1m"`                // Порог устаревания метрик на дашборде
+	NotifyTimeout      time.Duration       `yaml:"notify_timeout" env:"ALERT_NOTIFY_TIMEOUT" env-default:"10s"`         // Ограничение на отправку одного уведомления в канал
+}
+
+// RuleConfig описывает одно правило: метрику, условие сравнения с порогом
+// и время, в течение которого условие должно выполняться до срабатывания.
+type RuleConfig struct {
+	Name       string        `yaml:"name"`
+	MetricID   string        `yaml:"metric_id"`
+	MetricType string        `yaml:"metric_type"`
+	Comparison string        `yaml:"comparison"`
+	Threshold  float64       `yaml:"threshold"`
+	For        time.Duration `yaml:"for"`
+	Channels   []string      `yaml:"channels"` // Имена каналов уведомлений
+}
+
+// AbsenceRuleConfig описывает правило отсутствия: одна метрика (MetricID)
+// или набор метрик агента (Pattern), не обновлявшиеся дольше Timeout.
+type AbsenceRuleConfig struct {
+	Name       string        `yaml:"name"`
+	MetricID   string        `yaml:"metric_id"`
+	MetricType string        `yaml:"metric_type"`
+	Pattern    string        `yaml:"pattern"`
+	Timeout    time.Duration `yaml:"timeout"`
+	Channels   []string      `yaml:"channels"`
+}
+
+// Типы каналов уведомлений
+const (
+	ChannelWebhook = "webhook"
+	ChannelFile    = "file"
+	ChannelSMTP    = "smtp"
+)
+
+// ChannelConfig описывает именованный канал доставки уведомлений.
+// В зависимости от Type используется URL, Path или SMTP.
+type ChannelConfig struct {
+	Name    string     `yaml:"name"`
+	Type    string     `yaml:"type"`
+	Enabled *bool      `yaml:"enabled"` // false отключает доставку; правила могут ссылаться на канал
+	URL     string     `yaml:"url"`
+	Path    string     `yaml:"path"`
+	SMTP    SMTPConfig `yaml:"smtp"`
+}
+
+// IsEnabled сообщает, доставляет ли канал уведомления. Канал без поля
+// enabled включен.
+func (cc *ChannelConfig) IsEnabled() bool {
+	return cc.Enabled == nil || *cc.Enabled
+}
+
+// SMTPConfig - параметры отправки уведомлений по email.
+type SMTPConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+func (ac *AlertConfig) IsEnabled() bool {
+	return len(ac.Rules) > 0 || len(ac.Absence) > 0
+}
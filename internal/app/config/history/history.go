@@ -0,0 +1,29 @@
+package history
+
+import "time"
+
+// HistoryConfig описывает хранение истории значений метрик:
+// сроки хранения сырых сэмплов и агрегатов 1m и 1h по умолчанию,
+// периодичность компакции и политики для отдельных метрик.
+type HistoryConfig struct {
+	Retention          time.Duration  `yaml:"retention" env:"HISTORY_RETENTION" env-default:"24h"`                    // Срок хранения сырых сэмплов
+	MinuteRetention    time.Duration  `yaml:"minute_retention" env:"HISTORY_MINUTE_RETENTION" env-default:"168h"`     // Срок хранения агрегатов 1m
+	HourRetention      time.Duration  `yaml:"hour_retention" env:"HISTORY_HOUR_RETENTION" env-default:"2160h"`        // Срок хранения агрегатов 1h
+	MaxSamples         int            `yaml:"max_samples" env:"HISTORY_MAX_SAMPLES" env-default:"43200"`              // Емкость кольцевого буфера серии в памяти
+	CompactionInterval time.Duration  `yaml:"compaction_interval" env:"HISTORY_COMPACTION_INTERVAL" env-default:"1m"` // Периодичность компакции и удаления устаревших данных
+	Policies           []PolicyConfig `yaml:"policies"`
+}
+
+// PolicyConfig переопределяет сроки хранения для метрик, id которых
+// подходит под регулярное выражение Pattern. Применяется первая подходящая политика,
+// незаданные сроки берутся из HistoryConfig.
+type PolicyConfig struct {
+	Pattern         string        `yaml:"pattern"`
+	Retention       time.Duration `yaml:"retention"`
+	MinuteRetention time.Duration `yaml:"minute_retention"`
+	HourRetention   time.Duration `yaml:"hour_retention"`
+}
+
+func (hc *HistoryConfig) IsEnabled() bool {
+	return hc.Retention > 0 && hc.CompactionInterval > 0
+}
@@ -0,0 +1,10 @@
+package idempotency
+
+import "time"
+
+// IdempotencyConfig описывает хранение ключей идемпотентности /update и /updates.
+type IdempotencyConfig struct {
+	TTL             time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"1h"`                           // Время, в течение которого повтор запроса получает сохраненный ответ
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"IDEMPOTENCY_CLEANUP_INTERVAL" env-default:"1m"` // Периодичность удаления истекших ключей
+	MaxKeys         int           `yaml:"max_keys" env:"IDEMPOTENCY_MAX_KEYS" env-default:"100000"`             // Предел числа ключей в памяти; самые старые вытесняются
+}
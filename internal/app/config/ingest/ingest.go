@@ -0,0 +1,32 @@
+package ingest
+
+import "time"
+
+// IngestConfig описывает дополнительные сетевые listener'ы приема метрик.
+type IngestConfig struct {
+	Statsd   StatsdConfig   `yaml:"statsd"`
+	Graphite GraphiteConfig `yaml:"graphite"`
+}
+
+// StatsdConfig описывает UDP listener StatsD. Пустой адрес отключает listener.
+type StatsdConfig struct {
+	Addr          string        `yaml:"addr" env:"STATSD_ADDRESS"`                                    // Адрес UDP, например :8125
+	FlushInterval time.Duration `yaml:"flush_interval" env:"STATSD_FLUSH_INTERVAL" env-default:"10s"` // Период агрегации перед записью
+	MaxKeys       int           `yaml:"max_keys" env:"STATSD_MAX_KEYS" env-default:"10000"`           // Лимит серий за интервал; значения сверх него отбрасываются
+}
+
+func (sc *StatsdConfig) IsEnabled() bool {
+	return sc.Addr != "" && sc.FlushInterval > 0
+}
+
+// GraphiteConfig описывает TCP listener plaintext-протокола Graphite.
+// Значения пишутся батчами по BatchSize или раз в FlushInterval.
+type GraphiteConfig struct {
+	Addr          string        `yaml:"addr" env:"GRAPHITE_ADDRESS"`                                   // Адрес TCP, например :2003
+	BatchSize     int           `yaml:"batch_size" env:"GRAPHITE_BATCH_SIZE" env-default:"1000"`       // Размер батча, при котором запись не ждет таймера
+	FlushInterval time.Duration `yaml:"flush_interval" env:"GRAPHITE_FLUSH_INTERVAL" env-default:"1s"` // Максимальная задержка записи
+}
+
+func (gc *GraphiteConfig) IsEnabled() bool {
+	return gc.Addr != "" && gc.FlushInterval > 0
+}
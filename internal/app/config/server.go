@@ -6,10 +6,15 @@ import (
 
 	"github.com/ilyakaznacheev/cleanenv"
 
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/audit"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/cache"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/history"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/idempotency"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/ingest"
 	S "github.com/bigsm0uk/metrics-alert-server/internal/app/config/storage"
 	Store "github.com/bigsm0uk/metrics-alert-server/internal/app/config/store"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/stream"
 )
 
 const (
@@ -19,14 +24,29 @@ const (
 )
 
 type ServerConfig struct {
-	Env          string            `yaml:"env"  env-default:"development"`
-	Storage      S.StorageConfig   `yaml:"storage" required:"true"`
-	TemplatePath string            `yaml:"template_path" env-default:"api/templates/metrics.html"`
-	Addr         string            `env:"ADDRESS"`
-	Store        Store.StoreConfig `required:"true"`
-	Key          string            `env:"KEY"`
-	Audit        audit.AuditConfig `yaml:"audit"`
-	Cache        cache.CacheConfig `yaml:"cache"`
+	Env          string                        `yaml:"env"  env-default:"development"`
+	Storage      S.StorageConfig               `yaml:"storage" required:"true"`
+	TemplatePath string                        `yaml:"template_path" env-default:"api/templates/metrics.html"`
+	Addr         string                        `env:"ADDRESS"`
+	GRPCAddr     string                        `yaml:"grpc_addr" env:"GRPC_ADDRESS"` // Адрес gRPC API; пустой - gRPC отключен
+	Store        Store.StoreConfig             `required:"true"`
+	Key          string                        `env:"KEY"`
+	Audit        audit.AuditConfig             `yaml:"audit"`
+	Cache        cache.CacheConfig             `yaml:"cache"`
+	Alert        alert.AlertConfig             `yaml:"alert"`
+	History      history.HistoryConfig         `yaml:"history"`
+	Ingest       ingest.IngestConfig           `yaml:"ingest"`
+	Stream       stream.StreamConfig           `yaml:"stream"`
+	Idempotency  idempotency.IdempotencyConfig `yaml:"idempotency"`
+	// Границы бакетов histogram для обновлений через /update/histogram/{id}/{value};
+	// пустой список - domain.DefaultHistogramBuckets
+	HistogramBuckets []float64 `yaml:"histogram_buckets" env:"HISTOGRAM_BUCKETS" env-separator:","`
+	// Относительная точность квантилей summary для обновлений через
+	// /update/summary/{id}/{value}; ноль - domain.DefaultSummaryAccuracy
+	SummaryAccuracy float64 `yaml:"summary_accuracy" env:"SUMMARY_ACCURACY"`
+	// Файл описаний метрик при хранении в памяти; в Postgres описания
+	// хранятся в таблице metric_metadata
+	MetadataFile string `yaml:"metadata_file" env:"METADATA_FILE" env-default:"metadata.json"`
 }
 
 func LoadServerConfig() (*ServerConfig, error) {
@@ -35,6 +55,7 @@ func LoadServerConfig() (*ServerConfig, error) {
 
 	var (
 		flagAddr      = flag.String("a", "", "server address")
+		flagGRPCAddr  = flag.String("g", "", "grpc server address")
 		flagFile      = flag.String("f", "", "path to store file")
 		flagRestore   = flag.Bool("r", true, "restore store from file")
 		flagInterval  = flag.String("i", "", "store interval")
@@ -56,6 +77,9 @@ func LoadServerConfig() (*ServerConfig, error) {
 	if *flagAddr != "" {
 		cfg.Addr = *flagAddr
 	}
+	if *flagGRPCAddr != "" {
+		cfg.GRPCAddr = *flagGRPCAddr
+	}
 	if *flagFile != "" {
 		cfg.Store.FileStoragePath = *flagFile
 	}
@@ -0,0 +1,9 @@
+package stream
+
+import "time"
+
+// StreamConfig описывает поток обновлений метрик /api/stream.
+type StreamConfig struct {
+	Buffer    int           `yaml:"buffer" env:"STREAM_BUFFER" env-default:"256"`       // Число неотправленных обновлений, после которого подписчик отключается
+	Heartbeat time.Duration `yaml:"heartbeat" env:"STREAM_HEARTBEAT" env-default:"15s"` // Периодичность комментариев, поддерживающих соединение
+}
@@ -2,16 +2,22 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"time"
 
+	"google.golang.org/grpc"
+
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/audit"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/cache"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/notify"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/server/store"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
 	"github.com/bigsm0uk/metrics-alert-server/internal/handler"
+	"github.com/bigsm0uk/metrics-alert-server/internal/handler/grpchandler"
 	"github.com/bigsm0uk/metrics-alert-server/internal/repository"
 	"github.com/bigsm0uk/metrics-alert-server/internal/service"
 )
@@ -22,8 +28,18 @@ type Container struct {
 	repository   interfaces.MetricsRepository
 	store        interfaces.MetricsStore
 	service      *service.MetricService
+	stream       *service.StreamService
 	handler      *handler.MetricHandler
 	auditService *service.AuditService
+	alertService *service.AlertService
+	silences     *service.SilenceService
+	metadata     *service.MetadataService
+	idempotency  *service.IdempotencyService
+	history      *service.HistoryService
+	compaction   *service.CompactionService
+	statsd       *service.StatsdService
+	graphite     *service.GraphiteService
+	grpcServer   *grpc.Server
 	cache        interfaces.MetricsCache
 }
 
@@ -104,7 +120,56 @@ func WithStore() ContainerOptions {
 // WithService инициализирует сервис
 func WithService() ContainerOptions {
 	return func(c *Container) error {
-		c.service = service.NewService(c.repository, c.store)
+		c.stream = service.NewStreamService(&c.config.Stream, zl.Log)
+		c.service = service.NewService(c.repository, c.store, service.WithStream(c.stream))
+		return nil
+	}
+}
+
+// WithHistoryService инициализирует сервис истории метрик
+func WithHistoryService() ContainerOptions {
+	return func(c *Container) error {
+		repo, err := repository.InitSampleRepository(c.repository)
+		if err != nil {
+			return err
+		}
+		c.history = service.NewHistoryService(repo, zl.Log)
+		return nil
+	}
+}
+
+// WithCompactionService инициализирует сервис компакции истории метрик
+func WithCompactionService() ContainerOptions {
+	return func(c *Container) error {
+		samples, err := repository.InitSampleRepository(c.repository)
+		if err != nil {
+			return err
+		}
+		rollups, err := repository.InitRollupRepository(c.repository)
+		if err != nil {
+			return err
+		}
+		cs, err := service.NewCompactionService(c.repository, samples, rollups, &c.config.History, zl.Log)
+		if err != nil {
+			return err
+		}
+		c.compaction = cs
+		return nil
+	}
+}
+
+// WithStatsdService инициализирует прием метрик StatsD по UDP
+func WithStatsdService() ContainerOptions {
+	return func(c *Container) error {
+		c.statsd = service.NewStatsdService(c.service, &c.config.Ingest.Statsd, zl.Log)
+		return nil
+	}
+}
+
+// WithGraphiteService инициализирует прием метрик Graphite по TCP
+func WithGraphiteService() ContainerOptions {
+	return func(c *Container) error {
+		c.graphite = service.NewGraphiteService(c.service, &c.config.Ingest.Graphite, zl.Log)
 		return nil
 	}
 }
@@ -120,7 +185,35 @@ func WithCache() ContainerOptions {
 // WithHandler инициализирует обработчик
 func WithHandler() ContainerOptions {
 	return func(c *Container) error {
-		c.handler = handler.NewMetricHandler(c.service, c.config.TemplatePath, c.config.Key, c.auditService, c.cache)
+		if err := domain.NewHistogram(c.config.HistogramBuckets).Validate(); err != nil {
+			return fmt.Errorf("histogram_buckets: %w", err)
+		}
+		if acc := c.config.SummaryAccuracy; acc != 0 {
+			if err := domain.NewSummary(acc).Validate(); err != nil {
+				return fmt.Errorf("summary_accuracy: %w", err)
+			}
+		}
+		c.handler = handler.NewMetricHandler(c.service, c.config.TemplatePath, c.config.Key, c.auditService, c.cache,
+			handler.WithAlertService(c.alertService),
+			handler.WithSilenceService(c.silences),
+			handler.WithMetadataService(c.metadata),
+			handler.WithHistoryService(c.history),
+			handler.WithStreamService(c.stream),
+			handler.WithIdempotencyService(c.idempotency),
+			handler.WithStaleAfter(c.config.Alert.StaleAfter),
+			handler.WithHistogramBuckets(c.config.HistogramBuckets),
+			handler.WithSummaryAccuracy(c.config.SummaryAccuracy),
+		)
+		return nil
+	}
+}
+
+// WithGRPCServer инициализирует gRPC API поверх сервиса метрик
+func WithGRPCServer() ContainerOptions {
+	return func(c *Container) error {
+		c.grpcServer = grpchandler.NewServer(
+			grpchandler.NewMetricServer(c.service, c.auditService, grpchandler.WithIdempotencyService(c.idempotency)),
+			c.config.Key)
 		return nil
 	}
 }
@@ -161,7 +254,56 @@ func WithAuditService() ContainerOptions {
 	}
 }
 
+// WithSilenceService инициализирует сервис подавлений уведомлений
+func WithSilenceService() ContainerOptions {
+	return func(c *Container) error {
+		repo, err := repository.InitSilenceRepository(c.config, c.repository)
+		if err != nil {
+			return err
+		}
+		c.silences = service.NewSilenceService(repo, zl.Log)
+		return nil
+	}
+}
+
+// WithMetadataService инициализирует реестр описаний метрик
+func WithMetadataService() ContainerOptions {
+	return func(c *Container) error {
+		repo, err := repository.InitMetadataRepository(c.config, c.repository)
+		if err != nil {
+			return err
+		}
+		c.metadata = service.NewMetadataService(repo, zl.Log)
+		return nil
+	}
+}
+
+// WithIdempotencyService инициализирует хранилище ключей идемпотентности
+func WithIdempotencyService() ContainerOptions {
+	return func(c *Container) error {
+		repo := repository.InitIdempotencyRepository(c.config, c.repository)
+		c.idempotency = service.NewIdempotencyService(repo, &c.config.Idempotency, zl.Log)
+		return nil
+	}
+}
+
+// WithAlertService инициализирует сервис алертинга
+func WithAlertService() ContainerOptions {
+	return func(c *Container) error {
+		notifiers, err := notify.CreateNotifiers(&c.config.Alert, zl.Log)
+		if err != nil {
+			return err
+		}
+		as, err := service.NewAlertService(c.repository, &c.config.Alert, notifiers, c.silences, zl.Log)
+		if err != nil {
+			return err
+		}
+		c.alertService = as
+		return nil
+	}
+}
+
 // Build создает новый сервер
 func Build(c *Container) *Server {
-	return NewServer(c.config, c.handler, c.store, c.auditService)
+	return NewServer(c.config, c.handler, c.store, c.auditService, c.alertService, c.compaction, c.statsd, c.graphite, c.grpcServer)
 }
@@ -0,0 +1,231 @@
+// Package exposition реализует отдачу метрик во внешних текстовых форматах.
+package exposition
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// PrometheusContentType - Content-Type текстового формата экспозиции Prometheus.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const counterSuffix = "_total"
+
+// SanitizeName приводит идентификатор метрики к допустимому в Prometheus имени
+// [a-zA-Z_:][a-zA-Z0-9_:]*: недопустимые символы заменяются на "_",
+// перед ведущей цифрой добавляется "_".
+func SanitizeName(id string) string {
+	if id == "" {
+		return "_"
+	}
+	var b strings.Builder
+	b.Grow(len(id) + 1)
+	for i, r := range id {
+		switch {
+		case r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+type family struct {
+	name    string
+	id      string
+	mType   string
+	samples []sample
+}
+
+// sample - строки экспозиции одной метрики семейства; key - ее лейблы
+// в каноническом виде для сортировки.
+type sample struct {
+	key   string
+	lines string
+}
+
+// WritePrometheus пишет метрики в текстовом формате экспозиции Prometheus.
+// Counter получают суффикс _total, gauge экспортируются как есть, histogram -
+// серии _bucket с лейблом le, _sum и _count, summary - квантили
+// domain.SummaryQuantiles с лейблом quantile, _sum и _count. Метрики с
+// одним id и разными лейблами образуют одно семейство. Семейства сортируются
+// по имени; если после санитизации имена совпали, остается семейство с
+// лексикографически меньшим исходным id. Строка HELP берется из описания
+// метрики в metadata, если оно задано.
+func WritePrometheus(w io.Writer, metrics []domain.Metrics, metadata map[string]domain.MetricMetadata) error {
+	byName := make(map[string]*family, len(metrics))
+	for _, m := range metrics {
+		name := SanitizeName(m.ID)
+		var lines string
+		switch m.MType {
+		case domain.Counter:
+			if m.Delta == nil {
+				continue
+			}
+			if !strings.HasSuffix(name, counterSuffix) {
+				name += counterSuffix
+			}
+			lines = name + formatLabels(m.Labels) + " " + strconv.FormatInt(*m.Delta, 10) + "\n"
+		case domain.Gauge:
+			if m.Value == nil {
+				continue
+			}
+			lines = name + formatLabels(m.Labels) + " " + formatFloat(*m.Value) + "\n"
+		case domain.Histogram:
+			if m.Histogram == nil {
+				continue
+			}
+			lines = histogramLines(name, m.Labels, m.Histogram)
+		case domain.Summary:
+			if m.Summary == nil {
+				continue
+			}
+			lines = summaryLines(name, m.Labels, m.Summary)
+		default:
+			continue
+		}
+
+		f, ok := byName[name]
+		switch {
+		case !ok || m.ID < f.id || (m.ID == f.id && m.MType < f.mType):
+			f = &family{name: name, id: m.ID, mType: m.MType}
+			byName[name] = f
+		case m.ID != f.id || m.MType != f.mType:
+			continue
+		}
+		f.samples = append(f.samples, sample{key: m.Labels.String(), lines: lines})
+	}
+
+	families := make([]*family, 0, len(byName))
+	for _, f := range byName {
+		sort.Slice(f.samples, func(i, j int) bool { return f.samples[i].key < f.samples[j].key })
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		bw.WriteString("# HELP " + f.name + " " + helpText(f, metadata[f.id]) + "\n")
+		bw.WriteString("# TYPE " + f.name + " " + f.mType + "\n")
+		for _, s := range f.samples {
+			bw.WriteString(s.lines)
+		}
+	}
+	return bw.Flush()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// histogramLines возвращает накопительные бакеты, _sum и _count histogram.
+func histogramLines(name string, labels domain.Labels, h *domain.HistogramValue) string {
+	var b strings.Builder
+	for i, c := range h.Cumulative() {
+		le := "+Inf"
+		if i < len(h.Bounds) {
+			le = formatFloat(h.Bounds[i])
+		}
+		b.WriteString(name + "_bucket" + formatLabels(labels, "le", le) + " " + strconv.FormatUint(c, 10) + "\n")
+	}
+	b.WriteString(name + "_sum" + formatLabels(labels) + " " + formatFloat(h.Sum) + "\n")
+	b.WriteString(name + "_count" + formatLabels(labels) + " " + strconv.FormatUint(h.Count, 10) + "\n")
+	return b.String()
+}
+
+// summaryLines возвращает оценки квантилей, _sum и _count summary.
+func summaryLines(name string, labels domain.Labels, s *domain.SummaryValue) string {
+	var b strings.Builder
+	for _, q := range domain.SummaryQuantiles {
+		b.WriteString(name + formatLabels(labels, "quantile", formatFloat(q)) + " " + formatFloat(s.Quantile(q)) + "\n")
+	}
+	b.WriteString(name + "_sum" + formatLabels(labels) + " " + formatFloat(s.Sum) + "\n")
+	b.WriteString(name + "_count" + formatLabels(labels) + " " + strconv.FormatUint(s.Count, 10) + "\n")
+	return b.String()
+}
+
+// formatLabels возвращает лейблы в виде {k="v",...}, отсортированные по имени;
+// extra - дополнительные пары имя/значение, которые добавляются в конец.
+func formatLabels(labels domain.Labels, extra ...string) string {
+	if len(labels)+len(extra) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	write := func(name, value string) {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(SanitizeName(name))
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(value))
+		b.WriteByte('"')
+	}
+	for _, k := range names {
+		write(k, labels[k])
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		write(extra[i], extra[i+1])
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// helpText возвращает текст HELP: описание метрики или, если его нет,
+// тип и исходный id; единица измерения и владелец дописываются в скобках.
+func helpText(f *family, meta domain.MetricMetadata) string {
+	text := meta.Description
+	if text == "" {
+		kind := "Gauge"
+		switch f.mType {
+		case domain.Counter:
+			kind = "Counter"
+		case domain.Histogram:
+			kind = "Histogram"
+		case domain.Summary:
+			kind = "Summary"
+		}
+		text = kind + " metric " + f.id + "."
+	}
+	var extra []string
+	if meta.Unit != "" {
+		extra = append(extra, "unit: "+meta.Unit)
+	}
+	if meta.Owner != "" {
+		extra = append(extra, "owner: "+meta.Owner)
+	}
+	if len(extra) > 0 {
+		text += " (" + strings.Join(extra, ", ") + ")"
+	}
+	return helpEscaper.Replace(text)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
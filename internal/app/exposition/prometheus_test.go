@@ -0,0 +1,93 @@
+package exposition
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Alloc", "Alloc"},
+		{"cpu.usage-percent", "cpu_usage_percent"},
+		{"9lives", "_9lives"},
+		{"job:rate5m", "job:rate5m"},
+		{"метрика", "_______"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SanitizeName(tt.in), tt.in)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	delta := int64(42)
+	zero := int64(0)
+	value := 1.5
+	inf := math.Inf(1)
+	rtt := domain.NewSummary(domain.DefaultSummaryAccuracy)
+	rtt.Observe(2)
+
+	metrics := []domain.Metrics{
+		{ID: "PollCount", MType: domain.Counter, Delta: &delta},
+		{ID: "Alloc", MType: domain.Gauge, Value: &value},
+		{ID: "requests_total", MType: domain.Counter, Delta: &zero},
+		{ID: "cpu.load", MType: domain.Gauge, Value: &inf},
+		{ID: "cpu-load", MType: domain.Gauge, Value: &value},
+		{ID: "Empty", MType: domain.Gauge},
+		{ID: "temp", MType: domain.Gauge, Labels: domain.Labels{"host": "b"}, Value: &value},
+		{ID: "latency", MType: domain.Histogram, Labels: domain.Labels{"path": "/"}, Histogram: &domain.HistogramValue{
+			Bounds: []float64{0.1, 1}, Counts: []uint64{2, 1, 1}, Count: 4, Sum: 3.5,
+		}},
+		{ID: "temp", MType: domain.Gauge, Labels: domain.Labels{"host": "a", "dc.name": `eu "1"`}, Value: &value},
+		{ID: "rtt", MType: domain.Summary, Summary: rtt},
+	}
+
+	metadata := map[string]domain.MetricMetadata{
+		"Alloc": {ID: "Alloc", Description: "Heap bytes.\nSee runtime.MemStats.", Unit: "bytes", Owner: "runtime"},
+		"temp":  {ID: "temp", Unit: "celsius"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePrometheus(&buf, metrics, metadata))
+
+	want := "# HELP Alloc Heap bytes.\\nSee runtime.MemStats. (unit: bytes, owner: runtime)\n" +
+		"# TYPE Alloc gauge\n" +
+		"Alloc 1.5\n" +
+		"# HELP PollCount_total Counter metric PollCount.\n" +
+		"# TYPE PollCount_total counter\n" +
+		"PollCount_total 42\n" +
+		"# HELP cpu_load Gauge metric cpu-load.\n" +
+		"# TYPE cpu_load gauge\n" +
+		"cpu_load 1.5\n" +
+		"# HELP latency Histogram metric latency.\n" +
+		"# TYPE latency histogram\n" +
+		"latency_bucket{path=\"/\",le=\"0.1\"} 2\n" +
+		"latency_bucket{path=\"/\",le=\"1\"} 3\n" +
+		"latency_bucket{path=\"/\",le=\"+Inf\"} 4\n" +
+		"latency_sum{path=\"/\"} 3.5\n" +
+		"latency_count{path=\"/\"} 4\n" +
+		"# HELP requests_total Counter metric requests_total.\n" +
+		"# TYPE requests_total counter\n" +
+		"requests_total 0\n" +
+		"# HELP rtt Summary metric rtt.\n" +
+		"# TYPE rtt summary\n" +
+		"rtt{quantile=\"0.5\"} 2\n" +
+		"rtt{quantile=\"0.9\"} 2\n" +
+		"rtt{quantile=\"0.99\"} 2\n" +
+		"rtt_sum 2\n" +
+		"rtt_count 1\n" +
+		"# HELP temp Gauge metric temp. (unit: celsius)\n" +
+		"# TYPE temp gauge\n" +
+		"temp{dc_name=\"eu \\\"1\\\"\",host=\"a\"} 1.5\n" +
+		"temp{host=\"b\"} 1.5\n"
+	assert.Equal(t, want, buf.String())
+}
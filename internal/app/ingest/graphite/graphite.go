@@ -0,0 +1,66 @@
+// Package graphite разбирает plaintext-протокол Graphite:
+//
+//	metric.path[;tag=value...] value timestamp
+package graphite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// Point - одно значение серии.
+type Point struct {
+	ID     string
+	Labels domain.Labels
+	Value  float64
+	TS     time.Time
+}
+
+var errInvalidFormat = errors.New("expected \"path value timestamp\"")
+
+// ParseLine разбирает строку протокола. Теги Graphite (path;tag=value)
+// становятся лейблами серии. Timestamp -1 означает текущее время.
+func ParseLine(line string, now time.Time) (Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return Point{}, errInvalidFormat
+	}
+
+	path, tags := fields[0], domain.Labels(nil)
+	if i := strings.IndexByte(path, ';'); i >= 0 {
+		tags = make(domain.Labels)
+		for _, tag := range strings.Split(path[i+1:], ";") {
+			k, v, ok := strings.Cut(tag, "=")
+			if !ok || k == "" || v == "" {
+				return Point{}, fmt.Errorf("invalid tag %q", tag)
+			}
+			tags[k] = v
+		}
+		path = path[:i]
+	}
+	if path == "" {
+		return Point{}, errInvalidFormat
+	}
+
+	// Пропуски (NaN) Graphite тоже отклоняем: gauge без значения не сохранить.
+	v, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Point{}, fmt.Errorf("invalid value %q", fields[1])
+	}
+
+	ts := now
+	if fields[2] != "-1" {
+		sec, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("invalid timestamp %q", fields[2])
+		}
+		ts = time.Unix(0, int64(sec*float64(time.Second)))
+	}
+	return Point{ID: path, Labels: tags, Value: v, TS: ts}, nil
+}
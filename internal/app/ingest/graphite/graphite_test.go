@@ -0,0 +1,43 @@
+package graphite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestParseLine(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+	tests := []struct {
+		line    string
+		want    Point
+		wantErr bool
+	}{
+		{line: "servers.a.cpu 12.5 1700000000", want: Point{ID: "servers.a.cpu", Value: 12.5, TS: time.Unix(1700000000, 0)}},
+		{line: "  disk.used   3   -1 ", want: Point{ID: "disk.used", Value: 3, TS: now}},
+		{line: "cpu;host=a;dc=eu 1 1700000000.5", want: Point{ID: "cpu", Labels: domain.Labels{"dc": "eu", "host": "a"}, Value: 1, TS: time.Unix(1700000000, 5e8)}},
+		{line: "cpu 1", wantErr: true},
+		{line: "cpu abc 1700000000", wantErr: true},
+		{line: "cpu nan 1700000000", wantErr: true},
+		{line: "cpu 1 yesterday", wantErr: true},
+		{line: "cpu;host 1 1700000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseLine(tt.line, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.ID, got.ID)
+			assert.Equal(t, tt.want.Labels, got.Labels)
+			assert.Equal(t, tt.want.Value, got.Value)
+			assert.True(t, tt.want.TS.Equal(got.TS), got.TS)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Package influx разбирает InfluxDB line protocol:
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+package influx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// MaxLineSize ограничивает длину одной строки.
+const MaxLineSize = 1 << 20
+
+// valueField - поле, которое не добавляется к имени измерения (как в Telegraf).
+const valueField = "value"
+
+var (
+	ErrInvalidPrecision = errors.New("invalid precision")
+	errMissingFields    = errors.New("missing fields")
+	errMissingName      = errors.New("missing measurement")
+)
+
+// LineError описывает строку, которую не удалось разобрать.
+type LineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Result - итог разбора тела запроса.
+type Result struct {
+	Metrics []*domain.Metrics
+	Errors  []LineError
+}
+
+// Precision возвращает длительность единицы timestamp для параметра precision
+// (ns, us, ms, s; пустое значение - наносекунды).
+func Precision(p string) (time.Duration, error) {
+	switch p {
+	case "", "ns", "n":
+		return time.Nanosecond, nil
+	case "us", "u":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	}
+	return 0, fmt.Errorf("%w %q", ErrInvalidPrecision, p)
+}
+
+// Parse разбирает тело запроса построчно. Числовые поля становятся gauge,
+// целые с суффиксом i (и u) - counter; строковые и булевы поля пропускаются.
+// Id метрики - measurement_field, теги становятся лейблами метрики;
+// поле value дает id по имени измерения. Ошибочные строки не прерывают разбор
+// и попадают в Result.Errors с номером строки (с единицы).
+func Parse(r io.Reader, precision time.Duration, now time.Time) (*Result, error) {
+	batch := ingest.NewBatch()
+	res := &Result{}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), MaxLineSize)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if err := parseLine(batch, line, precision, now); err != nil {
+			res.Errors = append(res.Errors, LineError{Line: lineNo, Error: err.Error()})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+	}
+	res.Metrics = batch.Metrics()
+	return res, nil
+}
+
+type field struct {
+	name    string
+	gauge   float64
+	counter int64
+	isInt   bool
+}
+
+func parseLine(batch *ingest.Batch, line string, precision time.Duration, now time.Time) error {
+	keyEnd := indexUnescaped(line, ' ', false)
+	if keyEnd < 0 {
+		return errMissingFields
+	}
+	key, rest := line[:keyEnd], strings.TrimLeft(line[keyEnd+1:], " ")
+
+	fieldsEnd := indexUnescaped(rest, ' ', true)
+	fieldsPart, tsPart := rest, ""
+	if fieldsEnd >= 0 {
+		fieldsPart, tsPart = rest[:fieldsEnd], strings.TrimSpace(rest[fieldsEnd+1:])
+	}
+	if fieldsPart == "" {
+		return errMissingFields
+	}
+
+	keyParts := splitUnescaped(key, ',', false)
+	measurement := unescape(keyParts[0])
+	if measurement == "" {
+		return errMissingName
+	}
+	var tags domain.Labels
+	if len(keyParts) > 1 {
+		tags = make(domain.Labels, len(keyParts)-1)
+	}
+	for _, kv := range keyParts[1:] {
+		k, v, ok := splitPair(kv)
+		if !ok || k == "" || v == "" {
+			return fmt.Errorf("invalid tag %q", kv)
+		}
+		tags[unescape(k)] = unescape(v)
+	}
+
+	var fields []field
+	for _, kv := range splitUnescaped(fieldsPart, ',', true) {
+		k, v, ok := splitPair(kv)
+		if !ok || k == "" || v == "" {
+			return fmt.Errorf("invalid field %q", kv)
+		}
+		f, numeric, err := parseFieldValue(v)
+		if err != nil {
+			return fmt.Errorf("field %q: %w", unescape(k), err)
+		}
+		if numeric {
+			f.name = unescape(k)
+			fields = append(fields, f)
+		}
+	}
+
+	ts := now
+	if tsPart != "" {
+		n, err := strconv.ParseInt(tsPart, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", tsPart)
+		}
+		ts = time.Unix(0, n*int64(precision))
+	}
+
+	for _, f := range fields {
+		name := measurement
+		if f.name != valueField {
+			name += "_" + f.name
+		}
+		if f.isInt {
+			batch.AddCounter(name, tags, f.counter)
+		} else {
+			batch.AddGauge(name, tags, f.gauge, ts)
+		}
+	}
+	return nil
+}
+
+// parseFieldValue разбирает значение поля; numeric=false для строк и булевых.
+func parseFieldValue(v string) (f field, numeric bool, err error) {
+	switch {
+	case v[0] == '"':
+		if len(v) < 2 || v[len(v)-1] != '"' {
+			return f, false, errors.New("unterminated string")
+		}
+		return f, false, nil
+	case isBool(v):
+		return f, false, nil
+	case strings.HasSuffix(v, "i"):
+		n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+		if err != nil {
+			return f, false, fmt.Errorf("invalid integer %q", v)
+		}
+		return field{counter: n, isInt: true}, true, nil
+	case strings.HasSuffix(v, "u"):
+		n, err := strconv.ParseUint(v[:len(v)-1], 10, 64)
+		if err != nil || n > math.MaxInt64 {
+			return f, false, fmt.Errorf("invalid unsigned integer %q", v)
+		}
+		return field{counter: int64(n), isInt: true}, true, nil
+	}
+	g, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(g) || math.IsInf(g, 0) {
+		return f, false, fmt.Errorf("invalid float %q", v)
+	}
+	return field{gauge: g}, true, nil
+}
+
+func isBool(v string) bool {
+	switch v {
+	case "t", "T", "true", "True", "TRUE", "f", "F", "false", "False", "FALSE":
+		return true
+	}
+	return false
+}
+
+// indexUnescaped ищет sep, не экранированный обратным слешем; при quoted
+// разделители внутри строк в двойных кавычках пропускаются.
+func indexUnescaped(s string, sep byte, quoted bool) int {
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case quoted && c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			return i
+		}
+	}
+	return -1
+}
+
+func splitUnescaped(s string, sep byte, quoted bool) []string {
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep, quoted)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
+
+func splitPair(kv string) (string, string, bool) {
+	i := indexUnescaped(kv, '=', false)
+	if i < 0 {
+		return "", "", false
+	}
+	return kv[:i], kv[i+1:], true
+}
+
+var unescaper = strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=")
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return unescaper.Replace(s)
+}
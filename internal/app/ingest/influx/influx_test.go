@@ -0,0 +1,66 @@
+package influx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestParse(t *testing.T) {
+	body := strings.Join([]string{
+		`# comment`,
+		`cpu,host=a,region=eu usage_idle=92.5,usage_user=3 1700000000000000000`,
+		`net,host=a bytes_recv=1024i,status="up",up=true`,
+		``,
+		`disk\ io,mount=/var\,log value=7`,
+		`cpu,region=eu,host=a usage_idle=90 1600000000000000000`,
+		`net,host=a bytes_recv=10i`,
+		`broken`,
+		`cpu,host=a usage_idle=abc`,
+		`cpu,host usage_idle=1`,
+		`cpu usage_idle=1 notatime`,
+	}, "\n")
+
+	res, err := Parse(strings.NewReader(body), time.Nanosecond, time.Now())
+	require.NoError(t, err)
+
+	got := make(map[string]domain.Metrics, len(res.Metrics))
+	for _, m := range res.Metrics {
+		got[m.MType+":"+m.SeriesID()] = *m
+	}
+	require.Len(t, got, 4)
+	assert.Equal(t, 92.5, *got[`gauge:cpu_usage_idle{host="a",region="eu"}`].Value)
+	assert.Equal(t, 3.0, *got[`gauge:cpu_usage_user{host="a",region="eu"}`].Value)
+	assert.Equal(t, int64(1034), *got[`counter:net_bytes_recv{host="a"}`].Delta)
+	assert.Equal(t, 7.0, *got[`gauge:disk io{mount="/var,log"}`].Value)
+
+	assert.Equal(t, []LineError{
+		{Line: 8, Error: "missing fields"},
+		{Line: 9, Error: `field "usage_idle": invalid float "abc"`},
+		{Line: 10, Error: `invalid tag "host"`},
+		{Line: 11, Error: `invalid timestamp "notatime"`},
+	}, res.Errors)
+}
+
+func TestParse_QuotedSpaces(t *testing.T) {
+	res, err := Parse(strings.NewReader(`log,app=x msg="a, b=c d",count=2i 1700000000`), time.Second, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, res.Errors)
+	require.Len(t, res.Metrics, 1)
+	assert.Equal(t, "log_count", res.Metrics[0].ID)
+	assert.Equal(t, domain.Labels{"app": "x"}, res.Metrics[0].Labels)
+}
+
+func TestPrecision(t *testing.T) {
+	p, err := Precision("ms")
+	require.NoError(t, err)
+	assert.Equal(t, time.Millisecond, p)
+
+	_, err = Precision("h")
+	assert.ErrorIs(t, err, ErrInvalidPrecision)
+}
@@ -0,0 +1,68 @@
+// Package ingest содержит общие для сторонних протоколов приема метрик
+// преобразования: сборку батча для MetricService.SaveOrUpdateMetricsBatch.
+package ingest
+
+import (
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+type batchKey struct {
+	series string
+	mType  string
+}
+
+// Batch накапливает значения и схлопывает повторы одной серии: для gauge
+// остается значение с наибольшим временем, для counter дельты суммируются.
+// Без схлопывания Postgres отклонил бы батч с повторяющимся (id, type, labels).
+type Batch struct {
+	metrics []*domain.Metrics
+	index   map[batchKey]int
+	ts      []time.Time
+}
+
+// NewBatch создает пустой батч.
+func NewBatch() *Batch {
+	return &Batch{index: make(map[batchKey]int)}
+}
+
+// AddGauge добавляет значение gauge серии id+labels, измеренное в момент ts.
+func (b *Batch) AddGauge(id string, labels domain.Labels, value float64, ts time.Time) {
+	k := batchKey{series: domain.SeriesID(id, labels), mType: domain.Gauge}
+	if i, ok := b.index[k]; ok {
+		if ts.Before(b.ts[i]) {
+			return
+		}
+		*b.metrics[i].Value = value
+		b.ts[i] = ts
+		return
+	}
+	b.append(k, &domain.Metrics{ID: id, MType: domain.Gauge, Labels: labels.Clone(), Value: &value}, ts)
+}
+
+// AddCounter добавляет приращение counter серии id+labels.
+func (b *Batch) AddCounter(id string, labels domain.Labels, delta int64) {
+	k := batchKey{series: domain.SeriesID(id, labels), mType: domain.Counter}
+	if i, ok := b.index[k]; ok {
+		*b.metrics[i].Delta += delta
+		return
+	}
+	b.append(k, &domain.Metrics{ID: id, MType: domain.Counter, Labels: labels.Clone(), Delta: &delta}, time.Time{})
+}
+
+func (b *Batch) append(k batchKey, m *domain.Metrics, ts time.Time) {
+	b.index[k] = len(b.metrics)
+	b.metrics = append(b.metrics, m)
+	b.ts = append(b.ts, ts)
+}
+
+// Len возвращает число различных серий в батче.
+func (b *Batch) Len() int {
+	return len(b.metrics)
+}
+
+// Metrics возвращает метрики в порядке первого появления серий.
+func (b *Batch) Metrics() []*domain.Metrics {
+	return b.metrics
+}
@@ -0,0 +1,32 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestBatch(t *testing.T) {
+	now := time.Now()
+	b := NewBatch()
+	b.AddGauge("temp", nil, 1, now)
+	b.AddCounter("hits", nil, 2)
+	b.AddGauge("temp", nil, 3, now.Add(time.Second))
+	b.AddGauge("temp", nil, 2, now.Add(-time.Second))
+	b.AddCounter("hits", nil, 5)
+	b.AddCounter("temp", nil, 1)
+	b.AddGauge("temp", domain.Labels{"host": "a"}, 4, now)
+
+	m := b.Metrics()
+	require.Equal(t, 4, b.Len())
+	assert.Equal(t, domain.Metrics{ID: "temp", MType: domain.Gauge, Value: m[0].Value}, *m[0])
+	assert.Equal(t, 3.0, *m[0].Value)
+	assert.Equal(t, int64(7), *m[1].Delta)
+	assert.Equal(t, domain.Counter, m[2].MType)
+	assert.Equal(t, domain.Labels{"host": "a"}, m[3].Labels)
+	assert.Equal(t, 4.0, *m[3].Value)
+}
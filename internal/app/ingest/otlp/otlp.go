@@ -0,0 +1,241 @@
+// Package otlp преобразует запросы OTLP/HTTP (ExportMetricsServiceRequest)
+// в метрики сервера.
+package otlp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"mime"
+	"strconv"
+	"sync"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// Поддерживаемые кодировки тела запроса и ответа.
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// MaxBodySize ограничивает размер тела запроса после распаковки gzip.
+const MaxBodySize = 32 << 20
+
+var (
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+	ErrPayloadTooLarge        = errors.New("otlp payload too large")
+)
+
+// Decode разбирает тело запроса в кодировке, заданной Content-Type.
+func Decode(body []byte, contentType string) (*colmetricspb.ExportMetricsServiceRequest, error) {
+	mediaType, err := mediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	switch mediaType {
+	case ContentTypeProtobuf:
+		err = proto.Unmarshal(body, req)
+	case ContentTypeJSON:
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Encode сериализует ответ в той же кодировке, что и запрос.
+func Encode(resp *colmetricspb.ExportMetricsServiceResponse, contentType string) ([]byte, string, error) {
+	mediaType, err := mediaType(contentType)
+	if err != nil {
+		return nil, "", err
+	}
+	if mediaType == ContentTypeJSON {
+		b, err := protojson.Marshal(resp)
+		return b, ContentTypeJSON, err
+	}
+	b, err := proto.Marshal(resp)
+	return b, ContentTypeProtobuf, err
+}
+
+func mediaType(contentType string) (string, error) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mt != ContentTypeProtobuf && mt != ContentTypeJSON) {
+		return "", fmt.Errorf("%w %q", ErrUnsupportedContentType, contentType)
+	}
+	return mt, nil
+}
+
+// counterState - состояние монотонной суммы между запросами.
+type counterState struct {
+	start uint64  // StartTimeUnixNano последней точки; смена означает сброс
+	last  float64 // Последнее кумулятивное значение
+	carry float64 // Дробный остаток, еще не переданный в counter
+}
+
+// Converter преобразует точки OTLP в метрики. Монотонные Sum становятся
+// counter: кумулятивные значения переводятся в приращения относительно
+// предыдущего запроса (первая точка серии и сброс счетчика дают все значение),
+// дробная часть переносится в следующие запросы. Немонотонные Sum и Gauge
+// становятся gauge. Атрибуты ресурса и точки сворачиваются в лейблы id.
+// Histogram, ExponentialHistogram и Summary пока не поддерживаются и
+// учитываются как отклоненные точки.
+type Converter struct {
+	mu     sync.Mutex
+	series map[string]*counterState
+}
+
+// NewConverter создает конвертер с пустым состоянием счетчиков.
+func NewConverter() *Converter {
+	return &Converter{series: make(map[string]*counterState)}
+}
+
+// Result - итог преобразования запроса.
+type Result struct {
+	Metrics  []*domain.Metrics
+	Rejected int64
+	Message  string
+}
+
+// Convert преобразует запрос.
+func (c *Converter) Convert(req *colmetricspb.ExportMetricsServiceRequest) *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch := ingest.NewBatch()
+	res := &Result{}
+	reject := func(n int, msg string) {
+		res.Rejected += int64(n)
+		if res.Message == "" {
+			res.Message = msg
+		}
+	}
+
+	for _, rm := range req.GetResourceMetrics() {
+		resourceLabels := labels(nil, rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				switch data := m.GetData().(type) {
+				case *metricspb.Metric_Gauge:
+					for _, dp := range data.Gauge.GetDataPoints() {
+						if skip(dp) {
+							continue
+						}
+						batch.AddGauge(m.GetName(), labels(resourceLabels, dp.GetAttributes()), value(dp), pointTime(dp))
+					}
+				case *metricspb.Metric_Sum:
+					sum := data.Sum
+					temporality := sum.GetAggregationTemporality()
+					if temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED {
+						reject(len(sum.GetDataPoints()), fmt.Sprintf("metric %q: unspecified aggregation temporality", m.GetName()))
+						continue
+					}
+					for _, dp := range sum.GetDataPoints() {
+						if skip(dp) {
+							continue
+						}
+						pointLabels := labels(resourceLabels, dp.GetAttributes())
+						if !sum.GetIsMonotonic() {
+							batch.AddGauge(m.GetName(), pointLabels, value(dp), pointTime(dp))
+							continue
+						}
+						cumulative := temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+						delta := c.delta(domain.SeriesID(m.GetName(), pointLabels), dp, cumulative)
+						batch.AddCounter(m.GetName(), pointLabels, delta)
+					}
+				case *metricspb.Metric_Histogram:
+					reject(len(data.Histogram.GetDataPoints()), fmt.Sprintf("metric %q: histogram is not supported", m.GetName()))
+				case *metricspb.Metric_ExponentialHistogram:
+					reject(len(data.ExponentialHistogram.GetDataPoints()), fmt.Sprintf("metric %q: exponential histogram is not supported", m.GetName()))
+				case *metricspb.Metric_Summary:
+					reject(len(data.Summary.GetDataPoints()), fmt.Sprintf("metric %q: summary is not supported", m.GetName()))
+				}
+			}
+		}
+	}
+	res.Metrics = batch.Metrics()
+	return res
+}
+
+// delta возвращает целое приращение счетчика, накапливая дробный остаток.
+func (c *Converter) delta(id string, dp *metricspb.NumberDataPoint, cumulative bool) int64 {
+	st, ok := c.series[id]
+	if !ok {
+		st = &counterState{}
+		c.series[id] = st
+	}
+
+	v := value(dp)
+	increment := v
+	if cumulative {
+		if ok && st.start == dp.GetStartTimeUnixNano() && v >= st.last {
+			increment = v - st.last
+		}
+		st.start, st.last = dp.GetStartTimeUnixNano(), v
+	}
+
+	st.carry += increment
+	d := math.Trunc(st.carry)
+	st.carry -= d
+	return int64(d)
+}
+
+func skip(dp *metricspb.NumberDataPoint) bool {
+	return dp.GetFlags()&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0
+}
+
+func value(dp *metricspb.NumberDataPoint) float64 {
+	if v, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+	return dp.GetAsDouble()
+}
+
+func pointTime(dp *metricspb.NumberDataPoint) time.Time {
+	return time.Unix(0, int64(dp.GetTimeUnixNano()))
+}
+
+// labels добавляет атрибуты к base; атрибут точки перекрывает одноименный атрибут ресурса.
+func labels(base domain.Labels, attrs []*commonpb.KeyValue) domain.Labels {
+	if len(base)+len(attrs) == 0 {
+		return nil
+	}
+	out := make(domain.Labels, len(base)+len(attrs))
+	maps.Copy(out, base)
+	for _, kv := range attrs {
+		out[kv.GetKey()] = attrValue(kv.GetValue())
+	}
+	return out
+}
+
+func attrValue(v *commonpb.AnyValue) string {
+	switch x := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return x.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(x.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(x.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(x.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return hex.EncodeToString(x.BytesValue)
+	case nil:
+		return ""
+	}
+	// Массивы и вложенные списки передаем в JSON-представлении.
+	b, _ := protojson.Marshal(v)
+	return string(b)
+}
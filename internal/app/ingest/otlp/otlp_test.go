@@ -0,0 +1,116 @@
+package otlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func strAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func intPoint(v int64, start uint64, attrs ...*commonpb.KeyValue) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:        attrs,
+		StartTimeUnixNano: start,
+		TimeUnixNano:      start + 1,
+		Value:             &metricspb.NumberDataPoint_AsInt{AsInt: v},
+	}
+}
+
+func request(metrics ...*metricspb.Metric) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource:     &resourcepb.Resource{Attributes: []*commonpb.KeyValue{strAttr("service.name", "api")}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+	}}}
+}
+
+func cumulativeSum(name string, monotonic bool, points ...*metricspb.NumberDataPoint) *metricspb.Metric {
+	return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+		AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		IsMonotonic:            monotonic,
+		DataPoints:             points,
+	}}}
+}
+
+func byKey(metrics []*domain.Metrics) map[string]domain.Metrics {
+	out := make(map[string]domain.Metrics, len(metrics))
+	for _, m := range metrics {
+		out[m.MType+":"+m.SeriesID()] = *m
+	}
+	return out
+}
+
+func TestConverter_Convert(t *testing.T) {
+	c := NewConverter()
+
+	res := c.Convert(request(
+		cumulativeSum("requests", true, intPoint(10, 100, strAttr("route", "/a"))),
+		cumulativeSum("queue.size", false, intPoint(-3, 100)),
+		&metricspb.Metric{Name: "temp", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+			DataPoints: []*metricspb.NumberDataPoint{{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 21.5}}},
+		}}},
+		&metricspb.Metric{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			DataPoints: []*metricspb.HistogramDataPoint{{}, {}},
+		}}},
+	))
+	assert.Equal(t, int64(2), res.Rejected)
+	assert.Contains(t, res.Message, "histogram")
+
+	got := byKey(res.Metrics)
+	require.Len(t, got, 3)
+	assert.Equal(t, int64(10), *got[`counter:requests{route="/a",service.name="api"}`].Delta)
+	assert.Equal(t, -3.0, *got[`gauge:queue.size{service.name="api"}`].Value)
+	assert.Equal(t, 21.5, *got[`gauge:temp{service.name="api"}`].Value)
+
+	// Кумулятивное значение переводится в приращение, сброс (новый start) отдает все значение.
+	res = c.Convert(request(cumulativeSum("requests", true, intPoint(15, 100, strAttr("route", "/a")))))
+	assert.Equal(t, int64(5), *res.Metrics[0].Delta)
+	res = c.Convert(request(cumulativeSum("requests", true, intPoint(4, 200, strAttr("route", "/a")))))
+	assert.Equal(t, int64(4), *res.Metrics[0].Delta)
+}
+
+func TestConverter_DeltaDouble(t *testing.T) {
+	c := NewConverter()
+	sum := func(v float64) *colmetricspb.ExportMetricsServiceRequest {
+		return request(&metricspb.Metric{Name: "bytes", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+			IsMonotonic:            true,
+			DataPoints:             []*metricspb.NumberDataPoint{{Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: v}}},
+		}}})
+	}
+	assert.Equal(t, int64(1), *c.Convert(sum(1.5)).Metrics[0].Delta)
+	assert.Equal(t, int64(2), *c.Convert(sum(1.5)).Metrics[0].Delta)
+}
+
+func TestDecodeEncode(t *testing.T) {
+	req := request(cumulativeSum("requests", true, intPoint(1, 1)))
+	raw, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	decoded, err := Decode(raw, ContentTypeProtobuf)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(req, decoded))
+
+	jsonBody := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"up","gauge":{"dataPoints":[{"asInt":"1","timeUnixNano":"1700000000000000000"}]}}]}]}]}`
+	decoded, err = Decode([]byte(jsonBody), "application/json; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, "up", decoded.GetResourceMetrics()[0].GetScopeMetrics()[0].GetMetrics()[0].GetName())
+
+	_, err = Decode(raw, "text/plain")
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+	b, ct, err := Encode(&colmetricspb.ExportMetricsServiceResponse{}, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeJSON, ct)
+	assert.Equal(t, "{}", string(b))
+}
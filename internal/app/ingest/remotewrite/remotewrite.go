@@ -0,0 +1,91 @@
+// Package remotewrite разбирает запросы Prometheus remote_write.
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/pkg/prompb"
+)
+
+// MaxDecodedSize ограничивает размер распакованного тела запроса.
+const MaxDecodedSize = 32 << 20
+
+const metricNameLabel = "__name__"
+
+// staleNaN - маркер устаревания серии, который Prometheus отправляет при ее исчезновении.
+const staleNaN uint64 = 0x7ff0000000000002
+
+var (
+	ErrPayloadTooLarge = errors.New("remote write payload too large")
+	ErrMissingName     = errors.New("series without __name__ label")
+)
+
+// Decode распаковывает snappy (block format) и разбирает protobuf WriteRequest.
+func Decode(body []byte) (*prompb.WriteRequest, error) {
+	n, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("snappy: %w", err)
+	}
+	if n > MaxDecodedSize {
+		return nil, ErrPayloadTooLarge
+	}
+	raw, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("snappy: %w", err)
+	}
+
+	var req prompb.WriteRequest
+	if err := proto.Unmarshal(raw, &req); err != nil {
+		return nil, fmt.Errorf("protobuf: %w", err)
+	}
+	return &req, nil
+}
+
+// ToMetrics преобразует серии в gauge-метрики: __name__ становится id, остальные
+// лейблы - лейблами метрики. Из сэмплов серии берется самый поздний, stale-маркеры
+// пропускаются.
+func ToMetrics(req *prompb.WriteRequest) ([]*domain.Metrics, error) {
+	batch := ingest.NewBatch()
+	for _, ts := range req.GetTimeseries() {
+		id, labels, err := series(ts.GetLabels())
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range ts.GetSamples() {
+			if math.Float64bits(s.GetValue()) == staleNaN {
+				continue
+			}
+			batch.AddGauge(id, labels, s.GetValue(), time.UnixMilli(s.GetTimestamp()))
+		}
+	}
+	return batch.Metrics(), nil
+}
+
+func series(labels []*prompb.Label) (string, domain.Labels, error) {
+	var (
+		name string
+		rest domain.Labels
+	)
+	for _, l := range labels {
+		if l.GetName() == metricNameLabel {
+			name = l.GetValue()
+			continue
+		}
+		if rest == nil {
+			rest = make(domain.Labels, len(labels))
+		}
+		rest[l.GetName()] = l.GetValue()
+	}
+	if name == "" {
+		return "", nil, ErrMissingName
+	}
+	return name, rest, nil
+}
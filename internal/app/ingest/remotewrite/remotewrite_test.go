@@ -0,0 +1,77 @@
+package remotewrite
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/pkg/prompb"
+)
+
+func labels(kv ...string) []*prompb.Label {
+	out := make([]*prompb.Label, 0, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		out = append(out, &prompb.Label{Name: kv[i], Value: kv[i+1]})
+	}
+	return out
+}
+
+func TestDecodeAndToMetrics(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []*prompb.TimeSeries{
+		{
+			Labels:  labels("__name__", "up"),
+			Samples: []*prompb.Sample{{Value: 1, Timestamp: 1000}},
+		},
+		{
+			Labels: labels("job", "api", "__name__", "http_requests", "instance", "a:9090"),
+			Samples: []*prompb.Sample{
+				{Value: 5, Timestamp: 2000},
+				{Value: 3, Timestamp: 1000},
+			},
+		},
+		{
+			Labels:  labels("__name__", "up"),
+			Samples: []*prompb.Sample{{Value: 0, Timestamp: 3000}},
+		},
+		{
+			Labels:  labels("__name__", "gone"),
+			Samples: []*prompb.Sample{{Value: math.Float64frombits(staleNaN), Timestamp: 1000}},
+		},
+	}}
+	raw, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	decoded, err := Decode(snappy.Encode(nil, raw))
+	require.NoError(t, err)
+
+	metrics, err := ToMetrics(decoded)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "up", metrics[0].ID)
+	assert.Nil(t, metrics[0].Labels)
+	assert.Equal(t, domain.Gauge, metrics[0].MType)
+	assert.Equal(t, 0.0, *metrics[0].Value)
+
+	assert.Equal(t, "http_requests", metrics[1].ID)
+	assert.Equal(t, domain.Labels{"instance": "a:9090", "job": "api"}, metrics[1].Labels)
+	assert.Equal(t, 5.0, *metrics[1].Value)
+}
+
+func TestToMetrics_MissingName(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []*prompb.TimeSeries{
+		{Labels: labels("job", "api"), Samples: []*prompb.Sample{{Value: 1}}},
+	}}
+	_, err := ToMetrics(req)
+	assert.ErrorIs(t, err, ErrMissingName)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode([]byte("not snappy"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,246 @@
+// Package statsd разбирает и агрегирует метрики в формате StatsD:
+//
+//	name:value|type[|@sample_rate][|#tag:value,...]
+//
+// Поддерживаются типы c (counter), g (gauge), ms и h (timer); теги в стиле
+// DogStatsD становятся лейблами метрики.
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// Kind - тип метрики StatsD.
+type Kind int
+
+const (
+	KindCounter Kind = iota
+	KindGauge
+	KindTimer
+)
+
+// Sample - одно разобранное значение.
+type Sample struct {
+	ID       string
+	Labels   domain.Labels
+	Kind     Kind
+	Value    float64
+	Rate     float64 // Доля отправленных значений, (0, 1]
+	Relative bool    // Для gauge: значение со знаком +/- изменяет текущее
+}
+
+var errInvalidFormat = errors.New("expected name:value|type")
+
+// ParseLine разбирает одну строку пакета.
+func ParseLine(line string) (Sample, error) {
+	s := Sample{Rate: 1}
+
+	pipe := strings.IndexByte(line, '|')
+	if pipe < 0 {
+		return s, errInvalidFormat
+	}
+	// Двоеточие встречается и в тегах, поэтому ищем его до первого "|".
+	colon := strings.LastIndexByte(line[:pipe], ':')
+	if colon <= 0 {
+		return s, errInvalidFormat
+	}
+	name, value := line[:colon], line[colon+1:pipe]
+	parts := strings.Split(line[pipe+1:], "|")
+
+	switch parts[0] {
+	case "c":
+		s.Kind = KindCounter
+	case "g":
+		s.Kind = KindGauge
+		s.Relative = strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+	case "ms", "h":
+		s.Kind = KindTimer
+	default:
+		return s, fmt.Errorf("unsupported type %q", parts[0])
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return s, fmt.Errorf("invalid value %q", value)
+	}
+	s.Value = v
+
+	for _, p := range parts[1:] {
+		switch {
+		case strings.HasPrefix(p, "@"):
+			rate, err := strconv.ParseFloat(p[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return s, fmt.Errorf("invalid sample rate %q", p)
+			}
+			s.Rate = rate
+		case strings.HasPrefix(p, "#"):
+			for _, tag := range strings.Split(p[1:], ",") {
+				if tag == "" {
+					continue
+				}
+				k, v, _ := strings.Cut(tag, ":")
+				if s.Labels == nil {
+					s.Labels = make(domain.Labels)
+				}
+				s.Labels[k] = v
+			}
+		}
+	}
+	s.ID = name
+	return s, nil
+}
+
+type timerStats struct {
+	count float64
+	min   float64
+	max   float64
+	sum   float64
+	n     int
+}
+
+// Aggregator накапливает значения между сбросами. Безопасен для
+// конкурентного использования.
+//
+// Counter суммируются с учетом sample rate; дробный остаток переносится в
+// следующий интервал. Gauge сохраняют значение между интервалами (для
+// относительных изменений), но отдаются только если обновлялись. Timer
+// превращаются в gauge id.count, id.min, id.max и id.mean с теми же лейблами.
+// Значения хранятся по идентификатору серии (domain.SeriesID).
+//
+// Число серий ограничено maxKeys: значения новых серий сверх лимита
+// отбрасываются и учитываются в счетчике, который возвращает Flush.
+type Aggregator struct {
+	mu       sync.Mutex
+	maxKeys  int
+	dropped  int
+	series   map[string]seriesRef
+	counters map[string]float64
+	gauges   map[string]float64
+	updated  map[string]struct{}
+	timers   map[string]*timerStats
+}
+
+// seriesRef - id и лейблы серии, по которым собираются метрики при сбросе.
+type seriesRef struct {
+	id     string
+	labels domain.Labels
+}
+
+// NewAggregator создает пустой агрегатор не более чем на maxKeys серий;
+// ноль - без ограничения.
+func NewAggregator(maxKeys int) *Aggregator {
+	return &Aggregator{
+		maxKeys:  maxKeys,
+		series:   make(map[string]seriesRef),
+		counters: make(map[string]float64),
+		gauges:   make(map[string]float64),
+		updated:  make(map[string]struct{}),
+		timers:   make(map[string]*timerStats),
+	}
+}
+
+// Add учитывает значение.
+func (a *Aggregator) Add(s Sample) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := domain.SeriesID(s.ID, s.Labels)
+	if _, ok := a.series[key]; !ok {
+		if a.maxKeys > 0 && len(a.series) >= a.maxKeys {
+			a.dropped++
+			return
+		}
+		a.series[key] = seriesRef{id: s.ID, labels: s.Labels.Clone()}
+	}
+
+	switch s.Kind {
+	case KindCounter:
+		a.counters[key] += s.Value / s.Rate
+	case KindGauge:
+		if s.Relative {
+			a.gauges[key] += s.Value
+		} else {
+			a.gauges[key] = s.Value
+		}
+		a.updated[key] = struct{}{}
+	case KindTimer:
+		t, ok := a.timers[key]
+		if !ok {
+			t = &timerStats{min: s.Value, max: s.Value}
+			a.timers[key] = t
+		}
+		t.count += 1 / s.Rate
+		t.min = math.Min(t.min, s.Value)
+		t.max = math.Max(t.max, s.Value)
+		t.sum += s.Value
+		t.n++
+	}
+}
+
+// Flush возвращает накопленные за интервал метрики и число значений,
+// отброшенных за интервал из-за лимита серий, и начинает новый интервал.
+// Если значения отбрасывались, сохраненные gauge, которые не обновлялись
+// за интервал, забываются, чтобы освободить место для новых серий.
+func (a *Aggregator) Flush() ([]*domain.Metrics, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	metrics := make([]*domain.Metrics, 0, len(a.counters)+len(a.updated)+4*len(a.timers))
+	for key, sum := range a.counters {
+		delta := int64(math.Trunc(sum))
+		if delta == 0 {
+			continue
+		}
+		if rest := sum - float64(delta); rest != 0 {
+			a.counters[key] = rest
+		} else {
+			delete(a.counters, key)
+		}
+		ref := a.series[key]
+		metrics = append(metrics, &domain.Metrics{ID: ref.id, MType: domain.Counter, Labels: ref.labels, Delta: &delta})
+	}
+	for key := range a.updated {
+		metrics = append(metrics, a.series[key].gauge("", a.gauges[key]))
+	}
+	for key, t := range a.timers {
+		ref := a.series[key]
+		metrics = append(metrics,
+			ref.gauge(".count", t.count),
+			ref.gauge(".min", t.min),
+			ref.gauge(".max", t.max),
+			ref.gauge(".mean", t.sum/float64(t.n)),
+		)
+	}
+
+	dropped := a.dropped
+	if dropped > 0 {
+		for key := range a.gauges {
+			if _, ok := a.updated[key]; !ok {
+				delete(a.gauges, key)
+			}
+		}
+	}
+	a.dropped = 0
+	clear(a.updated)
+	clear(a.timers)
+	for key := range a.series {
+		_, isCounter := a.counters[key]
+		_, isGauge := a.gauges[key]
+		if !isCounter && !isGauge {
+			delete(a.series, key)
+		}
+	}
+	return metrics, dropped
+}
+
+// gauge создает gauge серии с суффиксом к имени (для агрегатов timer).
+func (r seriesRef) gauge(suffix string, v float64) *domain.Metrics {
+	return &domain.Metrics{ID: r.id + suffix, MType: domain.Gauge, Labels: r.labels, Value: &v}
+}
@@ -0,0 +1,105 @@
+package statsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Sample
+		wantErr bool
+	}{
+		{line: "hits:1|c", want: Sample{ID: "hits", Kind: KindCounter, Value: 1, Rate: 1}},
+		{line: "hits:2|c|@0.5", want: Sample{ID: "hits", Kind: KindCounter, Value: 2, Rate: 0.5}},
+		{line: "temp:-3|g", want: Sample{ID: "temp", Kind: KindGauge, Value: -3, Rate: 1, Relative: true}},
+		{line: "req.time:320|ms|#env:prod,az:a", want: Sample{ID: "req.time", Labels: domain.Labels{"az": "a", "env": "prod"}, Kind: KindTimer, Value: 320, Rate: 1}},
+		{line: "hits", wantErr: true},
+		{line: "hits:1|s", wantErr: true},
+		{line: "hits:abc|c", wantErr: true},
+		{line: "hits:1|c|@2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func flushed(a *Aggregator) map[string]domain.Metrics {
+	out := make(map[string]domain.Metrics)
+	metrics, _ := a.Flush()
+	for _, m := range metrics {
+		out[m.MType+":"+m.SeriesID()] = *m
+	}
+	return out
+}
+
+func TestAggregator(t *testing.T) {
+	a := NewAggregator(0)
+	for _, line := range []string{
+		"hits:1|c", "hits:1|c|@0.4",
+		"temp:10|g", "temp:+5|g",
+		"req:100|ms", "req:300|ms", "req:200|ms|@0.5",
+		"req:50|ms|#env:prod",
+	} {
+		s, err := ParseLine(line)
+		require.NoError(t, err)
+		a.Add(s)
+	}
+
+	got := flushed(a)
+	assert.Equal(t, int64(3), *got["counter:hits"].Delta)
+	assert.Equal(t, 15.0, *got["gauge:temp"].Value)
+	assert.Equal(t, 4.0, *got["gauge:req.count"].Value)
+	assert.Equal(t, 100.0, *got["gauge:req.min"].Value)
+	assert.Equal(t, 300.0, *got["gauge:req.max"].Value)
+	assert.Equal(t, 200.0, *got["gauge:req.mean"].Value)
+	assert.Equal(t, 50.0, *got[`gauge:req.max{env="prod"}`].Value)
+
+	// Остаток 0.5 от hits переносится, не обновлявшийся gauge не отдается.
+	s, _ := ParseLine("hits:1|c|@0.4")
+	a.Add(s)
+	s, _ = ParseLine("temp:-1|g")
+	a.Add(s)
+	got = flushed(a)
+	assert.Len(t, got, 2)
+	assert.Equal(t, int64(3), *got["counter:hits"].Delta)
+	assert.Equal(t, 14.0, *got["gauge:temp"].Value)
+
+	metrics, dropped := a.Flush()
+	assert.Empty(t, metrics)
+	assert.Zero(t, dropped)
+}
+
+func TestAggregator_MaxKeys(t *testing.T) {
+	a := NewAggregator(2)
+	for _, line := range []string{"temp:10|g", "hits:1|c", "hits:2|c", "other:1|c", "req:5|ms"} {
+		s, err := ParseLine(line)
+		require.NoError(t, err)
+		a.Add(s)
+	}
+
+	metrics, dropped := a.Flush()
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, 2, dropped)
+
+	// Не обновлявшийся gauge забыт, и новая серия помещается в лимит
+	s, _ := ParseLine("other:1|c")
+	a.Add(s)
+	metrics, dropped = a.Flush()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "other", metrics[0].ID)
+	assert.Zero(t, dropped)
+}
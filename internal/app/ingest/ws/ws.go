@@ -0,0 +1,99 @@
+// Package ws описывает протокол приема метрик по WebSocket. Сообщения -
+// JSON в текстовых кадрах:
+//
+//	сервер -> агент: {"type":"challenge","nonce":"..."}
+//	агент -> сервер: {"type":"auth","agent":"host-1","ts":1700000000,"hash":"..."}
+//	сервер -> агент: {"type":"ack"}
+//	агент -> сервер: {"type":"batch","seq":1,"idempotency_key":"...","metrics":[...]}
+//	сервер -> агент: {"type":"ack","seq":1,"updated":29} или {"type":"nack","seq":1,"error":"..."}
+//
+// Агент аутентифицируется один раз на соединение, после чего батчи не
+// подписываются. Подпись приветствия включает одноразовый nonce соединения,
+// поэтому перехваченное приветствие нельзя повторить в другом соединении.
+// Батч с уже примененным idempotency_key подтверждается без повторного
+// применения. Отклоненный батч не разрывает соединение; nack с "retry":true
+// означает, что батч не применен и его можно повторить с тем же ключом.
+package ws
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/pkg/util/hasher"
+)
+
+// Path - путь эндпоинта приема метрик по WebSocket.
+const Path = "/updates/ws"
+
+// Типы сообщений.
+const (
+	TypeChallenge = "challenge"
+	TypeAuth      = "auth"
+	TypeBatch     = "batch"
+	TypeAck       = "ack"
+	TypeNack      = "nack"
+)
+
+const (
+	// MaxMessageSize ограничивает размер одного сообщения.
+	MaxMessageSize = 4 << 20
+	// MaxClockSkew - допустимое расхождение времени приветствия с часами сервера.
+	MaxClockSkew = 5 * time.Minute
+)
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Message - сообщение протокола. Набор заполненных полей зависит от Type.
+type Message struct {
+	Type           string           `json:"type"`
+	Seq            uint64           `json:"seq,omitempty"`
+	Nonce          string           `json:"nonce,omitempty"`
+	Agent          string           `json:"agent,omitempty"`
+	TS             int64            `json:"ts,omitempty"`
+	Hash           string           `json:"hash,omitempty"`
+	IdempotencyKey string           `json:"idempotency_key,omitempty"`
+	Metrics        []domain.Metrics `json:"metrics,omitempty"`
+	Updated        int              `json:"updated,omitempty"`
+	Error          string           `json:"error,omitempty"`
+	Retry          bool             `json:"retry,omitempty"`
+}
+
+// NewChallenge создает приветствие сервера со случайным nonce соединения.
+func NewChallenge() Message {
+	return Message{Type: TypeChallenge, Nonce: rand.Text()}
+}
+
+// NewAuth создает приветствие агента в ответ на challenge сервера с nonce.
+// При пустом key подпись не ставится.
+func NewAuth(agent, key, nonce string, now time.Time) Message {
+	m := Message{Type: TypeAuth, Agent: agent, TS: now.Unix()}
+	if key != "" {
+		m.Hash = hasher.Hash(m.authPayload(nonce), key)
+	}
+	return m
+}
+
+// VerifyAuth проверяет приветствие: тип, свежесть и подпись ключом key
+// вместе с nonce, выданным соединению. При пустом key подпись не проверяется.
+func (m *Message) VerifyAuth(key, nonce string, now time.Time) error {
+	if m.Type != TypeAuth {
+		return ErrUnauthorized
+	}
+	if key == "" {
+		return nil
+	}
+	if skew := now.Sub(time.Unix(m.TS, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return ErrUnauthorized
+	}
+	if !hasher.VerifyHash(m.authPayload(nonce), key, m.Hash) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func (m *Message) authPayload(nonce string) string {
+	return m.Agent + ":" + strconv.FormatInt(m.TS, 10) + ":" + nonce
+}
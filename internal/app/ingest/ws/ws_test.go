@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_VerifyAuth(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		msg     Message
+		key     string
+		wantErr bool
+	}{
+		{name: "signed", msg: NewAuth("host", "secret", "nonce", now), key: "secret"},
+		{name: "no key on server", msg: NewAuth("host", "", "nonce", now)},
+		{name: "wrong key", msg: NewAuth("host", "other", "nonce", now), key: "secret", wantErr: true},
+		{name: "unsigned", msg: NewAuth("host", "", "nonce", now), key: "secret", wantErr: true},
+		{name: "stale", msg: NewAuth("host", "secret", "nonce", now.Add(-2*MaxClockSkew)), key: "secret", wantErr: true},
+		{name: "replayed on another connection", msg: NewAuth("host", "secret", "other", now), key: "secret", wantErr: true},
+		{name: "not auth", msg: Message{Type: TypeBatch}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.VerifyAuth(tt.key, "nonce", now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnauthorized)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
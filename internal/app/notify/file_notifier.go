@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// FileNotifier дописывает уведомления в файл в формате JSON lines.
+type FileNotifier struct {
+	name string
+	path string
+	log  *zap.Logger
+	mu   sync.Mutex
+}
+
+var _ interfaces.AlertNotifier = &FileNotifier{}
+
+func NewFileNotifier(name, path string, log *zap.Logger) *FileNotifier {
+	logger := log.Named("file-notifier")
+	return &FileNotifier{name: name, path: path, log: logger}
+}
+
+func (n *FileNotifier) GetID() string {
+	return n.name
+}
+
+func (n *FileNotifier) Notify(_ context.Context, notification domain.AlertNotification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := json.NewEncoder(writer).Encode(notification); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	n.log.Debug("notification written to file", zap.String("path", n.path), zap.String("rule", notification.Rule))
+	return nil
+}
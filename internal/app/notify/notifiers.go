@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// CreateNotifiers создает каналы уведомлений на основе конфигурации.
+// Возвращает каналы по их именам. Отключенный канал ничего не доставляет,
+// но остается в списке, чтобы ссылающиеся на него правила были валидны.
+func CreateNotifiers(cfg *alert.AlertConfig, log *zap.Logger) (map[string]interfaces.AlertNotifier, error) {
+	notifiers := make(map[string]interfaces.AlertNotifier, len(cfg.Channels))
+
+	for _, ch := range cfg.Channels {
+		if ch.Name == "" {
+			return nil, fmt.Errorf("notification channel of type %q has no name", ch.Type)
+		}
+		if _, ok := notifiers[ch.Name]; ok {
+			return nil, fmt.Errorf("duplicate notification channel %s", ch.Name)
+		}
+
+		if !ch.IsEnabled() {
+			notifiers[ch.Name] = newDisabledNotifier(ch.Name, log)
+			continue
+		}
+
+		switch ch.Type {
+		case alert.ChannelWebhook:
+			if ch.URL == "" {
+				return nil, fmt.Errorf("webhook channel %s: empty url", ch.Name)
+			}
+			notifiers[ch.Name] = NewWebhookNotifier(ch.Name, ch.URL, log)
+		case alert.ChannelFile:
+			if ch.Path == "" {
+				return nil, fmt.Errorf("file channel %s: empty path", ch.Name)
+			}
+			notifiers[ch.Name] = NewFileNotifier(ch.Name, ch.Path, log)
+		case alert.ChannelSMTP:
+			if ch.SMTP.Host == "" || ch.SMTP.From == "" || len(ch.SMTP.To) == 0 {
+				return nil, fmt.Errorf("smtp channel %s: host, from and to are required", ch.Name)
+			}
+			notifiers[ch.Name] = NewSMTPNotifier(ch.Name, ch.SMTP, log)
+		default:
+			return nil, fmt.Errorf("notification channel %s: unknown type %q", ch.Name, ch.Type)
+		}
+	}
+
+	return notifiers, nil
+}
+
+// disabledNotifier - канал, отключенный в конфигурации: уведомления
+// только пишутся в лог.
+type disabledNotifier struct {
+	name string
+	log  *zap.Logger
+}
+
+var _ interfaces.AlertNotifier = &disabledNotifier{}
+
+func newDisabledNotifier(name string, log *zap.Logger) *disabledNotifier {
+	return &disabledNotifier{name: name, log: log.Named("disabled-notifier")}
+}
+
+func (n *disabledNotifier) GetID() string {
+	return n.name
+}
+
+func (n *disabledNotifier) Notify(_ context.Context, notification domain.AlertNotification) error {
+	n.log.Debug("notification channel is disabled, skipping",
+		zap.String("channel", n.name),
+		zap.String("rule", notification.Rule),
+	)
+	return nil
+}
@@ -0,0 +1,236 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+var testNotification = domain.AlertNotification{
+	TS:         1700000000,
+	Status:     domain.AlertFiring,
+	Rule:       "high_alloc",
+	MetricID:   "Alloc",
+	MetricType: domain.Gauge,
+	Comparison: domain.CompareGreater,
+	Threshold:  100,
+	Value:      lo.ToPtr(150.0),
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	var got domain.AlertNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier("hook", server.URL, zap.NewNop())
+	require.NoError(t, n.Notify(context.Background(), testNotification))
+	assert.Equal(t, testNotification, got)
+}
+
+func TestFileNotifier_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	n := NewFileNotifier("file", path, zap.NewNop())
+
+	require.NoError(t, n.Notify(context.Background(), testNotification))
+	require.NoError(t, n.Notify(context.Background(), testNotification))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var got domain.AlertNotification
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &got))
+	assert.Equal(t, testNotification, got)
+}
+
+// fakeSMTPServer - минимальный SMTP сервер, принимающий одно письмо.
+func fakeSMTPServer(t *testing.T) (addr string, mail <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	ch := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 OK")
+				ch <- data.String()
+			case cmd == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return l.Addr().String(), ch
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	addr, mail := fakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	n := NewSMTPNotifier("email", alert.SMTPConfig{
+		Host: host,
+		Port: p,
+		From: "alerts@example.com",
+		To:   []string{"ops@example.com"},
+	}, zap.NewNop())
+
+	require.NoError(t, n.Notify(context.Background(), testNotification))
+
+	msg := <-mail
+	assert.Contains(t, msg, "Subject: [FIRING] high_alloc")
+	assert.Contains(t, msg, "To: ops@example.com")
+	assert.Contains(t, msg, "gauge Alloc = 150 (> 100)")
+}
+
+func TestSMTPNotifier_NotifyTimeout(t *testing.T) {
+	// Сервер принимает соединение, но не отвечает
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	host, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	n := NewSMTPNotifier("email", alert.SMTPConfig{Host: host, Port: p, From: "a@b.c", To: []string{"d@e.f"}}, zap.NewNop())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, n.Notify(ctx, testNotification))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCreateNotifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels []alert.ChannelConfig
+		wantErr  bool
+	}{
+		{
+			name: "all types",
+			channels: []alert.ChannelConfig{
+				{Name: "hook", Type: alert.ChannelWebhook, URL: "http://localhost:8081/alerts"},
+				{Name: "file", Type: alert.ChannelFile, Path: "alerts.jsonl"},
+				{Name: "email", Type: alert.ChannelSMTP, SMTP: alert.SMTPConfig{Host: "localhost", From: "a@b.c", To: []string{"d@e.f"}}},
+			},
+		},
+		{
+			name: "disabled channel needs no settings",
+			channels: []alert.ChannelConfig{
+				{Name: "hook", Type: alert.ChannelWebhook, Enabled: lo.ToPtr(false)},
+			},
+		},
+		{
+			name:     "unknown type",
+			channels: []alert.ChannelConfig{{Name: "x", Type: "pager"}},
+			wantErr:  true,
+		},
+		{
+			name:     "webhook without url",
+			channels: []alert.ChannelConfig{{Name: "hook", Type: alert.ChannelWebhook}},
+			wantErr:  true,
+		},
+		{
+			name: "duplicate name",
+			channels: []alert.ChannelConfig{
+				{Name: "file", Type: alert.ChannelFile, Path: "a.jsonl"},
+				{Name: "file", Type: alert.ChannelFile, Path: "b.jsonl"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifiers, err := CreateNotifiers(&alert.AlertConfig{Channels: tt.channels}, zap.NewNop())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, notifiers, len(tt.channels))
+		})
+	}
+}
+
+func TestCreateNotifiers_Disabled(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer server.Close()
+
+	notifiers, err := CreateNotifiers(&alert.AlertConfig{Channels: []alert.ChannelConfig{
+		{Name: "hook", Type: alert.ChannelWebhook, URL: server.URL, Enabled: lo.ToPtr(false)},
+	}}, zap.NewNop())
+	require.NoError(t, err)
+	require.Contains(t, notifiers, "hook")
+
+	require.NoError(t, notifiers["hook"].Notify(context.Background(), testNotification))
+	assert.Zero(t, hits)
+}
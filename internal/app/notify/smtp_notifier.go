@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+const defaultSMTPPort = 25
+
+// SMTPNotifier отправляет уведомления по email.
+type SMTPNotifier struct {
+	name string
+	cfg  alert.SMTPConfig
+	log  *zap.Logger
+}
+
+var _ interfaces.AlertNotifier = &SMTPNotifier{}
+
+func NewSMTPNotifier(name string, cfg alert.SMTPConfig, log *zap.Logger) *SMTPNotifier {
+	logger := log.Named("smtp-notifier")
+	return &SMTPNotifier{name: name, cfg: cfg, log: logger}
+}
+
+func (n *SMTPNotifier) GetID() string {
+	return n.name
+}
+
+// Notify отправляет письмо. Соединение с сервером ограничено сроком ctx.
+func (n *SMTPNotifier) Notify(ctx context.Context, notification domain.AlertNotification) error {
+	port := n.cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(port))
+
+	if err := n.send(ctx, addr, n.message(notification)); err != nil {
+		return err
+	}
+	n.log.Debug("notification sent by email", zap.String("addr", addr), zap.String("rule", notification.Rule))
+	return nil
+}
+
+// send повторяет smtp.SendMail, но устанавливает соединение через ctx
+// и выставляет ему дедлайн ctx.
+func (n *SMTPNotifier) send(ctx context.Context, addr string, msg []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message формирует письмо в формате RFC 5322.
+func (n *SMTPNotifier) message(notification domain.AlertNotification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: [%s] %s\r\n", strings.ToUpper(notification.Status), notification.Rule)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Unix(notification.TS, 0).Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(notification.String())
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/audit"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// WebhookNotifier отправляет уведомление JSON-ом POST запросом на url.
+type WebhookNotifier struct {
+	name   string
+	url    string
+	log    *zap.Logger
+	client *resty.Client
+}
+
+var _ interfaces.AlertNotifier = &WebhookNotifier{}
+
+func NewWebhookNotifier(name, url string, log *zap.Logger) *WebhookNotifier {
+	logger := log.Named("webhook-notifier")
+	return &WebhookNotifier{name: name, url: url, log: logger, client: audit.NewRestyClient(url)}
+}
+
+func (n *WebhookNotifier) GetID() string {
+	return n.name
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification domain.AlertNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.R().SetContext(ctx).SetBody(body).Post(n.url)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("webhook %s responded with status %d", n.url, resp.StatusCode())
+	}
+	n.log.Debug("notification sent to webhook", zap.String("url", n.url), zap.String("rule", notification.Rule))
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/ws"
 	"github.com/bigsm0uk/metrics-alert-server/internal/handler"
 	lm "github.com/bigsm0uk/metrics-alert-server/internal/handler/middleware"
 	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
@@ -23,14 +24,14 @@ func NewRouter(h *handler.MetricHandler, key string) *chi.Mux {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 	r.Use(middleware.CleanPath)
-	r.Use(middleware.AllowContentType("application/json", "text/xml"))
-	r.Use(middleware.Timeout(time.Second * 60))
+	r.Use(middleware.AllowContentType("application/json", "text/xml", "application/x-protobuf", "text/plain"))
+	r.Use(lm.Timeout(time.Second*60, "/api/stream", ws.Path))
 	r.Use(middleware.RealIP)
 	r.Use(middleware.RequestID)
 	r.Use(lm.LoggerMiddleware)
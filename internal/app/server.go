@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/router"
@@ -21,14 +23,19 @@ import (
 )
 
 type Server struct {
-	cfg *config.ServerConfig
-	h   *handler.MetricHandler
-	ms  interfaces.MetricsStore
-	as  *service.AuditService
+	cfg  *config.ServerConfig
+	h    *handler.MetricHandler
+	ms   interfaces.MetricsStore
+	as   *service.AuditService
+	al   *service.AlertService
+	cs   *service.CompactionService
+	ss   *service.StatsdService
+	gs   *service.GraphiteService
+	grpc *grpc.Server
 }
 
-func NewServer(cfg *config.ServerConfig, h *handler.MetricHandler, ms interfaces.MetricsStore, as *service.AuditService) *Server {
-	return &Server{cfg: cfg, h: h, ms: ms, as: as}
+func NewServer(cfg *config.ServerConfig, h *handler.MetricHandler, ms interfaces.MetricsStore, as *service.AuditService, al *service.AlertService, cs *service.CompactionService, ss *service.StatsdService, gs *service.GraphiteService, gsrv *grpc.Server) *Server {
+	return &Server{cfg: cfg, h: h, ms: ms, as: as, al: al, cs: cs, ss: ss, gs: gs, grpc: gsrv}
 }
 
 func (a *Server) Run() error {
@@ -47,11 +54,32 @@ func (a *Server) Run() error {
 
 		ctx := context.Background()
 		a.ms.StartProcess(ctx)
+		a.al.StartProcess(ctx)
+		a.cs.StartProcess(ctx)
+		if err := a.ss.StartProcess(ctx); err != nil {
+			zl.Log.Fatal("failed to start statsd listener", zap.Error(err))
+		}
+		if err := a.gs.StartProcess(ctx); err != nil {
+			zl.Log.Fatal("failed to start graphite listener", zap.Error(err))
+		}
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			zl.Log.Fatal("failed to start server", zap.Error(err))
 		}
 	}()
 
+	if a.cfg.GRPCAddr != "" {
+		lis, err := net.Listen("tcp", a.cfg.GRPCAddr)
+		if err != nil {
+			return err
+		}
+		go func() {
+			zl.Log.Info("starting grpc server", zap.String("Addr", a.cfg.GRPCAddr))
+			if err := a.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				zl.Log.Fatal("failed to start grpc server", zap.Error(err))
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
@@ -60,6 +88,19 @@ func (a *Server) Run() error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	if a.cfg.GRPCAddr != "" {
+		a.stopGRPC(ctx)
+	}
+	if err := a.al.Close(ctx); err != nil {
+		zl.Log.Error("failed to deliver pending alert notifications", zap.Error(err))
+	}
+	a.cs.Close()
+	if err := a.ss.Close(ctx); err != nil {
+		zl.Log.Error("failed to close statsd listener", zap.Error(err))
+	}
+	if err := a.gs.Close(ctx); err != nil {
+		zl.Log.Error("failed to close graphite listener", zap.Error(err))
+	}
 	if err := a.ms.Close(ctx); err != nil {
 		zl.Log.Error("failed to close metric store", zap.Error(err))
 		return err
@@ -77,3 +118,20 @@ func (a *Server) Run() error {
 	zl.Log.Info("server exiting")
 	return nil
 }
+
+// stopGRPC дожидается завершения активных вызовов gRPC, но не дольше ctx:
+// открытый агентом UpdateMetricsStream не должен блокировать остановку.
+func (a *Server) stopGRPC(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		a.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		zl.Log.Warn("grpc graceful stop timed out, closing connections")
+		a.grpc.Stop()
+		<-done
+	}
+}
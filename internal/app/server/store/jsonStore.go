@@ -22,8 +22,8 @@ type JSONStore struct {
 	cfg *store.StoreConfig
 
 	storeInterval time.Duration
-	syncMode      bool // true если интервал = 0
-	ticker        *time.Ticker
+	syncMode      bool         // true если интервал = 0
+	ticker        *time.Ticker // Создается при запуске периодического сохранения
 	stopChan      chan struct{}
 }
 
@@ -42,7 +42,6 @@ func NewJSONStore(r interfaces.MetricsRepository, cfg *store.StoreConfig) (*JSON
 		cfg:           cfg,
 		storeInterval: interval,
 		syncMode:      syncMode,
-		ticker:        time.NewTicker(interval),
 		stopChan:      make(chan struct{}),
 	}
 
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// DefaultHistoryCapacity - емкость кольцевого буфера серии по умолчанию
+// (сутки сэмплов при интервале отправки агента 2 секунды).
+const DefaultHistoryCapacity = 43200
+
+// sampleRing - кольцевой буфер сэмплов одной серии, упорядоченных по времени.
+// Буфер растет по мере записи до capacity, после чего новый сэмпл вытесняет
+// самый старый: серия с редкими обновлениями не занимает полную емкость.
+type sampleRing struct {
+	buf      []domain.Sample
+	start    int
+	size     int
+	capacity int
+}
+
+func (r *sampleRing) push(s domain.Sample) {
+	if r.size == len(r.buf) && len(r.buf) < r.capacity {
+		if r.start != 0 {
+			r.buf = slices.Concat(r.buf[r.start:], r.buf[:r.start])
+			r.start = 0
+		}
+		r.buf = append(r.buf, s)
+		r.size++
+		return
+	}
+	if r.size < len(r.buf) {
+		r.buf[(r.start+r.size)%len(r.buf)] = s
+		r.size++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+func (r *sampleRing) at(i int) domain.Sample {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// dropBefore удаляет сэмплы старше before.
+func (r *sampleRing) dropBefore(before time.Time) {
+	n := sort.Search(r.size, func(i int) bool { return !r.at(i).TS.Before(before) })
+	r.start = (r.start + n) % len(r.buf)
+	r.size -= n
+}
+
+// MemHistory хранит историю значений метрик в памяти:
+// отдельный кольцевой буфер на каждую серию (id + тип).
+type MemHistory struct {
+	capacity int
+	series   map[string]*sampleRing
+	mu       sync.RWMutex
+}
+
+// NewMemHistory создает историю с емкостью capacity сэмплов на серию.
+// Если capacity не положительная, используется DefaultHistoryCapacity.
+func NewMemHistory(capacity int) *MemHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistoryCapacity
+	}
+	return &MemHistory{capacity: capacity, series: make(map[string]*sampleRing)}
+}
+
+func seriesKey(id, t string) string {
+	return t + ":" + id
+}
+
+// Append добавляет сэмпл в серию.
+func (h *MemHistory) Append(id, t string, s domain.Sample) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ring, ok := h.series[seriesKey(id, t)]
+	if !ok {
+		ring = &sampleRing{capacity: h.capacity}
+		h.series[seriesKey(id, t)] = ring
+	}
+	ring.push(s)
+}
+
+// Range возвращает сэмплы серии в интервале [from, to].
+func (h *MemHistory) Range(id, t string, from, to time.Time) []domain.Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ring, ok := h.series[seriesKey(id, t)]
+	if !ok {
+		return nil
+	}
+	first := sort.Search(ring.size, func(i int) bool { return !ring.at(i).TS.Before(from) })
+	var result []domain.Sample
+	for i := first; i < ring.size; i++ {
+		s := ring.at(i)
+		if s.TS.After(to) {
+			break
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// DeleteBefore удаляет сэмплы серии старше before.
+func (h *MemHistory) DeleteBefore(id, t string, before time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ring, ok := h.series[seriesKey(id, t)]
+	if !ok {
+		return
+	}
+	ring.dropBefore(before)
+	if ring.size == 0 {
+		delete(h.series, seriesKey(id, t))
+	}
+}
+
+// Delete удаляет серию вместе со всеми ее сэмплами.
+func (h *MemHistory) Delete(id, t string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.series, seriesKey(id, t))
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestMemHistory(t *testing.T) {
+	h := NewMemHistory(3)
+	start := time.Now()
+	for i := range 5 {
+		h.Append("Alloc", domain.Gauge, domain.Sample{TS: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+
+	// Емкость 3: два самых старых сэмпла вытеснены
+	samples := h.Range("Alloc", domain.Gauge, start, start.Add(time.Minute))
+	require.Len(t, samples, 3)
+	assert.Equal(t, []float64{2, 3, 4}, values(samples))
+
+	samples = h.Range("Alloc", domain.Gauge, start.Add(3*time.Second), start.Add(3*time.Second))
+	assert.Equal(t, []float64{3}, values(samples))
+
+	assert.Empty(t, h.Range("Alloc", domain.Counter, start, start.Add(time.Minute)))
+
+	h.DeleteBefore("Alloc", domain.Gauge, start.Add(4*time.Second))
+	assert.Equal(t, []float64{4}, values(h.Range("Alloc", domain.Gauge, start, start.Add(time.Minute))))
+
+	// Запись после удаления продолжает кольцо
+	h.Append("Alloc", domain.Gauge, domain.Sample{TS: start.Add(5 * time.Second), Value: 5})
+	assert.Equal(t, []float64{4, 5}, values(h.Range("Alloc", domain.Gauge, start, start.Add(time.Minute))))
+
+	h.DeleteBefore("Alloc", domain.Gauge, start.Add(time.Minute))
+	assert.Empty(t, h.Range("Alloc", domain.Gauge, start, start.Add(time.Minute)))
+}
+
+func TestMemHistory_GrowsLazily(t *testing.T) {
+	h := NewMemHistory(DefaultHistoryCapacity)
+	start := time.Now()
+	h.Append("Alloc", domain.Gauge, domain.Sample{TS: start, Value: 1})
+
+	// Серия с одним сэмплом не резервирует всю емкость кольца
+	ring := h.series[seriesKey("Alloc", domain.Gauge)]
+	require.NotNil(t, ring)
+	assert.Len(t, ring.buf, 1)
+	assert.Less(t, cap(ring.buf), 16)
+
+	// Рост после вытеснения из начала буфера сохраняет порядок сэмплов
+	h = NewMemHistory(4)
+	for i := range 2 {
+		h.Append("Alloc", domain.Gauge, domain.Sample{TS: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+	h.DeleteBefore("Alloc", domain.Gauge, start.Add(time.Second))
+	for i := 2; i < 6; i++ {
+		h.Append("Alloc", domain.Gauge, domain.Sample{TS: start.Add(time.Duration(i) * time.Second), Value: float64(i)})
+	}
+	assert.Equal(t, []float64{2, 3, 4, 5}, values(h.Range("Alloc", domain.Gauge, start, start.Add(time.Minute))))
+	assert.Len(t, h.series[seriesKey("Alloc", domain.Gauge)].buf, 4)
+}
+
+func values(samples []domain.Sample) []float64 {
+	result := make([]float64, len(samples))
+	for i, s := range samples {
+		result[i] = s.Value
+	}
+	return result
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 )
 
+// MemStorage хранит метрики по идентификатору серии (domain.SeriesID),
+// поэтому метрики с одним id и разными лейблами не перетирают друг друга.
 type MemStorage struct {
 	db map[string]domain.Metrics
 	mu sync.RWMutex
@@ -18,13 +20,13 @@ func NewMemStorage() *MemStorage {
 func (m *MemStorage) Set(metric domain.Metrics) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.db[metric.ID] = metric
+	m.db[metric.SeriesID()] = metric
 }
 
-func (m *MemStorage) Get(id, t string) (domain.Metrics, bool) {
+func (m *MemStorage) Get(id, t string, labels domain.Labels) (domain.Metrics, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	metric, ok := m.db[id]
+	metric, ok := m.db[domain.SeriesID(id, labels)]
 	if !ok {
 		return domain.Metrics{}, false
 	}
@@ -56,8 +58,14 @@ func (m *MemStorage) GetAll() []domain.Metrics {
 	return result
 }
 
-func (m *MemStorage) Delete(id string) {
+// Delete удаляет метрику указанного типа и сообщает, была ли она сохранена.
+func (m *MemStorage) Delete(id, t string, labels domain.Labels) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.db, id)
+	key := domain.SeriesID(id, labels)
+	if metric, ok := m.db[key]; !ok || metric.MType != t {
+		return false
+	}
+	delete(m.db, key)
+	return true
 }
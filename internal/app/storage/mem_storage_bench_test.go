@@ -29,7 +29,7 @@ func BenchmarkMemStorage_Get(b *testing.B) {
 
 		for b.Loop() {
 			idx := rand.IntN(len(metrics))
-			storage.Get(metrics[idx].ID, metrics[idx].MType)
+			storage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 		}
 	})
 	b.Run("Mutex", func(b *testing.B) {
@@ -41,7 +41,7 @@ func BenchmarkMemStorage_Get(b *testing.B) {
 
 		for b.Loop() {
 			idx := rand.IntN(len(metrics))
-			mStorage.Get(metrics[idx].ID, metrics[idx].MType)
+			mStorage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 		}
 	})
 }
@@ -180,7 +180,7 @@ func BenchmarkMemStorage_GetParallel(b *testing.B) {
 		b.RunParallel(func(pb *testing.PB) {
 			for pb.Next() {
 				idx := rand.IntN(len(metrics))
-				storage.Get(metrics[idx].ID, metrics[idx].MType)
+				storage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 			}
 		})
 	})
@@ -194,7 +194,7 @@ func BenchmarkMemStorage_GetParallel(b *testing.B) {
 		b.RunParallel(func(pb *testing.PB) {
 			for pb.Next() {
 				idx := rand.IntN(len(metrics))
-				mStorage.Get(metrics[idx].ID, metrics[idx].MType)
+				mStorage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 			}
 		})
 	})
@@ -216,7 +216,7 @@ func BenchmarkMemStorage_MixedOperations(b *testing.B) {
 			for pb.Next() {
 				idx := rand.IntN(len(metrics))
 				if rand.IntN(100) < 80 {
-					storage.Get(metrics[idx].ID, metrics[idx].MType)
+					storage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 				} else {
 					storage.Set(*metrics[idx])
 				}
@@ -234,7 +234,7 @@ func BenchmarkMemStorage_MixedOperations(b *testing.B) {
 			for pb.Next() {
 				idx := rand.IntN(len(metrics))
 				if rand.IntN(100) < 80 {
-					mStorage.Get(metrics[idx].ID, metrics[idx].MType)
+					mStorage.Get(metrics[idx].ID, metrics[idx].MType, nil)
 				} else {
 					mStorage.Set(*metrics[idx])
 				}
@@ -22,14 +22,14 @@ func NewMutexMemStorage() *MutexMemStorage {
 func (m *MutexMemStorage) Set(metric domain.Metrics) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.db[metric.ID] = metric
+	m.db[metric.SeriesID()] = metric
 }
 
-// Get получает метрику по ID и типу
-func (m *MutexMemStorage) Get(id, t string) (domain.Metrics, bool) {
+// Get получает метрику по ID, типу и лейблам
+func (m *MutexMemStorage) Get(id, t string, labels domain.Labels) (domain.Metrics, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	metric, ok := m.db[id]
+	metric, ok := m.db[domain.SeriesID(id, labels)]
 	if !ok {
 		return domain.Metrics{}, false
 	}
@@ -63,9 +63,9 @@ func (m *MutexMemStorage) GetAll() []domain.Metrics {
 	return result
 }
 
-// Delete удаляет метрику по ID
-func (m *MutexMemStorage) Delete(id string) {
+// Delete удаляет метрику по ID и лейблам
+func (m *MutexMemStorage) Delete(id string, labels domain.Labels) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	delete(m.db, id)
+	delete(m.db, domain.SeriesID(id, labels))
 }
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// MemRollups хранит агрегированную историю метрик в памяти:
+// упорядоченные по времени агрегаты на каждую серию и разрешение.
+type MemRollups struct {
+	series map[string][]domain.Bucket
+	mu     sync.RWMutex
+}
+
+func NewMemRollups() *MemRollups {
+	return &MemRollups{series: make(map[string][]domain.Bucket)}
+}
+
+func rollupKey(id, t string, resolution time.Duration) string {
+	return seriesKey(id, t) + "@" + resolution.String()
+}
+
+// Save добавляет агрегаты серии, заменяя агрегаты с тем же началом интервала.
+func (r *MemRollups) Save(id, t string, resolution time.Duration, buckets []domain.Bucket) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := rollupKey(id, t, resolution)
+	stored := r.series[key]
+	for _, b := range buckets {
+		i := sort.Search(len(stored), func(i int) bool { return !stored[i].Start.Before(b.Start) })
+		switch {
+		case i < len(stored) && stored[i].Start.Equal(b.Start):
+			stored[i] = b
+		case i == len(stored):
+			stored = append(stored, b)
+		default:
+			stored = append(stored[:i+1], stored[i:]...)
+			stored[i] = b
+		}
+	}
+	r.series[key] = stored
+}
+
+// Range возвращает агрегаты серии, начинающиеся в интервале [from, to].
+func (r *MemRollups) Range(id, t string, resolution time.Duration, from, to time.Time) []domain.Bucket {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored := r.series[rollupKey(id, t, resolution)]
+	first := sort.Search(len(stored), func(i int) bool { return !stored[i].Start.Before(from) })
+	last := sort.Search(len(stored), func(i int) bool { return stored[i].Start.After(to) })
+	if first >= last {
+		return nil
+	}
+	return append([]domain.Bucket(nil), stored[first:last]...)
+}
+
+// DeleteBefore удаляет агрегаты серии, начавшиеся раньше before.
+func (r *MemRollups) DeleteBefore(id, t string, resolution time.Duration, before time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := rollupKey(id, t, resolution)
+	stored := r.series[key]
+	n := sort.Search(len(stored), func(i int) bool { return !stored[i].Start.Before(before) })
+	if n == len(stored) {
+		delete(r.series, key)
+		return
+	}
+	r.series[key] = append([]domain.Bucket(nil), stored[n:]...)
+}
+
+// Delete удаляет агрегаты серии всех разрешений.
+func (r *MemRollups) Delete(id, t string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prefix := seriesKey(id, t) + "@"
+	for key := range r.series {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.series, key)
+		}
+	}
+}
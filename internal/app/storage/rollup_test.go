@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestMemRollups(t *testing.T) {
+	r := NewMemRollups()
+	start := time.Now().Truncate(time.Minute)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * time.Minute) }
+
+	r.Save("Alloc", domain.Gauge, domain.RollupMinute, []domain.Bucket{{Start: at(0), Last: 0}, {Start: at(2), Last: 2}})
+	// Вставка в середину и замена существующего агрегата
+	r.Save("Alloc", domain.Gauge, domain.RollupMinute, []domain.Bucket{{Start: at(1), Last: 1}, {Start: at(2), Last: 20}})
+
+	buckets := r.Range("Alloc", domain.Gauge, domain.RollupMinute, at(0), at(10))
+	assert.Equal(t, []domain.Bucket{{Start: at(0), Last: 0}, {Start: at(1), Last: 1}, {Start: at(2), Last: 20}}, buckets)
+	assert.Empty(t, r.Range("Alloc", domain.Gauge, domain.RollupHour, at(0), at(10)))
+	assert.Len(t, r.Range("Alloc", domain.Gauge, domain.RollupMinute, at(1), at(1)), 1)
+
+	r.DeleteBefore("Alloc", domain.Gauge, domain.RollupMinute, at(2))
+	assert.Equal(t, []domain.Bucket{{Start: at(2), Last: 20}}, r.Range("Alloc", domain.Gauge, domain.RollupMinute, at(0), at(10)))
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// CompareAbsent - условие правила отсутствия: метрика не обновлялась
+// дольше порога (порог в секундах).
+const CompareAbsent = "absent"
+
+// AbsenceRule - правило обнаружения отсутствующих метрик.
+// Если задан MetricID, правило следит за одной метрикой. Если задан Pattern,
+// правило следит за набором метрик агента (регулярное выражение по id)
+// и срабатывает, когда ни одна метрика набора не обновлялась дольше Timeout.
+type AbsenceRule struct {
+	Name       string
+	MetricID   string
+	MetricType string // Необязательный тип метрики для MetricID
+	Pattern    string
+	Timeout    time.Duration
+	Channels   []string // Имена каналов уведомлений
+
+	re *regexp.Regexp
+}
+
+// Validate проверяет корректность правила и компилирует Pattern.
+func (r *AbsenceRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidAlertRule)
+	}
+	if (r.MetricID == "") == (r.Pattern == "") {
+		return fmt.Errorf("%w: rule %s: exactly one of metric_id and pattern must be set", ErrInvalidAlertRule, r.Name)
+	}
+	if r.MetricType != "" && r.MetricType != Counter && r.MetricType != Gauge {
+		return fmt.Errorf("%w: rule %s: %w", ErrInvalidAlertRule, r.Name, ErrInvalidMetricType)
+	}
+	if r.Timeout <= 0 {
+		return fmt.Errorf("%w: rule %s: timeout must be positive", ErrInvalidAlertRule, r.Name)
+	}
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("%w: rule %s: %w", ErrInvalidAlertRule, r.Name, err)
+		}
+		r.re = re
+	}
+	return nil
+}
+
+// Target возвращает идентификатор метрики или шаблон набора метрик.
+func (r *AbsenceRule) Target() string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return r.MetricID
+}
+
+// LastUpdate возвращает самое позднее время обновления среди метрик правила.
+// Второй результат false, если ни одна метрика не относится к правилу.
+func (r *AbsenceRule) LastUpdate(metrics []Metrics) (time.Time, bool) {
+	var (
+		last  time.Time
+		found bool
+	)
+	for i := range metrics {
+		if !r.matches(&metrics[i]) {
+			continue
+		}
+		found = true
+		if metrics[i].UpdatedAt.After(last) {
+			last = metrics[i].UpdatedAt
+		}
+	}
+	return last, found
+}
+
+// MatchingIDs возвращает отсортированные id метрик, к которым относится правило.
+func (r *AbsenceRule) MatchingIDs(metrics []Metrics) []string {
+	var ids []string
+	for i := range metrics {
+		if r.matches(&metrics[i]) {
+			ids = append(ids, metrics[i].ID)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func (r *AbsenceRule) matches(m *Metrics) bool {
+	if r.re != nil {
+		return r.re.MatchString(m.ID)
+	}
+	return m.ID == r.MetricID && (r.MetricType == "" || m.MType == r.MetricType)
+}
+
+// AlertRule возвращает представление правила в виде правила алертинга,
+// где порог - Timeout в секундах, а значение - время с последнего обновления.
+func (r *AbsenceRule) AlertRule() AlertRule {
+	return AlertRule{
+		Name:       r.Name,
+		MetricID:   r.Target(),
+		MetricType: r.MetricType,
+		Comparison: CompareAbsent,
+		Threshold:  r.Timeout.Seconds(),
+		Channels:   r.Channels,
+	}
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Состояния алерта
+const (
+	AlertInactive = "inactive"
+	AlertPending  = "pending"
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Операторы сравнения значения метрики с порогом
+const (
+	CompareGreater      = ">"
+	CompareGreaterEqual = ">="
+	CompareLess         = "<"
+	CompareLessEqual    = "<="
+	CompareEqual        = "=="
+	CompareNotEqual     = "!="
+)
+
+// AlertRule - правило алертинга для одной метрики.
+// Алерт переходит в firing, если условие выполняется не меньше For.
+type AlertRule struct {
+	Name       string
+	MetricID   string
+	MetricType string
+	Comparison string
+	Threshold  float64
+	For        time.Duration
+	Channels   []string // Имена каналов уведомлений
+}
+
+// Validate проверяет корректность правила.
+func (r *AlertRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidAlertRule)
+	}
+	if r.MetricID == "" {
+		return fmt.Errorf("%w: rule %s: empty metric id", ErrInvalidAlertRule, r.Name)
+	}
+	if r.MetricType != Counter && r.MetricType != Gauge {
+		return fmt.Errorf("%w: rule %s: %w", ErrInvalidAlertRule, r.Name, ErrInvalidMetricType)
+	}
+	switch r.Comparison {
+	case CompareGreater, CompareGreaterEqual, CompareLess, CompareLessEqual, CompareEqual, CompareNotEqual:
+	default:
+		return fmt.Errorf("%w: rule %s: unknown comparison %q", ErrInvalidAlertRule, r.Name, r.Comparison)
+	}
+	if r.For < 0 {
+		return fmt.Errorf("%w: rule %s: negative for", ErrInvalidAlertRule, r.Name)
+	}
+	return nil
+}
+
+// Matches возвращает true, если значение удовлетворяет условию правила.
+func (r *AlertRule) Matches(value float64) bool {
+	switch r.Comparison {
+	case CompareGreater:
+		return value > r.Threshold
+	case CompareGreaterEqual:
+		return value >= r.Threshold
+	case CompareLess:
+		return value < r.Threshold
+	case CompareLessEqual:
+		return value <= r.Threshold
+	case CompareEqual:
+		return value == r.Threshold
+	case CompareNotEqual:
+		return value != r.Threshold
+	default:
+		return false
+	}
+}
+
+// Alert - текущее состояние правила алертинга.
+type Alert struct {
+	Rule       string     `json:"rule"`
+	MetricID   string     `json:"metric_id"`
+	MetricType string     `json:"metric_type"`
+	Comparison string     `json:"comparison"`
+	Threshold  float64    `json:"threshold"`
+	State      string     `json:"state"`
+	Value      *float64   `json:"value,omitempty"`
+	ActiveAt   *time.Time `json:"active_at,omitempty"`   // Когда условие начало выполняться
+	FiredAt    *time.Time `json:"fired_at,omitempty"`    // Когда алерт перешел в firing
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"` // Когда алерт перешел в resolved
+}
+
+// AlertNotification - уведомление о срабатывании (firing) или восстановлении (resolved) алерта.
+type AlertNotification struct {
+	TS         int64    `json:"ts"` // Время события
+	Status     string   `json:"status"`
+	Rule       string   `json:"rule"`
+	MetricID   string   `json:"metric_id"`
+	MetricType string   `json:"metric_type"`
+	Comparison string   `json:"comparison"`
+	Threshold  float64  `json:"threshold"`
+	Value      *float64 `json:"value,omitempty"`
+	MetricIDs  []string `json:"metric_ids,omitempty"` // Метрики набора для правила по шаблону; MetricID - сам шаблон
+}
+
+// AffectedIDs возвращает id метрик, к которым относится уведомление.
+func (n *AlertNotification) AffectedIDs() []string {
+	if len(n.MetricIDs) > 0 {
+		return n.MetricIDs
+	}
+	return []string{n.MetricID}
+}
+
+// String возвращает человекочитаемое описание уведомления.
+func (n *AlertNotification) String() string {
+	value := "n/a"
+	if n.Value != nil {
+		value = fmt.Sprintf("%g", *n.Value)
+	}
+	return fmt.Sprintf("[%s] %s: %s %s = %s (%s %g)",
+		n.Status, n.Rule, n.MetricType, n.MetricID, value, n.Comparison, n.Threshold)
+}
@@ -1,8 +1,15 @@
 package domain
 
+// Действия над метриками в событиях аудита. Пустое действие - обновление.
+const (
+	AuditActionDelete = "delete"
+	AuditActionReset  = "reset"
+)
+
 type AuditMessage struct {
-	TS      int64    `json:"ts"`         // Время события
-	Metrics []string `json:"metrics"`    // Наименования полученный метрик
-	IPAddr  string   `json:"ip_address"` // IP адрес входящего запроса
+	TS      int64    `json:"ts"`               // Время события
+	Metrics []string `json:"metrics"`          // Наименования полученный метрик
+	IPAddr  string   `json:"ip_address"`       // IP адрес входящего запроса
+	Action  string   `json:"action,omitempty"` // Действие; пустое - обновление
 }
 type AuditMessages []AuditMessage
@@ -8,4 +8,19 @@ var (
 	ErrInvalidMetricType  = errors.New("invalid metric type")
 	ErrInvalidMetricValue = errors.New("invalid metric value")
 	ErrMissingMetricValue = errors.New("missing value")
+	ErrInvalidAlertRule   = errors.New("invalid alert rule")
+	ErrSilenceNotFound    = errors.New("silence not found")
+	ErrInvalidSilence     = errors.New("invalid silence")
+	ErrInvalidRangeQuery  = errors.New("invalid range query")
+	ErrInvalidLabel       = errors.New("invalid label name")
+	ErrInvalidHistogram   = errors.New("invalid histogram")
+	ErrInvalidSummary     = errors.New("invalid summary")
+	ErrInvalidQuantile    = errors.New("invalid quantile")
+	ErrInvalidMetadata    = errors.New("invalid metadata")
+	ErrInvalidListQuery   = errors.New("invalid list query")
+
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyInUse   = errors.New("request with this idempotency key is in progress")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key is reused with a different request")
+	ErrIdempotencyStoreFull  = errors.New("too many idempotency keys in progress")
 )
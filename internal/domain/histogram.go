@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// DefaultHistogramBuckets - верхние границы бакетов по умолчанию
+// (совпадают с границами клиентских библиотек Prometheus).
+var DefaultHistogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// HistogramValue - состояние histogram: Counts[i] - число наблюдений
+// в бакете (Bounds[i-1], Bounds[i]], последний элемент Counts - бакет +Inf,
+// поэтому len(Counts) == len(Bounds)+1. Count и Sum - число и сумма всех
+// наблюдений.
+type HistogramValue struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Count  uint64    `json:"count"`
+	Sum    float64   `json:"sum"`
+}
+
+// NewHistogram создает пустую histogram с заданными границами бакетов.
+func NewHistogram(bounds []float64) *HistogramValue {
+	return &HistogramValue{Bounds: slices.Clone(bounds), Counts: make([]uint64, len(bounds)+1)}
+}
+
+// Validate проверяет, что границы конечны и строго возрастают, а число
+// бакетов и общее число наблюдений согласованы.
+func (h *HistogramValue) Validate() error {
+	for i, b := range h.Bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("%w: bucket bound %v", ErrInvalidHistogram, b)
+		}
+		if i > 0 && b <= h.Bounds[i-1] {
+			return fmt.Errorf("%w: bucket bounds must be strictly increasing", ErrInvalidHistogram)
+		}
+	}
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("%w: expected %d bucket counts, got %d", ErrInvalidHistogram, len(h.Bounds)+1, len(h.Counts))
+	}
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total != h.Count {
+		return fmt.Errorf("%w: bucket counts sum to %d, count is %d", ErrInvalidHistogram, total, h.Count)
+	}
+	if math.IsNaN(h.Sum) || math.IsInf(h.Sum, 0) {
+		return fmt.Errorf("%w: sum %v", ErrInvalidHistogram, h.Sum)
+	}
+	return nil
+}
+
+// Observe добавляет одно наблюдение.
+func (h *HistogramValue) Observe(v float64) {
+	i, _ := slices.BinarySearch(h.Bounds, v)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += v
+}
+
+// SameBounds сообщает, совпадают ли границы бакетов.
+func (h *HistogramValue) SameBounds(o *HistogramValue) bool {
+	return slices.Equal(h.Bounds, o.Bounds)
+}
+
+// Merge возвращает сумму двух histogram с одинаковыми границами.
+// При несовпадении границ раскладка считается измененной и
+// возвращается копия o, как при сбросе счетчика.
+func (h *HistogramValue) Merge(o *HistogramValue) *HistogramValue {
+	if h == nil || !h.SameBounds(o) {
+		return o.Clone()
+	}
+	out := h.Clone()
+	for i, c := range o.Counts {
+		out.Counts[i] += c
+	}
+	out.Count += o.Count
+	out.Sum += o.Sum
+	return out
+}
+
+// Clone возвращает глубокую копию.
+func (h *HistogramValue) Clone() *HistogramValue {
+	if h == nil {
+		return nil
+	}
+	return &HistogramValue{Bounds: slices.Clone(h.Bounds), Counts: slices.Clone(h.Counts), Count: h.Count, Sum: h.Sum}
+}
+
+// Cumulative возвращает накопленные числа наблюдений по бакетам:
+// i-й элемент - число наблюдений <= Bounds[i], последний - Count.
+func (h *HistogramValue) Cumulative() []uint64 {
+	out := make([]uint64, len(h.Counts))
+	var acc uint64
+	for i, c := range h.Counts {
+		acc += c
+		out[i] = acc
+	}
+	return out
+}
+
+// Mean возвращает среднее значение наблюдений.
+func (h *HistogramValue) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// String возвращает краткое текстовое представление: count=N sum=S.
+func (h *HistogramValue) String() string {
+	return "count=" + strconv.FormatUint(h.Count, 10) + " sum=" + strconv.FormatFloat(h.Sum, 'g', -1, 64)
+}
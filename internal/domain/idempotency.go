@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxIdempotencyKey - максимальная длина ключа идемпотентности.
+const MaxIdempotencyKey = 255
+
+// IdempotencyRecord - запрос, выполненный с ключом идемпотентности, и
+// ответ на него. Повтор запроса с тем же ключом до ExpiresAt получает
+// сохраненный ответ, а изменения не применяются повторно.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string // Хеш метода, пути и тела запроса
+	Status      int    // HTTP статус ответа; ноль - запрос еще выполняется
+	ContentType string
+	Hash        string // Заголовок HashSHA256 ответа
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Done сообщает, что ответ на запрос уже сохранен.
+func (r *IdempotencyRecord) Done() bool {
+	return r.Status != 0
+}
+
+// Expired сообщает, что ключ освободился к моменту now.
+func (r *IdempotencyRecord) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// ValidateIdempotencyKey проверяет, что ключ непустой, не длиннее
+// MaxIdempotencyKey и состоит из печатных ASCII символов.
+func ValidateIdempotencyKey(key string) error {
+	if key == "" || len(key) > MaxIdempotencyKey {
+		return fmt.Errorf("%w: length must be between 1 and %d", ErrInvalidIdempotencyKey, MaxIdempotencyKey)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return fmt.Errorf("%w: only printable ASCII characters are allowed", ErrInvalidIdempotencyKey)
+		}
+	}
+	return nil
+}
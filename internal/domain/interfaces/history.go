@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// SampleRepository - хранилище истории значений метрик
+type SampleRepository interface {
+	Samples(ctx context.Context, id, metricType string, from, to time.Time) ([]domain.Sample, error)
+	DeleteSamplesBefore(ctx context.Context, id, metricType string, before time.Time) error
+	// QueryRange возвращает значения серии, выровненные по точкам запроса
+	QueryRange(ctx context.Context, q domain.RangeQuery) ([]domain.Sample, error)
+}
+
+// RollupRepository - хранилище агрегированной истории метрик
+type RollupRepository interface {
+	SaveBuckets(ctx context.Context, id, metricType string, resolution time.Duration, buckets []domain.Bucket) error
+	Buckets(ctx context.Context, id, metricType string, resolution time.Duration, from, to time.Time) ([]domain.Bucket, error)
+	DeleteBucketsBefore(ctx context.Context, id, metricType string, resolution time.Duration, before time.Time) error
+}
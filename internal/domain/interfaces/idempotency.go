@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey занимает ключ record.Key, если он свободен или
+	// истек к моменту now, и возвращает nil. Иначе возвращает существующую запись.
+	ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error)
+	// SaveIdempotencyRecord сохраняет ответ на запрос с занятым ключом.
+	SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error
+	// DeleteIdempotencyKey освобождает ключ, например после ошибки сервера.
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	// DeleteExpiredIdempotencyKeys удаляет ключи, истекшие к моменту now.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+type MetadataRepository interface {
+	SaveMetadata(ctx context.Context, metadata *domain.MetricMetadata) error
+	Metadata(ctx context.Context) ([]domain.MetricMetadata, error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// AlertNotifier - канал доставки уведомлений об алертах
+type AlertNotifier interface {
+	GetID() string
+	Notify(ctx context.Context, n domain.AlertNotification) error
+}
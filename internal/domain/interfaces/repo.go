@@ -8,11 +8,19 @@ import (
 
 type MetricsRepository interface {
 	SaveOrUpdate(ctx context.Context, metric *domain.Metrics) error
-	Metric(ctx context.Context, id, metricType string) (*domain.Metrics, error)
+	Metric(ctx context.Context, id, metricType string, labels domain.Labels) (*domain.Metrics, error)
 	MetricList(ctx context.Context) ([]domain.Metrics, error)
 
 	SaveOrUpdateBatch(ctx context.Context, metrics []*domain.Metrics) error
 	MetricListByType(ctx context.Context, metricType string) ([]domain.Metrics, error)
+	// MetricPage возвращает не более q.Limit метрик, прошедших фильтры
+	// запроса, в порядке сортировки после курсора q.After.
+	MetricPage(ctx context.Context, q domain.MetricQuery) ([]domain.Metrics, error)
+
+	// Delete удаляет метрику; domain.ErrMetricNotFound, если ее нет.
+	Delete(ctx context.Context, id, metricType string, labels domain.Labels) error
+	// DeleteBatch удаляет метрики по id, типу и лейблам; отсутствующие пропускаются.
+	DeleteBatch(ctx context.Context, metrics []domain.Metrics) error
 
 	Ping(ctx context.Context) error
 	Close() error
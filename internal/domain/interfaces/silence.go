@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+type SilenceRepository interface {
+	SaveSilence(ctx context.Context, silence *domain.Silence) error
+	Silences(ctx context.Context) ([]domain.Silence, error)
+	DeleteSilence(ctx context.Context, id string) error
+}
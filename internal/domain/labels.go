@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Labels - набор лейблов метрики. Метрика идентифицируется тройкой
+// id + тип + лейблы; пустой набор эквивалентен отсутствию лейблов.
+type Labels map[string]string
+
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.\-]*$`)
+
+// Validate проверяет имена лейблов.
+func (l Labels) Validate() error {
+	for k := range l {
+		if !labelNameRe.MatchString(k) {
+			return fmt.Errorf("%w: %q", ErrInvalidLabel, k)
+		}
+	}
+	return nil
+}
+
+// String возвращает лейблы в каноническом виде {k1="v1",k2="v2"} с
+// сортировкой по имени; для пустого набора - пустую строку.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range slices.Sorted(maps.Keys(l)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Equal сравнивает наборы лейблов; nil и пустой набор равны.
+func (l Labels) Equal(other Labels) bool {
+	return maps.Equal(l, other)
+}
+
+// Clone возвращает копию набора; для пустого набора - nil.
+func (l Labels) Clone() Labels {
+	if len(l) == 0 {
+		return nil
+	}
+	return maps.Clone(l)
+}
+
+// SeriesID возвращает идентификатор серии вида id{k="v"}, под которым
+// хранятся история и агрегаты. Для метрики без лейблов совпадает с id.
+func SeriesID(id string, labels Labels) string {
+	return id + labels.String()
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Поля сортировки списка метрик.
+const (
+	SortByID        = "id"
+	SortByType      = "type"
+	SortByUpdatedAt = "updated_at"
+)
+
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// MetricQuery - параметры выборки списка метрик. Фильтры Type, Prefix и
+// Match (регулярное выражение для id, см. validateMatch) объединяются по И. Список
+// упорядочен по SortBy, при равенстве - по id, серии и типу, так что
+// порядок полный и позволяет листать страницы курсором After.
+type MetricQuery struct {
+	Type   string
+	Prefix string
+	Match  string
+	SortBy string
+	Desc   bool
+	Limit  int
+	After  *MetricCursor
+}
+
+// MetricCursor - позиция в списке: ключ сортировки последней отданной
+// метрики. Sort - сортировка, для которой выдан курсор.
+type MetricCursor struct {
+	Sort      string    `json:"o"`
+	ID        string    `json:"i"`
+	SeriesID  string    `json:"s"`
+	Type      string    `json:"t"`
+	UpdatedAt time.Time `json:"u"`
+}
+
+// Validate проверяет фильтры, сортировку, лимит и соответствие курсора
+// сортировке.
+func (q *MetricQuery) Validate() error {
+	if q.Type != "" && !IsMetricType(q.Type) {
+		return ErrInvalidMetricType
+	}
+	if q.Match != "" {
+		if err := validateMatch(q.Match); err != nil {
+			return fmt.Errorf("%w: match: %w", ErrInvalidListQuery, err)
+		}
+	}
+	switch q.SortBy {
+	case SortByID, SortByType, SortByUpdatedAt:
+	default:
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidListQuery, q.SortBy)
+	}
+	if q.Limit < 1 || q.Limit > MaxListLimit {
+		return fmt.Errorf("%w: limit must be in [1, %d]", ErrInvalidListQuery, MaxListLimit)
+	}
+	if q.After != nil && q.After.Sort != q.Sort() {
+		return fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidListQuery, q.After.Sort)
+	}
+	return nil
+}
+
+// validateMatch проверяет, что выражение одинаково понимают regexp Go и
+// оператор ~ Postgres. Из экранирований допускаются только знаки
+// пунктуации, из групп (?...) - только (?:...). Классы вроде \d, \pL и
+// [[:alpha:]] запрещены: в Go они ASCII или Unicode, а в Postgres зависят
+// от локали базы.
+func validateMatch(expr string) error {
+	if _, err := regexp.Compile(expr); err != nil {
+		return err
+	}
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\':
+			i++
+			if c := expr[i]; c >= 0x80 || c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) {
+				return fmt.Errorf("escape \\%c is not supported", c)
+			}
+		case strings.HasPrefix(expr[i:], "(?") && !strings.HasPrefix(expr[i:], "(?:"):
+			return errors.New("only (?:...) groups are supported")
+		case strings.HasPrefix(expr[i:], "[:"):
+			return errors.New("character class names are not supported")
+		}
+	}
+	return nil
+}
+
+// Sort возвращает сортировку в виде параметра запроса: поле с "-" для
+// убывающего порядка.
+func (q *MetricQuery) Sort() string {
+	if q.Desc {
+		return "-" + q.SortBy
+	}
+	return q.SortBy
+}
+
+// Filter возвращает функцию отбора метрик по фильтрам запроса.
+// Запрос должен быть провалидирован.
+func (q *MetricQuery) Filter() func(m *Metrics) bool {
+	var re *regexp.Regexp
+	if q.Match != "" {
+		re = regexp.MustCompile(q.Match)
+	}
+	return func(m *Metrics) bool {
+		return (q.Type == "" || m.MType == q.Type) &&
+			strings.HasPrefix(m.ID, q.Prefix) &&
+			(re == nil || re.MatchString(m.ID))
+	}
+}
+
+// Cursor возвращает курсор, указывающий на метрику m.
+func (q *MetricQuery) Cursor(m *Metrics) *MetricCursor {
+	return &MetricCursor{Sort: q.Sort(), ID: m.ID, SeriesID: m.SeriesID(), Type: m.MType, UpdatedAt: m.UpdatedAt}
+}
+
+// Compare сравнивает позиции a и b в порядке списка.
+func (q *MetricQuery) Compare(a, b *MetricCursor) int {
+	var c int
+	switch q.SortBy {
+	case SortByType:
+		c = cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.ID, b.ID), cmp.Compare(a.SeriesID, b.SeriesID))
+	case SortByUpdatedAt:
+		c = cmp.Or(a.UpdatedAt.Compare(b.UpdatedAt), cmp.Compare(a.ID, b.ID), cmp.Compare(a.SeriesID, b.SeriesID), cmp.Compare(a.Type, b.Type))
+	default:
+		c = cmp.Or(cmp.Compare(a.ID, b.ID), cmp.Compare(a.SeriesID, b.SeriesID), cmp.Compare(a.Type, b.Type))
+	}
+	if q.Desc {
+		return -c
+	}
+	return c
+}
+
+// Encode возвращает курсор в виде непрозрачной строки для параметра cursor.
+func (c *MetricCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeMetricCursor разбирает курсор, полученный из Encode.
+func DecodeMetricCursor(s string) (*MetricCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+	var c MetricCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidListQuery)
+	}
+	return &c, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxMetadataDescription = 1024
+	maxMetadataField       = 255
+)
+
+// MetricMetadata - описание метрики для людей: что она означает, в каких
+// единицах измеряется и кто за нее отвечает. Привязано к id метрики и
+// относится ко всем ее типам и сериям.
+type MetricMetadata struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description,omitempty"`
+	Unit        string    `json:"unit,omitempty"`
+	Owner       string    `json:"owner,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate проверяет id и длину полей; unit и owner должны быть однострочными.
+func (m *MetricMetadata) Validate() error {
+	if m.ID == "" {
+		return fmt.Errorf("%w: empty metric id", ErrInvalidMetadata)
+	}
+	if utf8.RuneCountInString(m.Description) > maxMetadataDescription {
+		return fmt.Errorf("%w: description is longer than %d characters", ErrInvalidMetadata, maxMetadataDescription)
+	}
+	for name, v := range map[string]string{"unit": m.Unit, "owner": m.Owner} {
+		if utf8.RuneCountInString(v) > maxMetadataField {
+			return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidMetadata, name, maxMetadataField)
+		}
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("%w: %s must be a single line", ErrInvalidMetadata, name)
+		}
+	}
+	return nil
+}
@@ -1,23 +1,41 @@
 package domain
 
-import "github.com/goccy/go-json"
+import (
+	"time"
+
+	"github.com/goccy/go-json"
+)
 
 const (
-	Counter = "counter"
-	Gauge   = "gauge"
+	Counter   = "counter"
+	Gauge     = "gauge"
+	Histogram = "histogram"
+	Summary   = "summary"
 )
 
+// IsMetricType сообщает, поддерживается ли тип метрики.
+func IsMetricType(t string) bool {
+	return t == Counter || t == Gauge || t == Histogram || t == Summary
+}
+
 // NOTE: Не усложняем пример, вводя иерархическую вложенность структур.
 // Органичиваясь плоской моделью.
 // Delta и Value объявлены через указатели,
 // что бы отличать значение "0", от не заданного значения
 // и соответственно не кодировать в структуру.
+// Histogram и Summary заполняются только для метрик соответствующего типа.
+// Labels - необязательные лейблы, вместе с ID и MType определяют серию.
+// UpdatedAt - время последнего обновления, заполняется репозиторием.
 type Metrics struct {
-	ID    string   `json:"id"`
-	MType string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
-	Hash  string   `json:"hash,omitempty"`
+	ID        string          `json:"id"`
+	MType     string          `json:"type"`
+	Labels    Labels          `json:"labels,omitempty"`
+	Delta     *int64          `json:"delta,omitempty"`
+	Value     *float64        `json:"value,omitempty"`
+	Histogram *HistogramValue `json:"histogram,omitempty"`
+	Summary   *SummaryValue   `json:"summary,omitempty"`
+	Hash      string          `json:"hash,omitempty"`
+	UpdatedAt time.Time       `json:"-"`
 }
 
 func (m *Metrics) String() string {
@@ -27,3 +45,25 @@ func (m *Metrics) String() string {
 	}
 	return string(json)
 }
+
+// SeriesID возвращает идентификатор серии метрики (см. SeriesID).
+func (m *Metrics) SeriesID() string {
+	return SeriesID(m.ID, m.Labels)
+}
+
+// NumericValue возвращает значение метрики в виде числа:
+// value для gauge и delta для counter. Второй результат false, если значение
+// не задано или у метрики нет одного числового значения (histogram, summary).
+func (m *Metrics) NumericValue() (float64, bool) {
+	switch m.MType {
+	case Gauge:
+		if m.Value != nil {
+			return *m.Value, true
+		}
+	case Counter:
+		if m.Delta != nil {
+			return float64(*m.Delta), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Разрешения агрегированной истории
+const (
+	RollupMinute = time.Minute
+	RollupHour   = time.Hour
+)
+
+// Bucket - агрегат сэмплов серии за интервал [Start, Start + разрешение).
+type Bucket struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Sum   float64   `json:"sum"`
+	Count int64     `json:"count"`
+	Last  float64   `json:"last"`
+}
+
+// Avg возвращает среднее значение сэмплов интервала.
+func (b *Bucket) Avg() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return b.Sum / float64(b.Count)
+}
+
+// merge добавляет к агрегату более поздний агрегат o.
+func (b *Bucket) merge(o Bucket) {
+	b.Min = min(b.Min, o.Min)
+	b.Max = max(b.Max, o.Max)
+	b.Sum += o.Sum
+	b.Count += o.Count
+	b.Last = o.Last
+}
+
+// Downsample агрегирует упорядоченные по времени сэмплы в интервалы длиной resolution.
+func Downsample(samples []Sample, resolution time.Duration) []Bucket {
+	var buckets []Bucket
+	for _, s := range samples {
+		b := Bucket{Start: s.TS.Truncate(resolution), Min: s.Value, Max: s.Value, Sum: s.Value, Count: 1, Last: s.Value}
+		buckets = appendBucket(buckets, b)
+	}
+	return buckets
+}
+
+// MergeBuckets объединяет упорядоченные по времени агрегаты в интервалы длиной resolution,
+// кратной разрешению исходных агрегатов.
+func MergeBuckets(buckets []Bucket, resolution time.Duration) []Bucket {
+	var result []Bucket
+	for _, b := range buckets {
+		b.Start = b.Start.Truncate(resolution)
+		result = appendBucket(result, b)
+	}
+	return result
+}
+
+func appendBucket(buckets []Bucket, b Bucket) []Bucket {
+	if n := len(buckets); n > 0 && buckets[n-1].Start.Equal(b.Start) {
+		buckets[n-1].merge(b)
+		return buckets
+	}
+	return append(buckets, b)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sample - значение метрики в момент времени.
+// Для counter хранится накопленное значение после обновления.
+type Sample struct {
+	TS    time.Time `json:"ts"`
+	Value float64   `json:"value"`
+}
+
+// Ограничения запроса по диапазону
+const (
+	// RangeLookback - на сколько назад от точки выравнивания ищется последний сэмпл.
+	RangeLookback = 5 * time.Minute
+	// MaxRangePoints - максимальное число точек в ответе на запрос по диапазону.
+	MaxRangePoints = 11000
+)
+
+// RangeQuery - запрос истории серии в интервале [Start, End] с шагом Step.
+// Значение в точке t - последний сэмпл в интервале (t - RangeLookback, t].
+type RangeQuery struct {
+	ID    string
+	MType string
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// Validate проверяет корректность запроса.
+func (q *RangeQuery) Validate() error {
+	if q.ID == "" {
+		return fmt.Errorf("%w: empty metric id", ErrInvalidRangeQuery)
+	}
+	if q.MType != Counter && q.MType != Gauge {
+		return fmt.Errorf("%w: %w", ErrInvalidRangeQuery, ErrInvalidMetricType)
+	}
+	if q.Step <= 0 {
+		return fmt.Errorf("%w: step must be positive", ErrInvalidRangeQuery)
+	}
+	if q.End.Before(q.Start) {
+		return fmt.Errorf("%w: end must not be before start", ErrInvalidRangeQuery)
+	}
+	if q.End.Sub(q.Start)/q.Step >= MaxRangePoints {
+		return fmt.Errorf("%w: exceeded maximum of %d points, increase step", ErrInvalidRangeQuery, MaxRangePoints)
+	}
+	return nil
+}
+
+// Align выравнивает упорядоченные по времени сэмплы по точкам запроса.
+// samples должны покрывать интервал [Start - RangeLookback, End].
+// Точки без сэмплов в окне поиска пропускаются.
+func (q *RangeQuery) Align(samples []Sample) []Sample {
+	var (
+		result []Sample
+		next   int
+	)
+	for t := q.Start; !t.After(q.End); t = t.Add(q.Step) {
+		for next < len(samples) && !samples[next].TS.After(t) {
+			next++
+		}
+		if next == 0 {
+			continue
+		}
+		last := samples[next-1]
+		if t.Sub(last.TS) >= RangeLookback {
+			continue
+		}
+		result = append(result, Sample{TS: t, Value: last.Value})
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Silence - подавление уведомлений для метрик, подходящих под MetricID,
+// в интервале [StartsAt, EndsAt). Если IsRegex, MetricID - регулярное
+// выражение, которое должно совпасть с id метрики целиком.
+type Silence struct {
+	ID        string    `json:"id"`
+	MetricID  string    `json:"metric_id"`
+	IsRegex   bool      `json:"is_regex"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	re *regexp.Regexp // Скомпилированный MetricID, если IsRegex
+}
+
+// Validate проверяет корректность подавления и компилирует регулярное
+// выражение MetricID, чтобы Matches не компилировал его на каждый вызов.
+func (s *Silence) Validate() error {
+	if s.MetricID == "" {
+		return fmt.Errorf("%w: empty metric id", ErrInvalidSilence)
+	}
+	if s.IsRegex {
+		re, err := compileSilence(s.MetricID)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSilence, err)
+		}
+		s.re = re
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidSilence)
+	}
+	return nil
+}
+
+// Matches возвращает true, если подавление относится к метрике id.
+func (s *Silence) Matches(id string) bool {
+	if !s.IsRegex {
+		return s.MetricID == id
+	}
+	if s.re == nil {
+		re, err := compileSilence(s.MetricID)
+		if err != nil {
+			return false
+		}
+		s.re = re
+	}
+	return s.re.MatchString(id)
+}
+
+// compileSilence компилирует выражение подавления с привязкой к началу и
+// концу id, как матчеры Alertmanager: подавление cpu не затрагивает cpu_temp.
+func compileSilence(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// IsActive возвращает true, если подавление действует в момент now.
+func (s *Silence) IsActive(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
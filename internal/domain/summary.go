@@ -0,0 +1,255 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// DefaultSummaryAccuracy - относительная точность квантилей summary по умолчанию.
+const DefaultSummaryAccuracy = 0.01
+
+// MaxSummaryBins - предельное число бинов в каждой из полуосей sketch.
+// При превышении младшие бины (наименьшие по модулю значения) схлопываются
+// в один, так что точность сохраняется для верхних квантилей.
+const MaxSummaryBins = 2048
+
+const (
+	// minSummaryValue - значения меньше по модулю учитываются в нулевом бине.
+	minSummaryValue = 1e-9
+	// maxSummaryIndex ограничивает индексы бинов, пришедших от клиентов.
+	maxSummaryIndex = 1 << 30
+)
+
+// SummaryQuantiles - квантили, которые публикуются в экспозиции Prometheus.
+var SummaryQuantiles = []float64{0.5, 0.9, 0.99}
+
+// SketchBins - плотное хранилище бинов sketch: Counts[i] - число
+// наблюдений в бине с индексом Offset+i.
+type SketchBins struct {
+	Offset int      `json:"offset"`
+	Counts []uint64 `json:"counts"`
+}
+
+// SummaryValue - состояние summary в виде DDSketch с относительной
+// точностью Accuracy. Положительное наблюдение x попадает в бин
+// ceil(log_g(x)), где g = (1+Accuracy)/(1-Accuracy); отрицательные
+// учитываются по модулю в Negative, близкие к нулю - в Zero. Sketch с
+// одинаковой точностью складываются без потери точности.
+type SummaryValue struct {
+	Accuracy float64    `json:"accuracy"`
+	Positive SketchBins `json:"positive"`
+	Negative SketchBins `json:"negative"`
+	Zero     uint64     `json:"zero"`
+	Count    uint64     `json:"count"`
+	Sum      float64    `json:"sum"`
+	Min      float64    `json:"min"`
+	Max      float64    `json:"max"`
+}
+
+// NewSummary создает пустой sketch с заданной относительной точностью.
+func NewSummary(accuracy float64) *SummaryValue {
+	return &SummaryValue{Accuracy: accuracy}
+}
+
+// Validate проверяет точность, размеры хранилищ и согласованность
+// числа наблюдений.
+func (s *SummaryValue) Validate() error {
+	if !(s.Accuracy > 0 && s.Accuracy < 1) {
+		return fmt.Errorf("%w: accuracy must be in (0, 1), got %v", ErrInvalidSummary, s.Accuracy)
+	}
+	total := s.Zero
+	for _, bins := range []SketchBins{s.Positive, s.Negative} {
+		if len(bins.Counts) > MaxSummaryBins {
+			return fmt.Errorf("%w: more than %d bins", ErrInvalidSummary, MaxSummaryBins)
+		}
+		if bins.Offset < -maxSummaryIndex || bins.Offset > maxSummaryIndex {
+			return fmt.Errorf("%w: bin offset %d out of range", ErrInvalidSummary, bins.Offset)
+		}
+		for _, c := range bins.Counts {
+			total += c
+		}
+	}
+	if total != s.Count {
+		return fmt.Errorf("%w: bin counts sum to %d, count is %d", ErrInvalidSummary, total, s.Count)
+	}
+	for _, v := range []float64{s.Sum, s.Min, s.Max} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("%w: non-finite sum, min or max", ErrInvalidSummary)
+		}
+	}
+	if s.Count > 0 && s.Min > s.Max {
+		return fmt.Errorf("%w: min %v is greater than max %v", ErrInvalidSummary, s.Min, s.Max)
+	}
+	return nil
+}
+
+// Observe добавляет одно наблюдение.
+func (s *SummaryValue) Observe(v float64) {
+	switch {
+	case v > minSummaryValue:
+		s.Positive.add(s.index(v), 1)
+	case v < -minSummaryValue:
+		s.Negative.add(s.index(-v), 1)
+	default:
+		s.Zero++
+	}
+	if s.Count == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.Count == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.Count++
+	s.Sum += v
+}
+
+// Merge возвращает объединение двух sketch с одинаковой точностью.
+// При несовпадении точности бины несопоставимы, и, как и для histogram,
+// возвращается копия o.
+func (s *SummaryValue) Merge(o *SummaryValue) *SummaryValue {
+	if s == nil || s.Accuracy != o.Accuracy {
+		return o.Clone()
+	}
+	out := s.Clone()
+	out.Positive.merge(o.Positive)
+	out.Negative.merge(o.Negative)
+	out.Zero += o.Zero
+	if o.Count > 0 {
+		if out.Count == 0 || o.Min < out.Min {
+			out.Min = o.Min
+		}
+		if out.Count == 0 || o.Max > out.Max {
+			out.Max = o.Max
+		}
+	}
+	out.Count += o.Count
+	out.Sum += o.Sum
+	return out
+}
+
+// Clone возвращает глубокую копию.
+func (s *SummaryValue) Clone() *SummaryValue {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Positive.Counts = slices.Clone(s.Positive.Counts)
+	out.Negative.Counts = slices.Clone(s.Negative.Counts)
+	return &out
+}
+
+// Quantile возвращает оценку квантиля q из [0, 1] с относительной
+// погрешностью не более Accuracy. Для пустого sketch возвращает NaN.
+func (s *SummaryValue) Quantile(q float64) float64 {
+	if s.Count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return s.Min
+	}
+	if q >= 1 {
+		return s.Max
+	}
+
+	rank := q * float64(s.Count-1)
+	var acc uint64
+	v := s.Max
+	// Отрицательные значения обходим от больших по модулю к меньшим
+	for i := len(s.Negative.Counts) - 1; i >= 0; i-- {
+		if acc += s.Negative.Counts[i]; float64(acc) > rank {
+			return min(max(-s.value(s.Negative.Offset+i), s.Min), s.Max)
+		}
+	}
+	if acc += s.Zero; float64(acc) > rank {
+		return min(max(0, s.Min), s.Max)
+	}
+	for i, c := range s.Positive.Counts {
+		if acc += c; float64(acc) > rank {
+			v = s.value(s.Positive.Offset + i)
+			break
+		}
+	}
+	return min(max(v, s.Min), s.Max)
+}
+
+// Mean возвращает среднее значение наблюдений.
+func (s *SummaryValue) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// String возвращает краткое текстовое представление: count=N sum=S.
+func (s *SummaryValue) String() string {
+	return "count=" + strconv.FormatUint(s.Count, 10) + " sum=" + strconv.FormatFloat(s.Sum, 'g', -1, 64)
+}
+
+func (s *SummaryValue) gamma() float64 {
+	return (1 + s.Accuracy) / (1 - s.Accuracy)
+}
+
+// index возвращает индекс бина для положительного значения.
+func (s *SummaryValue) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / math.Log(s.gamma())))
+}
+
+// value возвращает представителя бина: середину (g^(i-1), g^i] в смысле
+// относительной погрешности.
+func (s *SummaryValue) value(i int) float64 {
+	g := s.gamma()
+	return 2 * math.Pow(g, float64(i)) / (g + 1)
+}
+
+// add добавляет n наблюдений в бин index, сохраняя не более
+// MaxSummaryBins бинов: лишние младшие бины схлопываются.
+func (b *SketchBins) add(index int, n uint64) {
+	if len(b.Counts) == 0 {
+		b.Offset = index
+		b.Counts = []uint64{n}
+		return
+	}
+	top := b.Offset + len(b.Counts) - 1
+	switch {
+	case index > top:
+		if lo := index - MaxSummaryBins + 1; lo > b.Offset {
+			b.collapse(lo)
+		}
+		b.Counts = append(b.Counts, make([]uint64, index-(b.Offset+len(b.Counts)-1))...)
+	case index < b.Offset:
+		index = max(index, top-MaxSummaryBins+1)
+		b.Counts = append(make([]uint64, b.Offset-index), b.Counts...)
+		b.Offset = index
+	}
+	b.Counts[index-b.Offset] += n
+}
+
+// collapse переносит все бины ниже lo в бин lo.
+func (b *SketchBins) collapse(lo int) {
+	top := b.Offset + len(b.Counts) - 1
+	if lo > top {
+		var total uint64
+		for _, c := range b.Counts {
+			total += c
+		}
+		b.Offset, b.Counts = lo, []uint64{total}
+		return
+	}
+	var folded uint64
+	for _, c := range b.Counts[:lo-b.Offset] {
+		folded += c
+	}
+	b.Counts = b.Counts[lo-b.Offset:]
+	b.Counts[0] += folded
+	b.Offset = lo
+}
+
+func (b *SketchBins) merge(o SketchBins) {
+	for i, c := range o.Counts {
+		if c > 0 {
+			b.add(o.Offset+i, c)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
+)
+
+// DeleteMetric удаляет серию метрики по ее типу, id и лейблам
+func (h *MetricHandler) DeleteMetric(w http.ResponseWriter, r *http.Request, mType oapiMetric.DeleteMetricParamsType, id oapiMetric.ID, params oapiMetric.DeleteMetricParams) {
+	dto := &GetMetricDTO{
+		ID:     id,
+		Type:   string(mType),
+		Labels: queryLabels(params.Labels),
+	}
+	if err := dto.Validate(); err != nil {
+		if errors.Is(err, domain.ErrMetricNotFound) {
+			handleNotFound(w, err.Error())
+			return
+		}
+		handleBadRequest(w, err.Error())
+		return
+	}
+	if err := h.service.DeleteMetric(r.Context(), dto.ID, dto.Type, dto.Labels); err != nil {
+		if errors.Is(err, domain.ErrMetricNotFound) {
+			handleNotFound(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to delete metric", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	h.cache.Delete(dashboardCacheKey)
+	h.notifyAuditAction(r.RemoteAddr, domain.AuditActionDelete, []string{domain.SeriesID(dto.ID, dto.Labels)})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteMetrics удаляет все серии метрик, id которых подходит под шаблон
+func (h *MetricHandler) DeleteMetrics(w http.ResponseWriter, r *http.Request, params oapiMetric.DeleteMetricsParams) {
+	if params.Pattern == "" {
+		handleBadRequest(w, "empty pattern")
+		return
+	}
+	pattern, err := regexp.Compile(params.Pattern)
+	if err != nil {
+		handleBadRequest(w, fmt.Sprintf("invalid pattern: %s", err))
+		return
+	}
+	var mType string
+	if params.Type != nil {
+		mType = *params.Type
+	}
+	if mType != "" && !domain.IsMetricType(mType) {
+		handleBadRequest(w, domain.ErrInvalidMetricType.Error())
+		return
+	}
+
+	deleted, err := h.service.DeleteMetricsByPattern(r.Context(), pattern, mType)
+	if err != nil {
+		zl.Log.Error("failed to delete metrics by pattern", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+
+	res := DeleteResult{Deleted: len(deleted), Metrics: make([]string, len(deleted))}
+	for i := range deleted {
+		res.Metrics[i] = deleted[i].SeriesID()
+	}
+	slices.Sort(res.Metrics)
+	if len(deleted) > 0 {
+		h.cache.Delete(dashboardCacheKey)
+		h.notifyAuditAction(r.RemoteAddr, domain.AuditActionDelete, res.Metrics)
+	}
+	jsonWithHashValueHandler(w, res, h.key)
+}
+
+// DeleteSilence удаляет подавление уведомлений по идентификатору
+func (h *MetricHandler) DeleteSilence(w http.ResponseWriter, r *http.Request, id string) {
+	if h.silence == nil {
+		handleNotFound(w, domain.ErrSilenceNotFound.Error())
+		return
+	}
+	if err := h.silence.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrSilenceNotFound) {
+			handleNotFound(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to delete silence", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
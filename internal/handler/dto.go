@@ -1,9 +1,16 @@
 package handler
 
 import (
+	"cmp"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/influx"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
 )
 
 // validateMetricType проверяет валидность типа метрики
@@ -11,16 +18,21 @@ func validateMetricType(id, mtype string) error {
 	if id == "" {
 		return domain.ErrMetricNotFound
 	}
-	if mtype != domain.Counter && mtype != domain.Gauge {
+	if !domain.IsMetricType(mtype) {
 		return domain.ErrInvalidMetricType
 	}
 	return nil
 }
 
+// ParamMetric - метрика из параметров пути. Для histogram и summary Value -
+// одно наблюдение, которое попадает в бакеты с границами Buckets или в
+// sketch с точностью Accuracy.
 type ParamMetric struct {
-	ID    string `json:"id"`
-	MType string `json:"type"`
-	Value string `json:"value"`
+	ID       string    `json:"id"`
+	MType    string    `json:"type"`
+	Value    string    `json:"value"`
+	Buckets  []float64 `json:"-"`
+	Accuracy float64   `json:"-"`
 }
 
 func (m *ParamMetric) Validate() (*domain.Metrics, error) {
@@ -46,32 +58,74 @@ func (m *ParamMetric) Validate() (*domain.Metrics, error) {
 			return nil, domain.ErrInvalidMetricValue
 		}
 		metric.Value = &value
+	case domain.Histogram, domain.Summary:
+		value, err := strconv.ParseFloat(m.Value, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, domain.ErrInvalidMetricValue
+		}
+		if m.MType == domain.Histogram {
+			metric.Histogram = domain.NewHistogram(m.Buckets)
+			metric.Histogram.Observe(value)
+		} else {
+			metric.Summary = domain.NewSummary(m.Accuracy)
+			metric.Summary.Observe(value)
+		}
 	default:
 		return nil, domain.ErrInvalidMetricType
 	}
 	return metric, nil
 }
 
+// GetMetricDTO - запрос значения метрики. Quantile допустим только
+// для summary.
 type GetMetricDTO struct {
-	ID   string `json:"id"`
-	Type string `json:"type"`
+	ID       string        `json:"id"`
+	Type     string        `json:"type"`
+	Labels   domain.Labels `json:"labels,omitempty"`
+	Quantile *float64      `json:"-"`
 }
 
 func (gm *GetMetricDTO) Validate() error {
-	return validateMetricType(gm.ID, gm.Type)
+	if err := validateMetricType(gm.ID, gm.Type); err != nil {
+		return err
+	}
+	if q := gm.Quantile; q != nil {
+		if gm.Type != domain.Summary {
+			return fmt.Errorf("%w: quantiles are supported only for summary", domain.ErrInvalidQuantile)
+		}
+		if !(*q >= 0 && *q <= 1) {
+			return fmt.Errorf("%w: %v is not in [0, 1]", domain.ErrInvalidQuantile, *q)
+		}
+	}
+	return gm.Labels.Validate()
+}
+
+// queryLabels возвращает лейблы серии из параметра labels запроса;
+// для отсутствующего или пустого набора - nil.
+func queryLabels(labels *oapiMetric.Labels) domain.Labels {
+	if labels == nil {
+		return nil
+	}
+	return domain.Labels(*labels).Clone()
 }
 
 type BodyMetric struct {
-	ID    string   `json:"id"`
-	MType string   `json:"type"`
-	Delta *int64   `json:"delta,omitempty"`
-	Value *float64 `json:"value,omitempty"`
+	ID        string                 `json:"id"`
+	MType     string                 `json:"type"`
+	Labels    domain.Labels          `json:"labels,omitempty"`
+	Delta     *int64                 `json:"delta,omitempty"`
+	Value     *float64               `json:"value,omitempty"`
+	Histogram *domain.HistogramValue `json:"histogram,omitempty"`
+	Summary   *domain.SummaryValue   `json:"summary,omitempty"`
 }
 
 func (m *BodyMetric) Validate() (*domain.Metrics, error) {
 	if err := validateMetricType(m.ID, m.MType); err != nil {
 		return nil, err
 	}
+	if err := m.Labels.Validate(); err != nil {
+		return nil, err
+	}
 
 	// Проверяем, что для каждого типа метрики установлено правильное поле
 	switch m.MType {
@@ -83,12 +137,224 @@ func (m *BodyMetric) Validate() (*domain.Metrics, error) {
 		if m.Value == nil {
 			return nil, domain.ErrMissingMetricValue
 		}
+	case domain.Histogram:
+		if m.Histogram == nil {
+			return nil, domain.ErrMissingMetricValue
+		}
+		if err := m.Histogram.Validate(); err != nil {
+			return nil, err
+		}
+	case domain.Summary:
+		if m.Summary == nil {
+			return nil, domain.ErrMissingMetricValue
+		}
+		if err := m.Summary.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &domain.Metrics{
-		ID:    m.ID,
-		MType: m.MType,
-		Value: m.Value,
-		Delta: m.Delta,
+		ID:        m.ID,
+		MType:     m.MType,
+		Labels:    m.Labels.Clone(),
+		Value:     m.Value,
+		Delta:     m.Delta,
+		Histogram: m.Histogram.Clone(),
+		Summary:   m.Summary.Clone(),
 	}, nil
 }
+
+// SilenceDTO - тело запроса на создание подавления уведомлений.
+type SilenceDTO struct {
+	MetricID string    `json:"metric_id"`
+	IsRegex  bool      `json:"is_regex"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Comment  string    `json:"comment"`
+}
+
+func (s *SilenceDTO) ToDomain() *domain.Silence {
+	return &domain.Silence{
+		MetricID: s.MetricID,
+		IsRegex:  s.IsRegex,
+		StartsAt: s.StartsAt,
+		EndsAt:   s.EndsAt,
+		Comment:  s.Comment,
+	}
+}
+
+// DeleteResult - ответ на удаление метрик по шаблону.
+type DeleteResult struct {
+	Deleted int      `json:"deleted"`
+	Metrics []string `json:"metrics"`
+}
+
+// MetadataDTO - тело запроса на изменение описания метрики.
+type MetadataDTO struct {
+	Description string `json:"description"`
+	Unit        string `json:"unit"`
+	Owner       string `json:"owner"`
+}
+
+func (m *MetadataDTO) ToDomain(id string) *domain.MetricMetadata {
+	return &domain.MetricMetadata{
+		ID:          id,
+		Description: m.Description,
+		Unit:        m.Unit,
+		Owner:       m.Owner,
+	}
+}
+
+// MetricView - строка дашборда: метрика, ее описание и время с
+// последнего обновления.
+type MetricView struct {
+	domain.Metrics
+	Meta  domain.MetricMetadata // Описание метрики; пустое, если не задано
+	Age   time.Duration         // Время с последнего обновления
+	Stale bool                  // Метрика не обновлялась дольше порога устаревания
+}
+
+// newMetricViews строит строки дашборда на момент now; metadata - описания
+// метрик по id.
+func newMetricViews(metrics []domain.Metrics, metadata map[string]domain.MetricMetadata, staleAfter time.Duration, now time.Time) []MetricView {
+	views := make([]MetricView, len(metrics))
+	for i, m := range metrics {
+		views[i] = MetricView{Metrics: m, Meta: metadata[m.ID]}
+		if m.UpdatedAt.IsZero() {
+			continue
+		}
+		views[i].Age = now.Sub(m.UpdatedAt).Truncate(time.Second)
+		views[i].Stale = staleAfter > 0 && views[i].Age > staleAfter
+	}
+	return views
+}
+
+// RangeQueryDTO - параметры запроса истории метрики.
+// start и end - unix-время в секундах или RFC 3339, step - длительность или число секунд.
+type RangeQueryDTO struct {
+	ID    string
+	MType string
+	Start string
+	End   string
+	Step  string
+}
+
+func (q *RangeQueryDTO) ToDomain() (*domain.RangeQuery, error) {
+	start, err := parseTime(q.Start)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start: %w", domain.ErrInvalidRangeQuery, err)
+	}
+	end, err := parseTime(q.End)
+	if err != nil {
+		return nil, fmt.Errorf("%w: end: %w", domain.ErrInvalidRangeQuery, err)
+	}
+	step, err := parseStep(q.Step)
+	if err != nil {
+		return nil, fmt.Errorf("%w: step: %w", domain.ErrInvalidRangeQuery, err)
+	}
+	return &domain.RangeQuery{ID: q.ID, MType: q.MType, Start: start, End: end, Step: step}, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.UnixMicro(int64(sec * 1e6)), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func parseStep(s string) (time.Duration, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(sec * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// RangeResponse - ответ на запрос истории в формате Grafana JSON datasource.
+type RangeResponse struct {
+	Target     string       `json:"target"`
+	Type       string       `json:"type"`
+	Datapoints [][2]float64 `json:"datapoints"` // [значение, unix-время в миллисекундах]
+}
+
+func newRangeResponse(q *domain.RangeQuery, points []domain.Sample) RangeResponse {
+	resp := RangeResponse{Target: q.ID, Type: q.MType, Datapoints: make([][2]float64, len(points))}
+	for i, p := range points {
+		resp.Datapoints[i] = [2]float64{p.Value, float64(p.TS.UnixMilli())}
+	}
+	return resp
+}
+
+// ListQueryDTO - параметры запроса списка метрик; пустые поля - значения
+// по умолчанию.
+type ListQueryDTO struct {
+	Type   string
+	Prefix string
+	Match  string
+	Sort   string
+	Limit  *int
+	Cursor string
+}
+
+func (q *ListQueryDTO) ToDomain() (*domain.MetricQuery, error) {
+	res := &domain.MetricQuery{
+		Type:   q.Type,
+		Prefix: q.Prefix,
+		Match:  q.Match,
+		SortBy: cmp.Or(q.Sort, domain.SortByID),
+		Limit:  domain.DefaultListLimit,
+	}
+	if q.Limit != nil {
+		res.Limit = *q.Limit
+	}
+	if field, ok := strings.CutPrefix(res.SortBy, "-"); ok {
+		res.SortBy, res.Desc = field, true
+	}
+	if q.Cursor != "" {
+		after, err := domain.DecodeMetricCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		res.After = after
+	}
+	if err := res.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// deref возвращает значение необязательного параметра или нулевое значение.
+func deref[T any](p *T) T {
+	var v T
+	if p != nil {
+		v = *p
+	}
+	return v
+}
+
+// ListResponse - страница списка метрик.
+type ListResponse struct {
+	Metrics    []domain.Metrics `json:"metrics"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// Статусы метрики в ответе на частичную запись батча.
+const (
+	BatchItemOK    = "ok"
+	BatchItemError = "error"
+)
+
+// BatchItemResult - результат обработки одной метрики батча при partial=true.
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PartialWriteResponse - ответ на запись line protocol, в которой часть строк отклонена.
+type PartialWriteResponse struct {
+	Code    int                `json:"code"`
+	Message string             `json:"message"`
+	Errors  []influx.LineError `json:"errors"`
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
 	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
 	"github.com/bigsm0uk/metrics-alert-server/internal/service"
+	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
 )
 
 // ExampleMetricHandler_UpdateOrCreateMetricByBody демонстрирует обновление одной метрики через body.
@@ -34,7 +35,7 @@ func ExampleMetricHandler_UpdateOrCreateMetricByBody() {
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
-	h.UpdateOrCreateMetricByBody(rr, req)
+	h.UpdateOrCreateMetricByBody(rr, req, oapiMetric.UpdateOrCreateMetricByBodyParams{})
 
 	// Выведем код ответа и часть тела
 	out := rr.Body.String()
@@ -63,7 +64,7 @@ func ExampleMetricHandler_UpdateOrCreateMetricsBatch() {
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
 
-	h.UpdateOrCreateMetricsBatch(rr, req)
+	h.UpdateOrCreateMetricsBatch(rr, req, oapiMetric.UpdateOrCreateMetricsBatchParams{})
 
 	fmt.Println(rr.Code)
 	// Output:
@@ -2,14 +2,20 @@ package handler
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/goccy/go-json"
 	"go.uber.org/zap"
 
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/cache"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/exposition"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/service"
 	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
 )
 
@@ -50,7 +56,7 @@ func (h *MetricHandler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
 func (h *MetricHandler) GetAllMetrics(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	value, found := h.cache.Get("all_metrics")
+	value, found := h.cache.Get(dashboardCacheKey)
 	if found {
 		w.Write(value.([]byte))
 		return
@@ -67,23 +73,73 @@ func (h *MetricHandler) GetAllMetrics(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	var buf bytes.Buffer
-	if err := h.tmpl.Execute(&buf, m); err != nil {
+	if err := h.tmpl.Execute(&buf, newMetricViews(m, h.metadataIndex(ctx), h.stale, time.Now())); err != nil {
 		zl.Log.Error("failed to execute template", zap.Error(err))
 		handleInternal(w)
 		return
 	}
 
-	h.cache.Set("all_metrics", buf.Bytes(), cache.DefaultExpiration)
+	h.cache.Set(dashboardCacheKey, buf.Bytes(), cache.DefaultExpiration)
 	w.Write(buf.Bytes())
 }
 
-// GetValueByParam возвращает значение метрики по ее типу и id
-func (h *MetricHandler) GetValueByParam(w http.ResponseWriter, r *http.Request, mType oapiMetric.GetValueByParamParamsType, id oapiMetric.ID) {
+// GetPrometheusMetrics отдает метрики в текстовом формате экспозиции Prometheus
+func (h *MetricHandler) GetPrometheusMetrics(w http.ResponseWriter, r *http.Request, params oapiMetric.GetPrometheusMetricsParams) {
+	ctx := r.Context()
+
+	var (
+		m   []domain.Metrics
+		err error
+	)
+	if params.Type != nil && *params.Type != "" {
+		if !domain.IsMetricType(*params.Type) {
+			handleBadRequest(w, domain.ErrInvalidMetricType.Error())
+			return
+		}
+		m, err = h.service.GetMetricsByType(ctx, *params.Type)
+	} else {
+		m, err = h.service.GetAllMetrics(ctx)
+	}
+	if err != nil {
+		zl.Log.Error("failed to get metrics for exposition", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := exposition.WritePrometheus(&buf, m, h.metadataIndex(ctx)); err != nil {
+		zl.Log.Error("failed to write prometheus exposition", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	w.Header().Set("Content-Type", exposition.PrometheusContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// metadataIndex возвращает описания метрик по id. Недоступность реестра
+// не мешает отдать сами метрики, поэтому ошибка только логируется.
+func (h *MetricHandler) metadataIndex(ctx context.Context) map[string]domain.MetricMetadata {
+	if h.meta == nil {
+		return nil
+	}
+	index, err := h.meta.Index(ctx)
+	if err != nil {
+		zl.Log.Warn("failed to load metric metadata", zap.Error(err))
+		return nil
+	}
+	return index
+}
+
+// GetValueByParam возвращает значение метрики по ее типу и id;
+// для summary с параметром q - оценку квантиля
+func (h *MetricHandler) GetValueByParam(w http.ResponseWriter, r *http.Request, mType oapiMetric.GetValueByParamParamsType, id oapiMetric.ID, params oapiMetric.GetValueByParamParams) {
 	ctx := r.Context()
 
 	dto := &GetMetricDTO{
-		ID:   id,
-		Type: string(mType),
+		ID:       id,
+		Type:     string(mType),
+		Quantile: params.Q,
 	}
 	if err := dto.Validate(); err != nil {
 		if err == domain.ErrMetricNotFound {
@@ -94,7 +150,7 @@ func (h *MetricHandler) GetValueByParam(w http.ResponseWriter, r *http.Request,
 			return
 		}
 	}
-	m, err := h.service.GetMetric(ctx, dto.ID, dto.Type)
+	m, err := h.service.GetMetric(ctx, dto.ID, dto.Type, nil)
 	if err != nil {
 		handleNotFound(w, err.Error())
 		return
@@ -106,6 +162,173 @@ func (h *MetricHandler) GetValueByParam(w http.ResponseWriter, r *http.Request,
 		value = fmt.Sprintf("%g", *m.Value)
 	} else if m.MType == domain.Counter && m.Delta != nil {
 		value = fmt.Sprintf("%d", *m.Delta)
+	} else if m.MType == domain.Histogram && m.Histogram != nil {
+		value = m.Histogram.String()
+	} else if m.MType == domain.Summary && m.Summary != nil {
+		if dto.Quantile != nil {
+			value = fmt.Sprintf("%g", m.Summary.Quantile(*dto.Quantile))
+		} else {
+			value = m.Summary.String()
+		}
 	}
 	w.Write([]byte(value))
 }
+
+// ListMetrics возвращает страницу списка метрик в JSON с фильтрацией и сортировкой
+func (h *MetricHandler) ListMetrics(w http.ResponseWriter, r *http.Request, params oapiMetric.ListMetricsParams) {
+	dto := &ListQueryDTO{
+		Type:   deref(params.Type),
+		Prefix: deref(params.Prefix),
+		Match:  deref(params.Match),
+		Sort:   deref(params.Sort),
+		Limit:  params.Limit,
+		Cursor: deref(params.Cursor),
+	}
+	q, err := dto.ToDomain()
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	metrics, next, err := h.service.ListMetrics(r.Context(), *q)
+	if err != nil {
+		zl.Log.Error("failed to list metrics", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	jsonWithHashValueHandler(w, ListResponse{Metrics: metrics, NextCursor: next}, h.key)
+}
+
+// StreamMetrics отправляет принятые обновления метрик как Server-Sent Events.
+// Соединение открыто до отключения клиента, остановки сервера или
+// переполнения буфера подписчика.
+func (h *MetricHandler) StreamMetrics(w http.ResponseWriter, r *http.Request, params oapiMetric.StreamMetricsParams) {
+	if h.stream == nil {
+		handleNotFound(w, "metric stream is not configured")
+		return
+	}
+	filter := service.StreamFilter{ID: deref(params.Id), Type: deref(params.Type)}
+	if filter.Type != "" && !domain.IsMetricType(filter.Type) {
+		handleBadRequest(w, domain.ErrInvalidMetricType.Error())
+		return
+	}
+
+	sub := h.stream.Subscribe(filter)
+	defer h.stream.Unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Интервал переподключения EventSource после разрыва, мс
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		zl.Log.Error("streaming is not supported by response writer", zap.Error(err))
+		return
+	}
+
+	var heartbeat <-chan time.Time
+	if d := h.stream.Heartbeat(); d > 0 {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat:
+			fmt.Fprint(w, ": ping\n\n")
+		case m, ok := <-sub.Updates:
+			if !ok {
+				if sub.Dropped() {
+					fmt.Fprint(w, "event: dropped\ndata: {}\n\n")
+					_ = rc.Flush()
+				}
+				return
+			}
+			data, err := json.Marshal(m)
+			if err != nil {
+				zl.Log.Error("failed to marshal stream update", zap.Error(err))
+				continue
+			}
+			fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// GetAlerts возвращает алерты в состояниях pending, firing и resolved
+func (h *MetricHandler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts := []domain.Alert{}
+	if h.alerts != nil {
+		alerts = h.alerts.Alerts()
+	}
+	jsonWithHashValueHandler(w, alerts, h.key)
+}
+
+// GetSilences возвращает все подавления уведомлений
+func (h *MetricHandler) GetSilences(w http.ResponseWriter, r *http.Request) {
+	silences := []domain.Silence{}
+	if h.silence != nil {
+		var err error
+		silences, err = h.silence.List(r.Context())
+		if err != nil {
+			zl.Log.Error("failed to list silences", zap.Error(err))
+			handleInternal(w)
+			return
+		}
+	}
+	jsonWithHashValueHandler(w, silences, h.key)
+}
+
+// GetMetadata возвращает описания всех метрик
+func (h *MetricHandler) GetMetadata(w http.ResponseWriter, r *http.Request) {
+	metadata := []domain.MetricMetadata{}
+	if h.meta != nil {
+		var err error
+		metadata, err = h.meta.List(r.Context())
+		if err != nil {
+			zl.Log.Error("failed to list metadata", zap.Error(err))
+			handleInternal(w)
+			return
+		}
+	}
+	jsonWithHashValueHandler(w, metadata, h.key)
+}
+
+// GetQueryRange возвращает значения метрики за интервал, выровненные по шагу
+func (h *MetricHandler) GetQueryRange(w http.ResponseWriter, r *http.Request, params oapiMetric.GetQueryRangeParams) {
+	if h.history == nil {
+		handleNotFound(w, "metric history is not configured")
+		return
+	}
+
+	dto := &RangeQueryDTO{
+		ID:    params.Id,
+		MType: params.Type,
+		Start: params.Start,
+		End:   params.End,
+		Step:  params.Step,
+	}
+	q, err := dto.ToDomain()
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	points, err := h.history.QueryRange(r.Context(), *q)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRangeQuery) {
+			handleBadRequest(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to query range", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	jsonWithHashValueHandler(w, newRangeResponse(q, points), h.key)
+}
@@ -0,0 +1,112 @@
+package grpchandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	metricpb "github.com/bigsm0uk/metrics-alert-server/pkg/proto/metric"
+)
+
+// grpcContentType - тип содержимого сохраненного ответа gRPC-метода.
+const grpcContentType = "application/grpc+proto"
+
+// idempotent выполняет next и запоминает успешный ответ под ключом из
+// метаданных idempotency-key; повтор запроса с тем же ключом получает
+// сохраненный ответ без повторного выполнения next. Ошибка не запоминается,
+// и ключ освобождается. Без ключа или без сервиса идемпотентности next
+// выполняется как обычно.
+func (s *MetricServer) idempotent(ctx context.Context, method string, req *metricpb.UpdateMetricsRequest, next func() (*metricpb.UpdateMetricsResponse, error)) (*metricpb.UpdateMetricsResponse, error) {
+	key, ok := idempotencyKey(ctx)
+	if s.idem == nil || !ok {
+		return next()
+	}
+
+	fingerprint, err := requestFingerprint(method, req)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	record, err := s.idem.Begin(ctx, key, fingerprint)
+	switch {
+	case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyInUse):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyStoreFull):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		zl.Log.Error("failed to reserve idempotency key", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to reserve idempotency key")
+	case record != nil:
+		resp := &metricpb.UpdateMetricsResponse{}
+		if err := proto.Unmarshal(record.Body, resp); err != nil {
+			zl.Log.Error("failed to decode idempotent response", zap.String("key", key), zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to decode saved response")
+		}
+		return resp, nil
+	}
+
+	resp, err := next()
+
+	// Ответ не должен теряться из-за отмены запроса клиентом
+	saveCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		s.idem.Abort(saveCtx, key)
+		return nil, err
+	}
+	body, err := proto.Marshal(resp)
+	if err == nil {
+		// Статус записи - HTTP-код, как у ответов HTTP API: 0 означает,
+		// что запрос еще выполняется
+		err = s.idem.Complete(saveCtx, &domain.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      http.StatusOK,
+			ContentType: grpcContentType,
+			Body:        body,
+		})
+	}
+	if err != nil {
+		zl.Log.Error("failed to save idempotent response", zap.String("key", key), zap.Error(err))
+		s.idem.Abort(saveCtx, key)
+	}
+	return resp, nil
+}
+
+// idempotencyKey возвращает ключ идемпотентности из входящих метаданных.
+func idempotencyKey(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(metricpb.IdempotencyKeyMetadataKey)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// requestFingerprint - хеш метода и детерминированной сериализации запроса,
+// по которому повтор отличается от другого запроса с тем же ключом.
+func requestFingerprint(method string, req proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(method + "\n"))
+	sum.Write(b)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,119 @@
+package grpchandler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	metricpb "github.com/bigsm0uk/metrics-alert-server/pkg/proto/metric"
+)
+
+// verifiable - запрос, несущий собственный хеш (UpdateMetricsRequest).
+type verifiable interface {
+	Verify(key string) (bool, error)
+}
+
+func verify(msg any, key string) error {
+	v, ok := msg.(verifiable)
+	if !ok {
+		return nil
+	}
+	valid, err := v.Verify(key)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !valid {
+		zl.Log.Warn("Hash validation failed")
+		return status.Error(codes.InvalidArgument, "Hash validation failed")
+	}
+	return nil
+}
+
+// responseHeader возвращает метаданные с хешем ответа.
+func responseHeader(msg any, key string) (metadata.MD, bool) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, false
+	}
+	h, err := metricpb.MessageHash(m, key)
+	if err != nil {
+		zl.Log.Error("failed to hash response", zap.Error(err))
+		return nil, false
+	}
+	return metadata.Pairs(metricpb.HashMetadataKey, h), true
+}
+
+// HashUnaryInterceptor повторяет middleware WithHashValidation: при заданном
+// ключе проверяет хеш запроса (если он передан) и подписывает ответ
+// метаданными hashsha256.
+func HashUnaryInterceptor(key string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if key == "" {
+			return handler(ctx, req)
+		}
+		if err := verify(req, key); err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if md, ok := responseHeader(resp, key); ok {
+			if err := grpc.SetHeader(ctx, md); err != nil {
+				zl.Log.Error("failed to set hash header", zap.Error(err))
+			}
+		}
+		return resp, nil
+	}
+}
+
+// HashStreamInterceptor проверяет хеш каждого сообщения потока
+// и подписывает ответ так же, как HashUnaryInterceptor.
+func HashStreamInterceptor(key string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if key == "" {
+			return handler(srv, ss)
+		}
+		return handler(srv, &hashStream{ServerStream: ss, key: key})
+	}
+}
+
+type hashStream struct {
+	grpc.ServerStream
+	key string
+}
+
+func (s *hashStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return verify(m, s.key)
+}
+
+func (s *hashStream) SendMsg(m any) error {
+	if md, ok := responseHeader(m, s.key); ok {
+		if err := s.SetHeader(md); err != nil {
+			zl.Log.Error("failed to set hash header", zap.Error(err))
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// LoggerUnaryInterceptor логирует вызовы аналогично LoggerMiddleware.
+func LoggerUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	zl.Log.Info("grpc request",
+		zap.String("method", info.FullMethod),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return resp, err
+}
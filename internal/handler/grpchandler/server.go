@@ -0,0 +1,226 @@
+// Package grpchandler реализует gRPC API сервиса метрик поверх MetricService.
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // Регистрирует компрессор gzip для входящих запросов
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/handler"
+	"github.com/bigsm0uk/metrics-alert-server/internal/service"
+	metricpb "github.com/bigsm0uk/metrics-alert-server/pkg/proto/metric"
+)
+
+// MetricServer обслуживает gRPC-сервис Metrics. Валидация, сохранение
+// и уведомления аудита совпадают с HTTP-обработчиками.
+type MetricServer struct {
+	metricpb.UnimplementedMetricsServer
+	service *service.MetricService
+	as      *service.AuditService
+	idem    *service.IdempotencyService
+}
+
+// MetricServerOption настраивает необязательные зависимости MetricServer.
+type MetricServerOption func(*MetricServer)
+
+// WithIdempotencyService включает обработку ключа идемпотентности из
+// метаданных idempotency-key для UpdateMetrics.
+func WithIdempotencyService(idem *service.IdempotencyService) MetricServerOption {
+	return func(s *MetricServer) {
+		s.idem = idem
+	}
+}
+
+// NewMetricServer создает обработчик gRPC-сервиса метрик.
+func NewMetricServer(service *service.MetricService, as *service.AuditService, opts ...MetricServerOption) *MetricServer {
+	s := &MetricServer{service: service, as: as}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewServer создает gRPC-сервер с зарегистрированным сервисом Metrics.
+// key - секрет для проверки и подписи сообщений (см. HashUnaryInterceptor).
+func NewServer(ms *MetricServer, key string) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(LoggerUnaryInterceptor, HashUnaryInterceptor(key)),
+		grpc.ChainStreamInterceptor(HashStreamInterceptor(key)),
+	)
+	metricpb.RegisterMetricsServer(s, ms)
+	return s
+}
+
+// UpdateMetrics сохраняет батч метрик. Повтор запроса с тем же ключом
+// идемпотентности получает сохраненный ответ, и батч не применяется дважды.
+func (s *MetricServer) UpdateMetrics(ctx context.Context, req *metricpb.UpdateMetricsRequest) (*metricpb.UpdateMetricsResponse, error) {
+	return s.idempotent(ctx, metricpb.Metrics_UpdateMetrics_FullMethodName, req, func() (*metricpb.UpdateMetricsResponse, error) {
+		metrics, err := s.save(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return &metricpb.UpdateMetricsResponse{Metrics: toProtoPtrs(metrics)}, nil
+	})
+}
+
+// UpdateMetricsStream сохраняет батчи потока по мере получения
+func (s *MetricServer) UpdateMetricsStream(stream grpc.ClientStreamingServer[metricpb.UpdateMetricsRequest, metricpb.UpdateMetricsStreamResponse]) error {
+	var updated int64
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&metricpb.UpdateMetricsStreamResponse{Updated: updated})
+		}
+		if err != nil {
+			return err
+		}
+		metrics, err := s.save(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		updated += int64(len(metrics))
+	}
+}
+
+// GetMetric возвращает метрику по id, типу и лейблам
+func (s *MetricServer) GetMetric(ctx context.Context, req *metricpb.GetMetricRequest) (*metricpb.Metric, error) {
+	dto := handler.GetMetricDTO{ID: req.GetId(), Type: req.GetType(), Labels: req.GetLabels()}
+	if err := dto.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	m, err := s.service.GetMetric(ctx, dto.ID, dto.Type, dto.Labels.Clone())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProto(m), nil
+}
+
+// ListMetrics возвращает все метрики или метрики указанного типа
+func (s *MetricServer) ListMetrics(ctx context.Context, req *metricpb.ListMetricsRequest) (*metricpb.ListMetricsResponse, error) {
+	var (
+		metrics []domain.Metrics
+		err     error
+	)
+	switch req.GetType() {
+	case "":
+		metrics, err = s.service.GetAllMetrics(ctx)
+	case domain.Gauge, domain.Counter, domain.Histogram, domain.Summary:
+		metrics, err = s.service.GetMetricsByType(ctx, req.GetType())
+	default:
+		return nil, status.Error(codes.InvalidArgument, domain.ErrInvalidMetricType.Error())
+	}
+	if err != nil {
+		zl.Log.Error("failed to list metrics", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list metrics")
+	}
+
+	resp := &metricpb.ListMetricsResponse{Metrics: make([]*metricpb.Metric, len(metrics))}
+	for i := range metrics {
+		resp.Metrics[i] = toProto(&metrics[i])
+	}
+	return resp, nil
+}
+
+// save валидирует и сохраняет батч, после чего уведомляет аудит.
+func (s *MetricServer) save(ctx context.Context, req *metricpb.UpdateMetricsRequest) ([]*domain.Metrics, error) {
+	metrics := make([]*domain.Metrics, len(req.GetMetrics()))
+	for i, pm := range req.GetMetrics() {
+		dto := handler.BodyMetric{
+			ID:        pm.GetId(),
+			MType:     pm.GetType(),
+			Labels:    pm.GetLabels(),
+			Delta:     pm.Delta,
+			Value:     pm.Value,
+			Histogram: fromProtoHistogram(pm.GetHistogram()),
+			Summary:   fromProtoSummary(pm.GetSummary()),
+		}
+		m, err := dto.Validate()
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid metric %s: %s", pm.GetId(), err)
+		}
+		metrics[i] = m
+	}
+	if err := s.service.SaveOrUpdateMetricsBatch(ctx, metrics); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	s.notifyAudit(ctx, metrics)
+	return metrics, nil
+}
+
+func (s *MetricServer) notifyAudit(ctx context.Context, metrics []*domain.Metrics) {
+	if s.as == nil {
+		return
+	}
+	msg := domain.AuditMessage{
+		TS:      time.Now().Unix(),
+		Metrics: make([]string, len(metrics)),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		msg.IPAddr = p.Addr.String()
+	}
+	for i, m := range metrics {
+		msg.Metrics[i] = m.SeriesID()
+	}
+	s.as.NotifyAll(msg)
+}
+
+func toProto(m *domain.Metrics) *metricpb.Metric {
+	pm := &metricpb.Metric{Id: m.ID, Type: m.MType, Labels: m.Labels, Delta: m.Delta, Value: m.Value}
+	if h := m.Histogram; h != nil {
+		pm.Histogram = &metricpb.Histogram{Bounds: h.Bounds, Counts: h.Counts, Count: h.Count, Sum: h.Sum}
+	}
+	if s := m.Summary; s != nil {
+		pm.Summary = &metricpb.Summary{
+			Accuracy: s.Accuracy,
+			Positive: &metricpb.SketchBins{Offset: int32(s.Positive.Offset), Counts: s.Positive.Counts},
+			Negative: &metricpb.SketchBins{Offset: int32(s.Negative.Offset), Counts: s.Negative.Counts},
+			Zero:     s.Zero,
+			Count:    s.Count,
+			Sum:      s.Sum,
+			Min:      s.Min,
+			Max:      s.Max,
+		}
+	}
+	return pm
+}
+
+func fromProtoHistogram(h *metricpb.Histogram) *domain.HistogramValue {
+	if h == nil {
+		return nil
+	}
+	return &domain.HistogramValue{Bounds: h.GetBounds(), Counts: h.GetCounts(), Count: h.GetCount(), Sum: h.GetSum()}
+}
+
+func fromProtoSummary(s *metricpb.Summary) *domain.SummaryValue {
+	if s == nil {
+		return nil
+	}
+	return &domain.SummaryValue{
+		Accuracy: s.GetAccuracy(),
+		Positive: domain.SketchBins{Offset: int(s.GetPositive().GetOffset()), Counts: s.GetPositive().GetCounts()},
+		Negative: domain.SketchBins{Offset: int(s.GetNegative().GetOffset()), Counts: s.GetNegative().GetCounts()},
+		Zero:     s.GetZero(),
+		Count:    s.GetCount(),
+		Sum:      s.GetSum(),
+		Min:      s.GetMin(),
+		Max:      s.GetMax(),
+	}
+}
+
+func toProtoPtrs(metrics []*domain.Metrics) []*metricpb.Metric {
+	out := make([]*metricpb.Metric, len(metrics))
+	for i, m := range metrics {
+		out[i] = toProto(m)
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package grpchandler
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/agent"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/audit"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/idempotency"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+	"github.com/bigsm0uk/metrics-alert-server/internal/service"
+	metricpb "github.com/bigsm0uk/metrics-alert-server/pkg/proto/metric"
+)
+
+const testKey = "secret"
+
+type auditRecorder struct {
+	mu       sync.Mutex
+	messages []domain.AuditMessage
+}
+
+func (r *auditRecorder) GetID() string { return "recorder" }
+
+func (r *auditRecorder) Notify(m domain.AuditMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, m)
+}
+
+func setupServer(t *testing.T) (metricpb.MetricsClient, string, *auditRecorder) {
+	t.Helper()
+	svc := service.NewService(mem.NewMemRepository(storage.NewMemStorage()), nil)
+	as := service.NewAuditService(&audit.AuditConfig{AuditFile: "unused", AuditURL: "unused"}, zap.NewNop())
+	rec := &auditRecorder{}
+	as.Attach(rec)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	idem := service.NewIdempotencyService(mem.NewIdempotencyRepository(0), &idempotency.IdempotencyConfig{}, zap.NewNop())
+	srv := NewServer(NewMetricServer(svc, as, WithIdempotencyService(idem)), testKey)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return metricpb.NewMetricsClient(conn), lis.Addr().String(), rec
+}
+
+func TestMetricServer_UpdateAndGet(t *testing.T) {
+	client, _, rec := setupServer(t)
+	ctx := context.Background()
+
+	req := &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{
+		{Id: "PollCount", Type: domain.Counter, Delta: lo.ToPtr(int64(2))},
+		{Id: "Alloc", Type: domain.Gauge, Value: lo.ToPtr(1.5)},
+	}}
+	require.NoError(t, req.Sign(testKey))
+
+	var header metadata.MD
+	resp, err := client.UpdateMetrics(ctx, req, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Len(t, resp.GetMetrics(), 2)
+	wantHash, err := metricpb.MessageHash(resp, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{wantHash}, header.Get(metricpb.HashMetadataKey))
+
+	rec.mu.Lock()
+	require.Len(t, rec.messages, 1)
+	assert.Equal(t, []string{"PollCount", "Alloc"}, rec.messages[0].Metrics)
+	assert.NotEmpty(t, rec.messages[0].IPAddr)
+	rec.mu.Unlock()
+
+	m, err := client.GetMetric(ctx, &metricpb.GetMetricRequest{Id: "PollCount", Type: domain.Counter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), m.GetDelta())
+
+	_, err = client.GetMetric(ctx, &metricpb.GetMetricRequest{Id: "Unknown", Type: domain.Gauge})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := client.ListMetrics(ctx, &metricpb.ListMetricsRequest{Type: domain.Gauge})
+	require.NoError(t, err)
+	require.Len(t, list.GetMetrics(), 1)
+	assert.Equal(t, "Alloc", list.GetMetrics()[0].GetId())
+
+	_, err = client.ListMetrics(ctx, &metricpb.ListMetricsRequest{Type: "unknown"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMetricServer_InvalidRequests(t *testing.T) {
+	client, _, _ := setupServer(t)
+	ctx := context.Background()
+
+	req := &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{{Id: "Alloc", Type: domain.Gauge, Value: lo.ToPtr(1.0)}}}
+	require.NoError(t, req.Sign("wrong"))
+	_, err := client.UpdateMetrics(ctx, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpdateMetrics(ctx, &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{{Id: "Alloc", Type: domain.Gauge}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMetricServer_UpdateMetricsIdempotent(t *testing.T) {
+	client, _, rec := setupServer(t)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), metricpb.IdempotencyKeyMetadataKey, "report-1")
+
+	req := &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{{Id: "hits", Type: domain.Counter, Delta: lo.ToPtr(int64(2))}}}
+	require.NoError(t, req.Sign(testKey))
+	first, err := client.UpdateMetrics(ctx, req)
+	require.NoError(t, err)
+	replayed, err := client.UpdateMetrics(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.GetMetrics()[0].GetDelta(), replayed.GetMetrics()[0].GetDelta())
+
+	m, err := client.GetMetric(context.Background(), &metricpb.GetMetricRequest{Id: "hits", Type: domain.Counter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), m.GetDelta())
+	rec.mu.Lock()
+	assert.Len(t, rec.messages, 1)
+	rec.mu.Unlock()
+
+	// Тот же ключ с другим батчем
+	other := &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{{Id: "hits", Type: domain.Counter, Delta: lo.ToPtr(int64(5))}}}
+	require.NoError(t, other.Sign(testKey))
+	_, err = client.UpdateMetrics(ctx, other)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestMetricServer_UpdateMetricsStream(t *testing.T) {
+	client, _, _ := setupServer(t)
+	ctx := context.Background()
+
+	stream, err := client.UpdateMetricsStream(ctx)
+	require.NoError(t, err)
+	for range 3 {
+		req := &metricpb.UpdateMetricsRequest{Metrics: []*metricpb.Metric{{Id: "hits", Type: domain.Counter, Delta: lo.ToPtr(int64(1))}}}
+		require.NoError(t, req.Sign(testKey))
+		require.NoError(t, stream.Send(req))
+	}
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetUpdated())
+
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.NotEmpty(t, header.Get(metricpb.HashMetadataKey))
+
+	m, err := client.GetMetric(ctx, &metricpb.GetMetricRequest{Id: "hits", Type: domain.Counter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), m.GetDelta())
+}
+
+func TestGRPCSender(t *testing.T) {
+	client, addr, _ := setupServer(t)
+
+	sender, err := agent.NewGRPCSender(addr)
+	require.NoError(t, err)
+	defer sender.Close()
+
+	require.NoError(t, sender.SendMetricsV2([]domain.Metrics{
+		{ID: "RandomValue", MType: domain.Gauge, Value: lo.ToPtr(0.5)},
+	}, testKey, ""))
+
+	m, err := client.GetMetric(context.Background(), &metricpb.GetMetricRequest{Id: "RandomValue", Type: domain.Gauge})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, m.GetValue())
+
+	// Повтор отчета с тем же ключом идемпотентности не применяет приращение дважды
+	batch := []domain.Metrics{{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(3))}}
+	require.NoError(t, sender.SendMetricsV2(batch, testKey, "report-1"))
+	require.NoError(t, sender.SendMetricsV2(batch, testKey, "report-1"))
+
+	m, err = client.GetMetric(context.Background(), &metricpb.GetMetricRequest{Id: "PollCount", Type: domain.Counter})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), m.GetDelta())
+}
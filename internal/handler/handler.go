@@ -3,12 +3,17 @@ package handler
 import (
 	"html/template"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/coder/websocket"
 	"github.com/goccy/go-json"
 	"go.uber.org/zap"
 
 	"github.com/bigsm0uk/metrics-alert-server/api/templates"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/otlp"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
 	"github.com/bigsm0uk/metrics-alert-server/internal/service"
 	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
@@ -25,22 +30,122 @@ type MetricHandler struct {
 	key     string
 	as      *service.AuditService
 	cache   interfaces.MetricsCache
+	alerts  *service.AlertService
+	silence *service.SilenceService
+	meta    *service.MetadataService
+	history *service.HistoryService
+	stream  *service.StreamService
+	idem    *service.IdempotencyService
+	stale   time.Duration
+	otlp    *otlp.Converter
+	buckets []float64
+	acc     float64
+	wsMu    sync.Mutex
+	wsConns map[*websocket.Conn]struct{}
+}
+
+// dashboardCacheKey - ключ кеша с отрисованным HTML дашборда.
+const dashboardCacheKey = "all_metrics"
+
+// HandlerOption задает необязательные зависимости обработчика.
+type HandlerOption func(*MetricHandler)
+
+// WithAlertService подключает сервис алертинга для эндпоинта /api/alerts.
+func WithAlertService(alerts *service.AlertService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.alerts = alerts
+	}
+}
+
+// WithSilenceService подключает сервис подавлений для эндпоинтов /api/silences.
+func WithSilenceService(silences *service.SilenceService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.silence = silences
+	}
+}
+
+// WithMetadataService подключает реестр описаний метрик для эндпоинтов
+// /api/metadata, дашборда и HELP экспозиции Prometheus.
+func WithMetadataService(metadata *service.MetadataService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.meta = metadata
+	}
+}
+
+// WithHistoryService подключает сервис истории для эндпоинта /api/v1/query_range.
+func WithHistoryService(history *service.HistoryService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.history = history
+	}
+}
+
+// WithStreamService подключает поток обновлений для эндпоинта /api/stream
+// и живого обновления дашборда.
+func WithStreamService(stream *service.StreamService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.stream = stream
+	}
+}
+
+// WithIdempotencyService подключает хранилище ключей идемпотентности для
+// заголовка Idempotency-Key эндпоинтов /update и /updates.
+func WithIdempotencyService(idem *service.IdempotencyService) HandlerOption {
+	return func(h *MetricHandler) {
+		h.idem = idem
+	}
+}
+
+// WithStaleAfter задает порог, после которого метрика без обновлений
+// отмечается на дашборде как устаревшая. Ноль отключает отметку.
+func WithStaleAfter(d time.Duration) HandlerOption {
+	return func(h *MetricHandler) {
+		h.stale = d
+	}
+}
+
+// WithHistogramBuckets задает границы бакетов histogram, в которые попадают
+// наблюдения, переданные через /update/histogram/{id}/{value}.
+func WithHistogramBuckets(bounds []float64) HandlerOption {
+	return func(h *MetricHandler) {
+		if len(bounds) > 0 {
+			h.buckets = bounds
+		}
+	}
+}
+
+// WithSummaryAccuracy задает относительную точность sketch, который
+// создается для наблюдений, переданных через /update/summary/{id}/{value}.
+func WithSummaryAccuracy(accuracy float64) HandlerOption {
+	return func(h *MetricHandler) {
+		if accuracy > 0 {
+			h.acc = accuracy
+		}
+	}
 }
 
 // NewMetricHandler конструирует экземпляр обработчика метрик.
 // templatePath — путь к HTML-шаблону; при ошибке используется встроенный дефолтный шаблон.
 // key — секрет для заголовка HashSHA256.
 // as — сервис аудита.
-func NewMetricHandler(service *service.MetricService, templatePath, key string, as *service.AuditService, cache interfaces.MetricsCache) *MetricHandler {
+// opts — необязательные зависимости (сервис алертинга и др.).
+func NewMetricHandler(service *service.MetricService, templatePath, key string, as *service.AuditService, cache interfaces.MetricsCache, opts ...HandlerOption) *MetricHandler {
 	tmpl := initializeTemplate(templatePath)
 
-	return &MetricHandler{
+	h := &MetricHandler{
 		service: service,
 		tmpl:    tmpl,
 		key:     key,
 		as:      as,
 		cache:   cache,
+		otlp:    otlp.NewConverter(),
+		buckets: domain.DefaultHistogramBuckets,
+		acc:     domain.DefaultSummaryAccuracy,
+		wsConns: map[*websocket.Conn]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 func initializeTemplate(path string) *template.Template {
@@ -72,7 +177,13 @@ func initializeTemplate(path string) *template.Template {
 }
 
 // Close корректно закрывает зависимости обработчика (репозиторий и др.).
+// Открытые потоки /api/stream и WebSocket соединения агентов завершаются,
+// чтобы не задерживать остановку сервера.
 func (h *MetricHandler) Close() error {
+	if h.stream != nil {
+		h.stream.Close()
+	}
+	h.closeConns()
 	return h.service.Close()
 }
 
@@ -131,6 +242,10 @@ func handleNotFound(w http.ResponseWriter, errText string) {
 }
 
 func jsonWithHashValueHandler(w http.ResponseWriter, data any, key string) {
+	jsonWithHashStatusHandler(w, http.StatusOK, data, key)
+}
+
+func jsonWithHashStatusHandler(w http.ResponseWriter, statusCode int, data any, key string) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		zl.Log.Error("failed to marshal response data", zap.Error(err))
@@ -141,7 +256,7 @@ func jsonWithHashValueHandler(w http.ResponseWriter, data any, key string) {
 
 	withHasherValueHandler(w, jsonData, key)
 
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(statusCode)
 
 	if _, err := w.Write(jsonData); err != nil {
 		zl.Log.Error("failed to write response", zap.Error(err))
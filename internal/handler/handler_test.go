@@ -1,25 +1,37 @@
 package handler
 
 import (
+	"bufio"
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-resty/resty/v2"
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/agent"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/cache"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/ws"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/server/store"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 	"github.com/bigsm0uk/metrics-alert-server/internal/repository"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
 	"github.com/bigsm0uk/metrics-alert-server/internal/service"
 	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
+	"github.com/bigsm0uk/metrics-alert-server/pkg/prompb"
 )
 
 func setupTestServer(t *testing.T) (*httptest.Server, *resty.Client) {
@@ -33,10 +45,19 @@ func setupTestServer(t *testing.T) (*httptest.Server, *resty.Client) {
 	ms, err := store.NewJSONStore(r, &cfg.Store)
 	require.NoError(t, err)
 
-	svc := service.NewService(r, ms)
+	stream := service.NewStreamService(&cfg.Stream, zl.Log)
+	svc := service.NewService(r, ms, service.WithStream(stream))
 	as := service.NewAuditService(&cfg.Audit, zl.Log)
 	cache := cache.New(cache.DefaultExpiration, 0)
-	h := NewMetricHandler(svc, cfg.TemplatePath, cfg.Key, as, cache)
+	silenceRepo, err := mem.NewSilenceRepository("")
+	require.NoError(t, err)
+	silences := service.NewSilenceService(silenceRepo, zl.Log)
+	metadataRepo, err := mem.NewMetadataRepository("")
+	require.NoError(t, err)
+	metadata := service.NewMetadataService(metadataRepo, zl.Log)
+	history := service.NewHistoryService(r.(*mem.MemRepository), zl.Log)
+	idem := service.NewIdempotencyService(mem.NewIdempotencyRepository(0), &cfg.Idempotency, zl.Log)
+	h := NewMetricHandler(svc, cfg.TemplatePath, cfg.Key, as, cache, WithSilenceService(silences), WithMetadataService(metadata), WithHistoryService(history), WithStreamService(stream), WithIdempotencyService(idem))
 
 	// Используем сгенерированный OpenAPI роутер
 	router := chi.NewRouter()
@@ -446,3 +467,949 @@ func TestMetricHandler_UpdateMetricsBatch_Integration(t *testing.T) {
 	assert.Equal(t, http.StatusOK, counterResp2.StatusCode())
 	assert.Equal(t, "200", string(counterResp2.Body())) // 150 + 50
 }
+
+func TestMetricHandler_UpdateOrCreateMetricsBatchPartial(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	batch := []map[string]any{
+		{"id": "requests_total", "type": domain.Counter, "delta": int64(5)},
+		{"id": "broken", "type": domain.Gauge},
+		{"id": "cpu", "type": "unknown", "value": 1.0},
+		{"id": "memory", "type": domain.Gauge, "value": 0.5},
+	}
+
+	// Без partial батч отклоняется целиком
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(batch).
+		Post("/updates")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	var results []BatchItemResult
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetQueryParam("partial", "true").
+		SetBody(batch).
+		SetResult(&results).
+		Post("/updates")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Len(t, results, 4)
+	for i, status := range []string{BatchItemOK, BatchItemError, BatchItemError, BatchItemOK} {
+		assert.Equal(t, i, results[i].Index)
+		assert.Equal(t, status, results[i].Status, results[i].ID)
+		assert.Equal(t, status == BatchItemError, results[i].Error != "", results[i].ID)
+	}
+
+	resp, err = client.R().Get("/value/counter/requests_total")
+	require.NoError(t, err)
+	assert.Equal(t, "5", resp.String())
+	resp, err = client.R().Get("/value/gauge/memory")
+	require.NoError(t, err)
+	assert.Equal(t, "0.5", resp.String())
+	resp, err = client.R().Get("/value/gauge/broken")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+}
+
+func TestMetricHandler_IdempotencyKey(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	post := func(path, key string, body any) *resty.Response {
+		t.Helper()
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/json").
+			SetHeader("Idempotency-Key", key).
+			SetBody(body).
+			Post(path)
+		require.NoError(t, err)
+		return resp
+	}
+	batch := []map[string]any{{"id": "PollCount", "type": domain.Counter, "delta": int64(5)}}
+
+	first := post("/updates", "batch-1", batch)
+	require.Equal(t, http.StatusOK, first.StatusCode())
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	// Повтор получает сохраненный ответ, приращение не применяется второй раз
+	replay := post("/updates", "batch-1", batch)
+	require.Equal(t, http.StatusOK, replay.StatusCode())
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.String(), replay.String())
+
+	single := map[string]any{"id": "PollCount", "type": domain.Counter, "delta": int64(2)}
+	require.Equal(t, http.StatusOK, post("/update", "single-1", single).StatusCode())
+	require.Equal(t, http.StatusOK, post("/update", "single-1", single).StatusCode())
+
+	resp, err := client.R().Get("/value/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, "7", resp.String())
+
+	// Ключ нельзя использовать для другого запроса
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/update", "batch-1", single).StatusCode())
+
+	// Неуспешный ответ не запоминается
+	invalid := []map[string]any{{"id": "PollCount", "type": domain.Counter}}
+	assert.Equal(t, http.StatusBadRequest, post("/updates", "batch-2", invalid).StatusCode())
+	assert.Equal(t, http.StatusOK, post("/updates", "batch-2", batch).StatusCode())
+}
+
+func TestMetricHandler_Labels(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	batch := []map[string]any{
+		{"id": "requests", "type": domain.Counter, "delta": int64(1)},
+		{"id": "requests", "type": domain.Counter, "delta": int64(5), "labels": map[string]string{"host": "a"}},
+		{"id": "requests", "type": domain.Counter, "delta": int64(7), "labels": map[string]string{"host": "b"}},
+	}
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(batch).
+		Post("/updates")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	// Метрика без лейблов по-прежнему доступна по старому API.
+	resp, err = client.R().Get("/value/counter/requests")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(resp.Body()))
+
+	var got domain.Metrics
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "requests", "type": domain.Counter, "labels": map[string]string{"host": "a"}}).
+		SetResult(&got).
+		Post("/value")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, int64(5), *got.Delta)
+	assert.Equal(t, domain.Labels{"host": "a"}, got.Labels)
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "requests", "type": domain.Counter, "delta": 1, "labels": map[string]string{"bad name": "x"}}).
+		Post("/update")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().Get("/")
+	require.NoError(t, err)
+	assert.Contains(t, resp.String(), "host=b")
+}
+
+func TestMetricHandler_Histogram(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	// Наблюдения по параметрам попадают в бакеты по умолчанию.
+	for _, v := range []string{"0.003", "0.2", "20"} {
+		resp, err := client.R().Post("/update/histogram/latency/" + v)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	resp, err := client.R().Post("/update/histogram/latency/abc")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().Get("/value/histogram/latency")
+	require.NoError(t, err)
+	assert.Equal(t, "count=3 sum=20.203", resp.String())
+
+	body := map[string]any{
+		"id":   "size",
+		"type": domain.Histogram,
+		"histogram": map[string]any{
+			"bounds": []float64{10, 100},
+			"counts": []uint64{1, 2, 0},
+			"count":  3,
+			"sum":    150,
+		},
+	}
+	for range 2 {
+		resp, err = client.R().
+			SetHeader("Content-Type", "application/json").
+			SetBody(body).
+			Post("/update")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	var got domain.Metrics
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "size", "type": domain.Histogram}).
+		SetResult(&got).
+		Post("/value")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.NotNil(t, got.Histogram)
+	assert.Equal(t, []uint64{2, 4, 0}, got.Histogram.Counts)
+	assert.Equal(t, uint64(6), got.Histogram.Count)
+	assert.Equal(t, 300.0, got.Histogram.Sum)
+
+	// Число наблюдений по бакетам не сходится с count.
+	body["histogram"].(map[string]any)["count"] = 5
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody([]map[string]any{body}).
+		Post("/updates")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().Get("/metrics")
+	require.NoError(t, err)
+	assert.Contains(t, resp.String(), `size_bucket{le="100"} 6`)
+	assert.Contains(t, resp.String(), "latency_count 3")
+}
+
+func TestMetricHandler_Summary(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	// Часть наблюдений приходит по одному, часть - готовым sketch агента.
+	for i := 1; i <= 50; i++ {
+		resp, err := client.R().Post(fmt.Sprintf("/update/summary/rtt/%d", i))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	partial := domain.NewSummary(domain.DefaultSummaryAccuracy)
+	for i := 51; i <= 100; i++ {
+		partial.Observe(float64(i))
+	}
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "rtt", "type": domain.Summary, "summary": partial}).
+		Post("/update")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().Get("/value/summary/rtt")
+	require.NoError(t, err)
+	assert.Equal(t, "count=100 sum=5050", resp.String())
+
+	for q, exact := range map[string]float64{"0.5": 50, "0.99": 99, "1": 100} {
+		resp, err = client.R().SetQueryParam("q", q).Get("/value/summary/rtt")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+		got, err := strconv.ParseFloat(resp.String(), 64)
+		require.NoError(t, err)
+		assert.InEpsilon(t, exact, got, 0.02, "q=%s", q)
+	}
+
+	// Квантиль вне [0, 1] и квантиль не для summary.
+	resp, err = client.R().SetQueryParam("q", "1.5").Get("/value/summary/rtt")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	resp, err = client.R().SetQueryParam("q", "0.5").Get("/value/gauge/rtt")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	// Sketch с неверной точностью отклоняется.
+	partial.Accuracy = 0
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "rtt", "type": domain.Summary, "summary": partial}).
+		Post("/update")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().Get("/metrics")
+	require.NoError(t, err)
+	assert.Contains(t, resp.String(), "# TYPE rtt summary")
+	assert.Contains(t, resp.String(), "rtt_count 100")
+}
+
+func TestMetricHandler_GetAlerts(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.R().Get("/api/alerts")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, "[]", string(resp.Body()))
+}
+
+func TestMetricHandler_Silences(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	var created domain.Silence
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{
+			"metric_id": "Heap.*",
+			"is_regex":  true,
+			"ends_at":   time.Now().Add(time.Hour),
+			"comment":   "deploy",
+		}).
+		SetResult(&created).
+		Post("/api/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "deploy", created.Comment)
+
+	// ends_at раньше starts_at
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"metric_id": "Alloc", "ends_at": time.Now().Add(-time.Hour)}).
+		Post("/api/silences")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	var list []domain.Silence
+	resp, err = client.R().SetResult(&list).Get("/api/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Len(t, list, 1)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	resp, err = client.R().Delete("/api/silences/" + created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().Delete("/api/silences/" + created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+}
+
+func TestMetricHandler_Metadata(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.R().Post("/update/gauge/MCacheSys/16384")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var saved domain.MetricMetadata
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(MetadataDTO{Description: "Память под структуры mcache", Unit: "bytes", Owner: "runtime"}).
+		SetResult(&saved).
+		Put("/api/metadata/MCacheSys")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "MCacheSys", saved.ID)
+	assert.False(t, saved.UpdatedAt.IsZero())
+
+	// Единица измерения должна быть однострочной
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(MetadataDTO{Unit: "bytes\nkb"}).
+		Put("/api/metadata/MCacheSys")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	var list []domain.MetricMetadata
+	resp, err = client.R().SetResult(&list).Get("/api/metadata")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Len(t, list, 1)
+	assert.Equal(t, "runtime", list[0].Owner)
+
+	resp, err = client.R().Get("/metrics")
+	require.NoError(t, err)
+	assert.Contains(t, resp.String(), "# HELP MCacheSys Память под структуры mcache (unit: bytes, owner: runtime)\n")
+
+	resp, err = client.R().Get("/")
+	require.NoError(t, err)
+	assert.Contains(t, resp.String(), "Память под структуры mcache")
+}
+
+func TestMetricHandler_DeleteAndReset(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	for _, path := range []string{
+		"/update/counter/PollCount/5",
+		"/update/gauge/RandomValue/1",
+		"/update/gauge/RandomExtra/2",
+		"/update/gauge/Alloc/3",
+	} {
+		resp, err := client.R().Post(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "RandomValue", "type": domain.Gauge, "labels": map[string]string{"host": "a"}, "value": 4}).
+		Post("/update")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	// Сброс counter заменяет значение нулем, последующие обновления суммируются с нуля
+	var reset domain.Metrics
+	resp, err = client.R().SetResult(&reset).Post("/reset/counter/PollCount")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, int64(0), *reset.Delta)
+	resp, err = client.R().Post("/update/counter/PollCount/2")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	resp, err = client.R().Get("/value/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, "2", resp.String())
+
+	resp, err = client.R().Post("/reset/counter/Unknown")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	// Серия с лейблами сбрасывается по параметру labels, остальные серии не меняются
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "PollCount", "type": domain.Counter, "labels": map[string]string{"host": "a"}, "delta": 7}).
+		Post("/update")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	resp, err = client.R().SetQueryParam("labels[host]", "a").SetResult(&reset).Post("/reset/counter/PollCount")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, domain.Labels{"host": "a"}, reset.Labels)
+	assert.Equal(t, int64(0), *reset.Delta)
+	resp, err = client.R().Get("/value/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, "2", resp.String())
+	resp, err = client.R().SetQueryParam("labels[host]", "b").Post("/reset/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	resp, err = client.R().SetQueryParam("labels[1bad]", "a").Post("/reset/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	// Удаление одной метрики
+	resp, err = client.R().Delete("/metric/gauge/Alloc")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	resp, err = client.R().Get("/value/gauge/Alloc")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	resp, err = client.R().Delete("/metric/gauge/Alloc")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	resp, err = client.R().Delete("/metric/counter/RandomExtra")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode(), "тип метрики должен совпадать")
+
+	// Удаление серии с лейблами не затрагивает серию без лейблов
+	resp, err = client.R().SetQueryParam("labels[host]", "a").Delete("/metric/gauge/RandomValue")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	resp, err = client.R().SetQueryParam("labels[host]", "a").Delete("/metric/gauge/RandomValue")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	resp, err = client.R().Get("/value/gauge/RandomValue")
+	require.NoError(t, err)
+	assert.Equal(t, "1", resp.String())
+
+	// Удаление по шаблону затрагивает все серии
+	var res DeleteResult
+	resp, err = client.R().SetQueryParam("pattern", "^Random").SetResult(&res).Delete("/metric")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, DeleteResult{Deleted: 2, Metrics: []string{"RandomExtra", "RandomValue"}}, res)
+
+	resp, err = client.R().SetQueryParam("pattern", "(").Delete("/metric")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().SetQueryParam("pattern", ".*").SetQueryParam("type", domain.Counter).SetResult(&res).Delete("/metric")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, []string{"PollCount", `PollCount{host="a"}`}, res.Metrics)
+}
+
+func TestMetricHandler_ListMetrics(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	for _, path := range []string{
+		"/update/gauge/HeapInuse/2",
+		"/update/gauge/HeapAlloc/1",
+		"/update/gauge/Alloc/3",
+		"/update/counter/PollCount/5",
+	} {
+		resp, err := client.R().Post(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]any{"id": "HeapInuse", "type": domain.Gauge, "labels": map[string]string{"host": "a"}, "value": 4}).
+		Post("/update")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	list := func(params map[string]string) ([]string, string) {
+		t.Helper()
+		var page ListResponse
+		resp, err := client.R().SetQueryParams(params).SetResult(&page).Get("/api/metrics")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+		ids := make([]string, len(page.Metrics))
+		for i := range page.Metrics {
+			ids[i] = page.Metrics[i].SeriesID()
+		}
+		return ids, page.NextCursor
+	}
+
+	// Постраничный обход по префиксу: серии одной метрики идут подряд
+	ids, next := list(map[string]string{"prefix": "Heap", "limit": "2"})
+	assert.Equal(t, []string{"HeapAlloc", "HeapInuse"}, ids)
+	require.NotEmpty(t, next)
+	ids, last := list(map[string]string{"prefix": "Heap", "limit": "2", "cursor": next})
+	assert.Equal(t, []string{`HeapInuse{host="a"}`}, ids)
+	assert.Empty(t, last)
+
+	ids, _ = list(map[string]string{"type": domain.Counter})
+	assert.Equal(t, []string{"PollCount"}, ids)
+	ids, _ = list(map[string]string{"match": "^(Alloc|PollCount)$", "sort": "-id"})
+	assert.Equal(t, []string{"PollCount", "Alloc"}, ids)
+	ids, _ = list(map[string]string{"sort": "type"})
+	assert.Equal(t, []string{"PollCount", "Alloc", "HeapAlloc", "HeapInuse", `HeapInuse{host="a"}`}, ids)
+
+	for name, params := range map[string]map[string]string{
+		"unknown type":         {"type": "unknown"},
+		"invalid regexp":       {"match": "("},
+		"unicode class":        {"match": `\pL`},
+		"regexp flags":         {"match": "(?i)alloc"},
+		"unknown sort":         {"sort": "value"},
+		"zero limit":           {"limit": "0"},
+		"too large limit":      {"limit": "1001"},
+		"malformed cursor":     {"cursor": "!"},
+		"cursor of other sort": {"cursor": next, "sort": "-id"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := client.R().SetQueryParams(params).Get("/api/metrics")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+		})
+	}
+}
+
+func TestMetricHandler_StreamMetrics(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.R().SetQueryParam("type", "unknown").Get("/api/stream")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/stream?type=gauge", nil)
+	require.NoError(t, err)
+	stream, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	lines := bufio.NewReader(stream.Body)
+	readEvent := func() (string, string) {
+		t.Helper()
+		var event, data string
+		for {
+			line, err := lines.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "" && event != "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+	// Подписка оформлена до первой записи в ответ
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "retry: 3000\n", line)
+
+	for _, path := range []string{"/update/counter/PollCount/1", "/update/gauge/Alloc/1.5"} {
+		resp, err := client.R().Post(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+	event, data := readEvent()
+	assert.Equal(t, "update", event)
+	assert.JSONEq(t, `{"id":"Alloc","type":"gauge","value":1.5}`, data)
+}
+
+func TestMetricHandler_IdempotentEmptyResponse(t *testing.T) {
+	cfg := config.InitDefaultConfig()
+	h := &MetricHandler{idem: service.NewIdempotencyService(mem.NewIdempotencyRepository(0), &cfg.Idempotency, zl.Log)}
+	calls := 0
+	// Обработчик без WriteHeader и Write отвечает 200
+	next := func(http.ResponseWriter, *http.Request) { calls++ }
+	key := "empty-1"
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		h.idempotent(w, httptest.NewRequest(http.MethodPost, "/update", strings.NewReader("{}")), &key, next)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestMetricHandler_IdempotencyStoreFull(t *testing.T) {
+	cfg := config.InitDefaultConfig()
+	h := &MetricHandler{idem: service.NewIdempotencyService(mem.NewIdempotencyRepository(1), &cfg.Idempotency, zl.Log)}
+	// Единственное место занято запросом, который еще выполняется
+	_, err := h.idem.Begin(context.Background(), "in-flight", "fingerprint")
+	require.NoError(t, err)
+
+	calls := 0
+	key := "next"
+	w := httptest.NewRecorder()
+	h.idempotent(w, httptest.NewRequest(http.MethodPost, "/updates", strings.NewReader("[]")), &key, func(http.ResponseWriter, *http.Request) { calls++ })
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Zero(t, calls)
+}
+
+func TestMetricHandler_UpdateMetricsWebSocket(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	// Отправка агентским транспортом: подключение, аутентификация и подтверждение батча
+	sender := agent.NewWSSender(server.URL)
+	defer sender.Close()
+	delta := int64(3)
+	batch := []domain.Metrics{{ID: "PollCount", MType: domain.Counter, Delta: &delta}}
+	require.NoError(t, sender.SendMetricsV2(batch, "", ""))
+	require.NoError(t, sender.SendMetricsV2(batch, "", ""))
+
+	resp, err := client.R().Get("/value/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, "6", resp.String())
+
+	// Повтор батча с тем же ключом идемпотентности подтверждается без применения,
+	// в том числе после переподключения
+	require.NoError(t, sender.SendMetricsV2(batch, "", "ws-report-1"))
+	require.NoError(t, sender.Close())
+	require.NoError(t, sender.SendMetricsV2(batch, "", "ws-report-1"))
+
+	resp, err = client.R().Get("/value/counter/PollCount")
+	require.NoError(t, err)
+	assert.Equal(t, "9", resp.String())
+
+	// Невалидный батч отклоняется, но соединение остается открытым
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, server.URL+ws.Path, nil)
+	require.NoError(t, err)
+	defer conn.CloseNow()
+	var reply ws.Message
+	require.NoError(t, wsjson.Read(ctx, conn, &reply))
+	require.Equal(t, ws.TypeChallenge, reply.Type)
+	assert.NotEmpty(t, reply.Nonce)
+	require.NoError(t, wsjson.Write(ctx, conn, ws.NewAuth("test", "", reply.Nonce, time.Now())))
+	require.NoError(t, wsjson.Read(ctx, conn, &reply))
+	assert.Equal(t, ws.TypeAck, reply.Type)
+
+	require.NoError(t, wsjson.Write(ctx, conn, ws.Message{Type: ws.TypeBatch, Seq: 1, Metrics: []domain.Metrics{{ID: "bad", MType: domain.Gauge}}}))
+	require.NoError(t, wsjson.Read(ctx, conn, &reply))
+	assert.Equal(t, ws.TypeNack, reply.Type)
+	assert.Equal(t, uint64(1), reply.Seq)
+	assert.NotEmpty(t, reply.Error)
+
+	value := 1.5
+	require.NoError(t, wsjson.Write(ctx, conn, ws.Message{Type: ws.TypeBatch, Seq: 2, Metrics: []domain.Metrics{{ID: "Alloc", MType: domain.Gauge, Value: &value}}}))
+	var ack ws.Message
+	require.NoError(t, wsjson.Read(ctx, conn, &ack))
+	assert.Equal(t, ws.Message{Type: ws.TypeAck, Seq: 2, Updated: 1}, ack)
+
+	// Ключ идемпотентности, использованный для другого батча, отклоняется
+	require.NoError(t, wsjson.Write(ctx, conn, ws.Message{Type: ws.TypeBatch, Seq: 3, IdempotencyKey: "ws-report-1", Metrics: []domain.Metrics{{ID: "Alloc", MType: domain.Gauge, Value: &value}}}))
+	require.NoError(t, wsjson.Read(ctx, conn, &reply))
+	assert.Equal(t, ws.TypeNack, reply.Type)
+	assert.False(t, reply.Retry)
+}
+
+func TestNewMetricViews(t *testing.T) {
+	now := time.Now()
+	metrics := []domain.Metrics{
+		{ID: "fresh", MType: domain.Gauge, UpdatedAt: now.Add(-10 * time.Second)},
+		{ID: "stale", MType: domain.Gauge, UpdatedAt: now.Add(-5 * time.Minute)},
+		{ID: "unknown", MType: domain.Gauge},
+	}
+
+	views := newMetricViews(metrics, nil, time.Minute, now)
+	require.Len(t, views, 3)
+	assert.Equal(t, 10*time.Second, views[0].Age)
+	assert.False(t, views[0].Stale)
+	assert.Equal(t, 5*time.Minute, views[1].Age)
+	assert.True(t, views[1].Stale)
+	assert.Zero(t, views[2].Age)
+	assert.False(t, views[2].Stale)
+
+	// Нулевой порог отключает отметку устаревания
+	views = newMetricViews(metrics, nil, 0, now)
+	assert.False(t, views[1].Stale)
+}
+
+func TestMetricHandler_GetQueryRange(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.R().Post("/update/gauge/Alloc/1.5")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	now := time.Now()
+	tests := []struct {
+		name       string
+		params     map[string]string
+		wantStatus int
+		wantPoints int
+	}{
+		{
+			name: "unix seconds",
+			params: map[string]string{
+				"id": "Alloc", "type": domain.Gauge,
+				"start": strconv.FormatInt(now.Add(time.Second).Unix(), 10),
+				"end":   strconv.FormatInt(now.Add(time.Minute+time.Second).Unix(), 10),
+				"step":  "30s",
+			},
+			wantStatus: http.StatusOK,
+			wantPoints: 3,
+		},
+		{
+			name: "rfc3339",
+			params: map[string]string{
+				"id": "Alloc", "type": domain.Gauge,
+				"start": now.Add(time.Second).Format(time.RFC3339),
+				"end":   now.Add(time.Minute).Format(time.RFC3339),
+				"step":  "60",
+			},
+			wantStatus: http.StatusOK,
+			wantPoints: 1,
+		},
+		{
+			name: "unknown metric",
+			params: map[string]string{
+				"id": "Unknown", "type": domain.Gauge,
+				"start": "0", "end": "60", "step": "15s",
+			},
+			wantStatus: http.StatusOK,
+			wantPoints: 0,
+		},
+		{
+			name: "invalid step",
+			params: map[string]string{
+				"id": "Alloc", "type": domain.Gauge,
+				"start": "0", "end": "60", "step": "abc",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "end before start",
+			params: map[string]string{
+				"id": "Alloc", "type": domain.Gauge,
+				"start": "60", "end": "0", "step": "15s",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing params",
+			params:     map[string]string{"id": "Alloc"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result RangeResponse
+			resp, err := client.R().SetQueryParams(tt.params).SetResult(&result).Get("/api/v1/query_range")
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, resp.StatusCode())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			require.Len(t, result.Datapoints, tt.wantPoints)
+			for _, p := range result.Datapoints {
+				assert.Equal(t, 1.5, p[0])
+			}
+		})
+	}
+}
+
+func TestMetricHandler_GetPrometheusMetrics(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	for _, path := range []string{"/update/gauge/cpu.load/0.5", "/update/counter/PollCount/3"} {
+		resp, err := client.R().Post(path)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode())
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		contains   []string
+		excludes   []string
+	}{
+		{
+			name:       "all metrics",
+			wantStatus: http.StatusOK,
+			contains:   []string{"# TYPE cpu_load gauge\ncpu_load 0.5", "# TYPE PollCount_total counter\nPollCount_total 3"},
+		},
+		{
+			name:       "counters only",
+			query:      domain.Counter,
+			wantStatus: http.StatusOK,
+			contains:   []string{"PollCount_total 3"},
+			excludes:   []string{"cpu_load"},
+		},
+		{
+			name:       "invalid type",
+			query:      "unknown",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := client.R()
+			if tt.query != "" {
+				req.SetQueryParam("type", tt.query)
+			}
+			resp, err := req.Get("/metrics")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header().Get("Content-Type"))
+			for _, s := range tt.contains {
+				assert.Contains(t, resp.String(), s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, resp.String(), s)
+			}
+		})
+	}
+}
+
+func TestMetricHandler_RemoteWrite(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	raw, err := proto.Marshal(&prompb.WriteRequest{Timeseries: []*prompb.TimeSeries{
+		{
+			Labels:  []*prompb.Label{{Name: "__name__", Value: "up"}},
+			Samples: []*prompb.Sample{{Value: 1, Timestamp: time.Now().UnixMilli()}},
+		},
+	}})
+	require.NoError(t, err)
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/x-protobuf").
+		SetHeader("Content-Encoding", "snappy").
+		SetBody(snappy.Encode(nil, raw)).
+		Post("/api/v1/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().Get("/value/gauge/up")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "1", resp.String())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/x-protobuf").
+		SetHeader("Content-Encoding", "snappy").
+		SetBody([]byte("garbage")).
+		Post("/api/v1/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/x-protobuf").
+		SetHeader("Content-Encoding", "zstd").
+		SetBody(raw).
+		Post("/api/v1/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestMetricHandler_InfluxWrite(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "text/plain; charset=utf-8").
+		SetBody("mem free=512\nhttp requests=3i\n").
+		Post("/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().Get("/value/gauge/mem_free")
+	require.NoError(t, err)
+	assert.Equal(t, "512", resp.String())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "text/plain").
+		SetQueryParam("precision", "s").
+		SetBody("http requests=2i 1700000000\nbroken\n").
+		Post("/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+	assert.Contains(t, resp.String(), `"line":2`)
+
+	resp, err = client.R().Get("/value/counter/http_requests")
+	require.NoError(t, err)
+	assert.Equal(t, "5", resp.String())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "text/plain").
+		SetQueryParam("precision", "h").
+		SetBody("mem free=1\n").
+		Post("/write")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestMetricHandler_OtlpMetrics(t *testing.T) {
+	server, client := setupTestServer(t)
+	defer server.Close()
+
+	jsonBody := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},` +
+		`"scopeMetrics":[{"metrics":[` +
+		`{"name":"requests","sum":{"aggregationTemporality":2,"isMonotonic":true,"dataPoints":[{"asInt":"7","startTimeUnixNano":"1"}]}},` +
+		`{"name":"latency","histogram":{"dataPoints":[{"count":"1"}]}}` +
+		`]}]}]}`
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(jsonBody).
+		Post("/v1/metrics")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Contains(t, resp.String(), `"rejectedDataPoints":"1"`)
+
+	m, err := client.R().Get(`/value/counter/requests{service.name="api"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "7", m.String())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "application/x-protobuf").
+		SetBody([]byte{0xff, 0xff}).
+		Post("/v1/metrics")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().
+		SetHeader("Content-Type", "text/xml").
+		SetBody("<metrics/>").
+		Post("/v1/metrics")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode())
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// idempotentReplayedHeader отмечает ответ, сохраненный при первом выполнении запроса.
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyRetryAfter - через сколько секунд повторить запрос, если все
+// места в хранилище ключей заняты выполняющимися запросами.
+const idempotencyRetryAfter = 1
+
+// idempotent выполняет next и запоминает успешный ответ под ключом key;
+// повтор запроса с тем же ключом получает сохраненный ответ без повторного
+// выполнения next. Неуспешный ответ не запоминается, и ключ освобождается,
+// чтобы запрос можно было повторить. Без ключа или без сервиса
+// идемпотентности next выполняется как обычно.
+func (h *MetricHandler) idempotent(w http.ResponseWriter, r *http.Request, key *string, next http.HandlerFunc) {
+	if h.idem == nil || key == nil {
+		next(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	fingerprint := requestFingerprint(r, body)
+
+	record, err := h.idem.Begin(r.Context(), *key, fingerprint)
+	switch {
+	case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+		handleBadRequest(w, err.Error())
+		return
+	case errors.Is(err, domain.ErrIdempotencyKeyInUse):
+		handleError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		handleError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case errors.Is(err, domain.ErrIdempotencyStoreFull):
+		w.Header().Set("Retry-After", strconv.Itoa(idempotencyRetryAfter))
+		handleError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		zl.Log.Error("failed to reserve idempotency key", zap.Error(err))
+		handleInternal(w)
+		return
+	case record != nil:
+		replayResponse(w, record)
+		return
+	}
+
+	capture := &responseCapture{ResponseWriter: w}
+	next(capture, r)
+
+	// Обработчик, который ничего не записал, отвечает 200, как и в net/http
+	status := cmp.Or(capture.status, http.StatusOK)
+
+	// Ответ уже отправлен: запись не должна зависеть от отключения клиента
+	ctx := context.WithoutCancel(r.Context())
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		h.idem.Abort(ctx, *key)
+		return
+	}
+	err = h.idem.Complete(ctx, &domain.IdempotencyRecord{
+		Key:         *key,
+		Fingerprint: fingerprint,
+		Status:      status,
+		ContentType: w.Header().Get("Content-Type"),
+		Hash:        w.Header().Get("HashSHA256"),
+		Body:        capture.body.Bytes(),
+	})
+	if err != nil {
+		zl.Log.Error("failed to save idempotent response", zap.String("key", *key), zap.Error(err))
+		h.idem.Abort(ctx, *key)
+	}
+}
+
+// requestFingerprint - хеш метода, пути, параметров и тела запроса, по
+// которому повтор отличается от другого запроса с тем же ключом.
+func requestFingerprint(r *http.Request, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func replayResponse(w http.ResponseWriter, record *domain.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	if record.Hash != "" {
+		w.Header().Set("HashSHA256", record.Hash)
+	}
+	w.Header().Set(idempotentReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(record.Status)
+	if _, err := w.Write(record.Body); err != nil {
+		zl.Log.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// responseCapture копирует статус и тело ответа для сохранения.
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(statusCode int) {
+	if c.status == 0 {
+		c.status = statusCode
+	}
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -49,6 +49,20 @@ func (g *gzipResponseWriter) Write(data []byte) (int, error) {
 	return g.ResponseWriter.Write(data)
 }
 
+// Flush отправляет клиенту накопленные данные, в том числе буфер gzip,
+// чтобы потоковые ответы (text/event-stream) доходили без задержки.
+func (g *gzipResponseWriter) Flush() {
+	if g.Header().Get("Content-Encoding") == "gzip" {
+		_ = g.writer.Flush()
+	}
+	_ = http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Unwrap возвращает исходный writer для http.ResponseController.
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
 func (g *gzipResponseWriter) Close() error {
 	return g.writer.Close()
 }
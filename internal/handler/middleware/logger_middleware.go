@@ -27,6 +27,16 @@ func (r *statusRecorder) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Flush пробрасывает сброс буфера для потоковых ответов.
+func (r *statusRecorder) Flush() {
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap возвращает исходный writer для http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func LoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
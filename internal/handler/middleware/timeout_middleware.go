@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout ограничивает время обработки запроса, как middleware.Timeout из chi,
+// кроме потоковых маршрутов streams: они открыты, пока клиент не отключится.
+func Timeout(timeout time.Duration, streams ...string) func(http.Handler) http.Handler {
+	limit := middleware.Timeout(timeout)
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(streams, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/goccy/go-json"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 	"go.uber.org/zap"
 
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/influx"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/otlp"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/remotewrite"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 	oapiMetric "github.com/bigsm0uk/metrics-alert-server/pkg/openapi/metric"
@@ -18,9 +24,11 @@ func (h *MetricHandler) UpdateOrCreateMetricByParam(w http.ResponseWriter, r *ht
 	ctx := r.Context()
 
 	dto := &ParamMetric{
-		ID:    id,
-		MType: string(mType),
-		Value: value,
+		ID:       id,
+		MType:    string(mType),
+		Value:    value,
+		Buckets:  h.buckets,
+		Accuracy: h.acc,
 	}
 
 	m, err := dto.Validate()
@@ -44,7 +52,11 @@ func (h *MetricHandler) UpdateOrCreateMetricByParam(w http.ResponseWriter, r *ht
 }
 
 // UpdateOrCreateMetricByBody обновляет или создает метрику по body запроса
-func (h *MetricHandler) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request) {
+func (h *MetricHandler) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request, params oapiMetric.UpdateOrCreateMetricByBodyParams) {
+	h.idempotent(w, r, params.IdempotencyKey, h.updateMetricByBody)
+}
+
+func (h *MetricHandler) updateMetricByBody(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var dto BodyMetric
@@ -70,7 +82,7 @@ func (h *MetricHandler) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *htt
 		return
 	}
 
-	updatedMetric, err := h.service.GetEnrichMetric(ctx, m.ID, m.MType)
+	updatedMetric, err := h.service.GetEnrichMetric(ctx, m.ID, m.MType, m.Labels)
 	if err != nil {
 		zl.Log.Error("failed to get enriched metric", zap.Error(err))
 		handleInternal(w)
@@ -81,8 +93,15 @@ func (h *MetricHandler) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *htt
 	h.notifyAudit(r.RemoteAddr, updatedMetric)
 }
 
-// UpdateOrCreateMetricsBatch Обновляет/сохраняет метрики batch запросов
-func (h *MetricHandler) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request) {
+// UpdateOrCreateMetricsBatch Обновляет/сохраняет метрики batch запросов.
+// С partial=true некорректные метрики не отклоняют весь батч.
+func (h *MetricHandler) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request, params oapiMetric.UpdateOrCreateMetricsBatchParams) {
+	h.idempotent(w, r, params.IdempotencyKey, func(w http.ResponseWriter, r *http.Request) {
+		h.updateMetricsBatch(w, r, deref(params.Partial))
+	})
+}
+
+func (h *MetricHandler) updateMetricsBatch(w http.ResponseWriter, r *http.Request, partial bool) {
 	ctx := r.Context()
 
 	var bodyMetrics []BodyMetric
@@ -91,6 +110,10 @@ func (h *MetricHandler) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *htt
 		handleBadRequest(w, err.Error())
 		return
 	}
+	if partial {
+		h.updateMetricsPartial(w, r, bodyMetrics)
+		return
+	}
 
 	metrics := make([]*domain.Metrics, len(bodyMetrics))
 
@@ -116,6 +139,34 @@ func (h *MetricHandler) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *htt
 	h.notifyAudit(r.RemoteAddr, metrics...)
 }
 
+// updateMetricsPartial сохраняет корректные метрики батча и возвращает
+// результат по каждой метрике. Корректные метрики по-прежнему пишутся одним
+// батчем, поэтому ошибка хранилища отклоняет запрос целиком.
+func (h *MetricHandler) updateMetricsPartial(w http.ResponseWriter, r *http.Request, bodyMetrics []BodyMetric) {
+	results := make([]BatchItemResult, len(bodyMetrics))
+	metrics := make([]*domain.Metrics, 0, len(bodyMetrics))
+
+	for i, bodyMetric := range bodyMetrics {
+		results[i] = BatchItemResult{Index: i, ID: bodyMetric.ID, Type: bodyMetric.MType, Status: BatchItemOK}
+		m, err := bodyMetric.Validate()
+		if err != nil {
+			results[i].Status, results[i].Error = BatchItemError, err.Error()
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	if len(metrics) == 0 {
+		jsonWithHashValueHandler(w, results, h.key)
+		return
+	}
+	if err := h.service.SaveOrUpdateMetricsBatch(r.Context(), metrics); err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+	jsonWithHashValueHandler(w, results, h.key)
+	h.notifyAudit(r.RemoteAddr, metrics...)
+}
+
 // GetValueByBody возвращает метрику по ее типу и id из body запроса
 func (h *MetricHandler) GetValueByBody(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -132,7 +183,7 @@ func (h *MetricHandler) GetValueByBody(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	m, err := h.service.GetEnrichMetric(ctx, dto.ID, dto.Type)
+	m, err := h.service.GetEnrichMetric(ctx, dto.ID, dto.Type, dto.Labels.Clone())
 	if err != nil {
 		handleNotFound(w, err.Error())
 		return
@@ -140,14 +191,196 @@ func (h *MetricHandler) GetValueByBody(w http.ResponseWriter, r *http.Request) {
 	jsonWithHashValueHandler(w, m, h.key)
 }
 
+// CreateSilence создает подавление уведомлений алертинга
+func (h *MetricHandler) CreateSilence(w http.ResponseWriter, r *http.Request) {
+	if h.silence == nil {
+		handleNotFound(w, "silences are not configured")
+		return
+	}
+
+	var dto SilenceDTO
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	silence := dto.ToDomain()
+	if err := h.silence.Create(r.Context(), silence); err != nil {
+		if errors.Is(err, domain.ErrInvalidSilence) {
+			handleBadRequest(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to create silence", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	jsonWithHashStatusHandler(w, http.StatusCreated, silence, h.key)
+}
+
 func (h *MetricHandler) notifyAudit(ip string, metrics ...*domain.Metrics) {
-	auditMessage := domain.AuditMessage{
+	ids := make([]string, len(metrics))
+	for i, metric := range metrics {
+		ids[i] = metric.SeriesID()
+	}
+	h.notifyAuditAction(ip, "", ids)
+}
+
+// notifyAuditAction отправляет событие аудита о действии action над сериями ids.
+func (h *MetricHandler) notifyAuditAction(ip, action string, ids []string) {
+	h.as.NotifyAll(domain.AuditMessage{
 		TS:      time.Now().Unix(),
-		Metrics: make([]string, len(metrics)),
+		Metrics: ids,
 		IPAddr:  ip,
+		Action:  action,
+	})
+}
+
+// ResetCounter обнуляет серию counter метрики по id и лейблам
+func (h *MetricHandler) ResetCounter(w http.ResponseWriter, r *http.Request, id oapiMetric.ID, params oapiMetric.ResetCounterParams) {
+	labels := queryLabels(params.Labels)
+	if err := labels.Validate(); err != nil {
+		handleBadRequest(w, err.Error())
+		return
 	}
-	for i, metric := range metrics {
-		auditMessage.Metrics[i] = metric.ID
+	m, err := h.service.ResetCounter(r.Context(), id, labels)
+	if err != nil {
+		if errors.Is(err, domain.ErrMetricNotFound) {
+			handleNotFound(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to reset counter", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	h.cache.Delete(dashboardCacheKey)
+	h.notifyAuditAction(r.RemoteAddr, domain.AuditActionReset, []string{m.SeriesID()})
+	jsonWithHashValueHandler(w, m, h.key)
+}
+
+// RemoteWrite принимает метрики по протоколу Prometheus remote_write
+func (h *MetricHandler) RemoteWrite(w http.ResponseWriter, r *http.Request, params oapiMetric.RemoteWriteParams) {
+	if params.ContentEncoding != nil && *params.ContentEncoding != "" && *params.ContentEncoding != "snappy" {
+		handleBadRequest(w, fmt.Sprintf("unsupported content encoding %q", *params.ContentEncoding))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, remotewrite.MaxDecodedSize+1))
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+	if len(body) > remotewrite.MaxDecodedSize {
+		handleBadRequest(w, remotewrite.ErrPayloadTooLarge.Error())
+		return
+	}
+
+	req, err := remotewrite.Decode(body)
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+	metrics, err := remotewrite.ToMetrics(req)
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.service.SaveOrUpdateMetricsBatch(r.Context(), metrics); err != nil {
+		zl.Log.Error("failed to save remote write metrics", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	h.notifyAudit(r.RemoteAddr, metrics...)
+}
+
+// InfluxWrite принимает метрики в формате InfluxDB line protocol
+func (h *MetricHandler) InfluxWrite(w http.ResponseWriter, r *http.Request, params oapiMetric.InfluxWriteParams) {
+	var precision string
+	if params.Precision != nil {
+		precision = *params.Precision
+	}
+	unit, err := influx.Precision(precision)
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	res, err := influx.Parse(r.Body, unit, time.Now())
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	if len(res.Metrics) > 0 {
+		if err := h.service.SaveOrUpdateMetricsBatch(r.Context(), res.Metrics); err != nil {
+			zl.Log.Error("failed to save line protocol metrics", zap.Error(err))
+			handleInternal(w)
+			return
+		}
+		h.notifyAudit(r.RemoteAddr, res.Metrics...)
+	}
+
+	if len(res.Errors) > 0 {
+		jsonWithHashStatusHandler(w, http.StatusBadRequest, PartialWriteResponse{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("partial write: %d lines rejected", len(res.Errors)),
+			Errors:  res.Errors,
+		}, h.key)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OtlpMetrics принимает метрики по протоколу OTLP/HTTP в protobuf или JSON
+func (h *MetricHandler) OtlpMetrics(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, otlp.MaxBodySize+1))
+	if err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+	if len(body) > otlp.MaxBodySize {
+		handleBadRequest(w, otlp.ErrPayloadTooLarge.Error())
+		return
+	}
+
+	req, err := otlp.Decode(body, contentType)
+	if err != nil {
+		if errors.Is(err, otlp.ErrUnsupportedContentType) {
+			handleError(w, http.StatusUnsupportedMediaType, err.Error())
+			return
+		}
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	res := h.otlp.Convert(req)
+	if len(res.Metrics) > 0 {
+		if err := h.service.SaveOrUpdateMetricsBatch(r.Context(), res.Metrics); err != nil {
+			zl.Log.Error("failed to save otlp metrics", zap.Error(err))
+			handleInternal(w)
+			return
+		}
+		h.notifyAudit(r.RemoteAddr, res.Metrics...)
+	}
+
+	resp := &colmetricspb.ExportMetricsServiceResponse{}
+	if res.Rejected > 0 {
+		resp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: res.Rejected,
+			ErrorMessage:       res.Message,
+		}
+	}
+	out, outType, err := otlp.Encode(resp, contentType)
+	if err != nil {
+		zl.Log.Error("failed to encode otlp response", zap.Error(err))
+		handleInternal(w)
+		return
 	}
-	h.as.NotifyAll(auditMessage)
+	w.Header().Set("Content-Type", outType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
 }
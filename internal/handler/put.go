@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// PutMetadata создает или заменяет описание метрики
+func (h *MetricHandler) PutMetadata(w http.ResponseWriter, r *http.Request, id string) {
+	if h.meta == nil {
+		handleNotFound(w, "metadata registry is not configured")
+		return
+	}
+
+	var dto MetadataDTO
+
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		handleBadRequest(w, err.Error())
+		return
+	}
+
+	metadata := dto.ToDomain(id)
+	if err := h.meta.Set(r.Context(), metadata); err != nil {
+		if errors.Is(err, domain.ErrInvalidMetadata) {
+			handleBadRequest(w, err.Error())
+			return
+		}
+		zl.Log.Error("failed to save metadata", zap.Error(err))
+		handleInternal(w)
+		return
+	}
+	jsonWithHashValueHandler(w, metadata, h.key)
+}
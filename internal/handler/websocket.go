@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/ws"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/zl"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// wsAuthTimeout - время, за которое агент должен прислать приветствие.
+const wsAuthTimeout = 10 * time.Second
+
+// UpdateMetricsWebSocket принимает батчи метрик по WebSocket от долгоживущих
+// агентов (протокол описан в пакете ws). Валидация, сохранение и аудит
+// совпадают с /updates.
+func (h *MetricHandler) UpdateMetricsWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{CompressionMode: websocket.CompressionContextTakeover})
+	if err != nil {
+		zl.Log.Warn("failed to accept websocket", zap.Error(err))
+		return
+	}
+	conn.SetReadLimit(ws.MaxMessageSize)
+	if !h.trackConn(conn) {
+		conn.Close(websocket.StatusGoingAway, "server is shutting down")
+		return
+	}
+	defer h.untrackConn(conn)
+
+	// Соединение перехвачено и не завершается вместе с запросом:
+	// при остановке сервера его закрывает Close
+	ctx := context.WithoutCancel(r.Context())
+	agent, err := h.wsAuth(ctx, conn)
+	if err != nil {
+		zl.Log.Warn("websocket agent rejected", zap.String("remote_addr", r.RemoteAddr), zap.Error(err))
+		conn.Close(websocket.StatusPolicyViolation, err.Error())
+		return
+	}
+	log := zl.Log.With(zap.String("agent", agent), zap.String("remote_addr", r.RemoteAddr))
+	log.Info("websocket agent connected")
+
+	for {
+		var msg ws.Message
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			switch websocket.CloseStatus(err) {
+			case websocket.StatusNormalClosure, websocket.StatusGoingAway:
+				log.Info("websocket agent disconnected")
+			default:
+				log.Warn("websocket read failed", zap.Error(err))
+				conn.Close(websocket.StatusProtocolError, "read failed")
+			}
+			return
+		}
+		if err := wsjson.Write(ctx, conn, h.wsBatch(ctx, r.RemoteAddr, &msg)); err != nil {
+			log.Warn("websocket write failed", zap.Error(err))
+			return
+		}
+	}
+}
+
+// wsAuth выдает соединению nonce, читает подписанное с ним приветствие
+// агента и возвращает имя агента.
+func (h *MetricHandler) wsAuth(ctx context.Context, conn *websocket.Conn) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, wsAuthTimeout)
+	defer cancel()
+	challenge := ws.NewChallenge()
+	if err := wsjson.Write(ctx, conn, challenge); err != nil {
+		return "", fmt.Errorf("write challenge: %w", err)
+	}
+	var msg ws.Message
+	if err := wsjson.Read(ctx, conn, &msg); err != nil {
+		return "", fmt.Errorf("read auth: %w", err)
+	}
+	if err := msg.VerifyAuth(h.key, challenge.Nonce, time.Now()); err != nil {
+		return "", err
+	}
+	if err := wsjson.Write(ctx, conn, ws.Message{Type: ws.TypeAck}); err != nil {
+		return "", fmt.Errorf("write auth ack: %w", err)
+	}
+	return msg.Agent, nil
+}
+
+// wsBatch сохраняет батч и возвращает подтверждение или отказ с причиной.
+// Батч с ключом идемпотентности, который уже применен, подтверждается
+// сохраненным ответом без повторного применения.
+func (h *MetricHandler) wsBatch(ctx context.Context, ip string, msg *ws.Message) ws.Message {
+	if msg.Type != ws.TypeBatch {
+		return wsNack(msg, fmt.Sprintf("unexpected message type %q", msg.Type))
+	}
+	if h.idem == nil || msg.IdempotencyKey == "" {
+		return h.wsSave(ctx, ip, msg)
+	}
+
+	fingerprint, err := wsBatchFingerprint(msg.Metrics)
+	if err != nil {
+		return wsNack(msg, err.Error())
+	}
+	record, err := h.idem.Begin(ctx, msg.IdempotencyKey, fingerprint)
+	switch {
+	case errors.Is(err, domain.ErrInvalidIdempotencyKey), errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return wsNack(msg, err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyInUse), errors.Is(err, domain.ErrIdempotencyStoreFull):
+		reply := wsNack(msg, err.Error())
+		reply.Retry = true
+		return reply
+	case err != nil:
+		zl.Log.Error("failed to reserve idempotency key", zap.Error(err))
+		reply := wsNack(msg, "failed to reserve idempotency key")
+		reply.Retry = true
+		return reply
+	case record != nil:
+		var reply ws.Message
+		if err := json.Unmarshal(record.Body, &reply); err != nil {
+			zl.Log.Error("failed to decode idempotent response", zap.String("key", msg.IdempotencyKey), zap.Error(err))
+			return wsNack(msg, "failed to decode saved response")
+		}
+		reply.Seq = msg.Seq
+		return reply
+	}
+
+	reply := h.wsSave(ctx, ip, msg)
+	if reply.Type != ws.TypeAck {
+		h.idem.Abort(ctx, msg.IdempotencyKey)
+		return reply
+	}
+	body, err := json.Marshal(reply)
+	if err == nil {
+		err = h.idem.Complete(ctx, &domain.IdempotencyRecord{
+			Key:         msg.IdempotencyKey,
+			Fingerprint: fingerprint,
+			Status:      http.StatusOK,
+			ContentType: "application/json",
+			Body:        body,
+		})
+	}
+	if err != nil {
+		zl.Log.Error("failed to save idempotent response", zap.String("key", msg.IdempotencyKey), zap.Error(err))
+		h.idem.Abort(ctx, msg.IdempotencyKey)
+	}
+	return reply
+}
+
+// wsBatchFingerprint - хеш пути и метрик батча, по которому повтор
+// отличается от другого батча с тем же ключом. Номер батча не входит в
+// отпечаток: после переподключения повтор получает новый номер.
+func wsBatchFingerprint(metrics []domain.Metrics) (string, error) {
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(ws.Path + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+func wsNack(msg *ws.Message, err string) ws.Message {
+	return ws.Message{Type: ws.TypeNack, Seq: msg.Seq, Error: err}
+}
+
+// wsSave валидирует и сохраняет батч, после чего уведомляет аудит.
+func (h *MetricHandler) wsSave(ctx context.Context, ip string, msg *ws.Message) ws.Message {
+	nack := func(err string) ws.Message {
+		return wsNack(msg, err)
+	}
+
+	metrics := make([]*domain.Metrics, len(msg.Metrics))
+	for i, pm := range msg.Metrics {
+		dto := BodyMetric{
+			ID:        pm.ID,
+			MType:     pm.MType,
+			Labels:    pm.Labels,
+			Delta:     pm.Delta,
+			Value:     pm.Value,
+			Histogram: pm.Histogram,
+			Summary:   pm.Summary,
+		}
+		m, err := dto.Validate()
+		if err != nil {
+			return nack(fmt.Sprintf("invalid metric %s: %s", pm.ID, err))
+		}
+		metrics[i] = m
+	}
+	if err := h.service.SaveOrUpdateMetricsBatch(ctx, metrics); err != nil {
+		zl.Log.Error("failed to save websocket batch", zap.Error(err))
+		return nack("failed to save metrics")
+	}
+	h.notifyAudit(ip, metrics...)
+	return ws.Message{Type: ws.TypeAck, Seq: msg.Seq, Updated: len(metrics)}
+}
+
+// trackConn регистрирует соединение для закрытия при остановке сервера;
+// false - сервер уже останавливается.
+func (h *MetricHandler) trackConn(conn *websocket.Conn) bool {
+	h.wsMu.Lock()
+	defer h.wsMu.Unlock()
+	if h.wsConns == nil {
+		return false
+	}
+	h.wsConns[conn] = struct{}{}
+	return true
+}
+
+func (h *MetricHandler) untrackConn(conn *websocket.Conn) {
+	h.wsMu.Lock()
+	defer h.wsMu.Unlock()
+	delete(h.wsConns, conn)
+}
+
+// closeConns закрывает WebSocket соединения агентов; агенты переподключатся
+// к следующему экземпляру сервера.
+func (h *MetricHandler) closeConns() {
+	h.wsMu.Lock()
+	conns := h.wsConns
+	h.wsConns = nil
+	h.wsMu.Unlock()
+	// Close ждет ответного кадра агента, поэтому соединения закрываются параллельно
+	var wg sync.WaitGroup
+	for conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = conn.Close(websocket.StatusGoingAway, "server is shutting down")
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+package mem
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// IdempotencyRepository хранит ключи идемпотентности в памяти. Ключи живут
+// одинаковое время, поэтому очередь в порядке занятия упорядочена и по
+// истечению: истекшие ключи удаляются из ее начала. При превышении maxKeys
+// вытесняются самые старые завершенные ключи; ключи запросов, которые еще
+// выполняются, не вытесняются, иначе повтор запроса применил бы его дважды.
+type IdempotencyRepository struct {
+	mu      sync.Mutex
+	maxKeys int
+	records map[string]domain.IdempotencyRecord
+	queue   []idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+var _ interfaces.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+// NewIdempotencyRepository создает репозиторий не более чем на maxKeys
+// ключей; ноль - без ограничения.
+func NewIdempotencyRepository(maxKeys int) *IdempotencyRepository {
+	return &IdempotencyRepository{maxKeys: maxKeys, records: make(map[string]domain.IdempotencyRecord)}
+}
+
+func (r *IdempotencyRepository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evict(func(e idempotencyEntry) bool { return !now.Before(e.expiresAt) })
+	if existing, ok := r.records[record.Key]; ok {
+		return &existing, nil
+	}
+	if r.maxKeys > 0 && len(r.records) >= r.maxKeys {
+		r.evictCompleted()
+		if len(r.records) >= r.maxKeys {
+			return nil, domain.ErrIdempotencyStoreFull
+		}
+	}
+	r.records[record.Key] = *record
+	r.queue = append(r.queue, idempotencyEntry{key: record.Key, expiresAt: record.ExpiresAt})
+	return nil, nil
+}
+
+func (r *IdempotencyRepository) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Отпечаток и срок жизни задаются при занятии ключа
+	if existing, ok := r.records[record.Key]; ok {
+		existing.Status, existing.ContentType, existing.Hash, existing.Body = record.Status, record.ContentType, record.Hash, record.Body
+		r.records[record.Key] = existing
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, key)
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evict(func(e idempotencyEntry) bool { return !now.Before(e.expiresAt) })
+	return nil
+}
+
+// evict удаляет ключи из начала очереди, пока выполняется условие. Элементы
+// очереди удаленных или занятых заново ключей пропускаются. Вызывается под
+// блокировкой.
+func (r *IdempotencyRepository) evict(cond func(idempotencyEntry) bool) {
+	for len(r.queue) > 0 && cond(r.queue[0]) {
+		e := r.queue[0]
+		r.queue[0] = idempotencyEntry{}
+		r.queue = r.queue[1:]
+		if record, ok := r.records[e.key]; ok && record.ExpiresAt.Equal(e.expiresAt) {
+			delete(r.records, e.key)
+		}
+	}
+}
+
+// evictCompleted удаляет самые старые завершенные ключи, пока их число не
+// станет меньше maxKeys, и убирает из очереди элементы удаленных ключей.
+// Вызывается под блокировкой.
+func (r *IdempotencyRepository) evictCompleted() {
+	kept := r.queue[:0]
+	for _, e := range r.queue {
+		record, ok := r.records[e.key]
+		if !ok || !record.ExpiresAt.Equal(e.expiresAt) {
+			continue
+		}
+		if len(r.records) >= r.maxKeys && record.Done() {
+			delete(r.records, e.key)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	clear(r.queue[len(kept):])
+	r.queue = kept
+}
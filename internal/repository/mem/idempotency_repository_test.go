@@ -0,0 +1,78 @@
+package mem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestIdempotencyRepository_ExpiryAndEviction(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := NewIdempotencyRepository(2)
+	reserve := func(key string, at time.Time) *domain.IdempotencyRecord {
+		t.Helper()
+		existing, err := repo.ReserveIdempotencyKey(ctx, &domain.IdempotencyRecord{Key: key, Fingerprint: key, ExpiresAt: at.Add(time.Minute)}, at)
+		require.NoError(t, err)
+		return existing
+	}
+
+	assert.Nil(t, reserve("a", now))
+	require.NoError(t, repo.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{Key: "a", Status: 200, Body: []byte("ok")}))
+	existing := reserve("a", now)
+	require.NotNil(t, existing)
+	assert.Equal(t, "a", existing.Fingerprint)
+	assert.Equal(t, []byte("ok"), existing.Body)
+	assert.True(t, existing.Done())
+
+	// Третий ключ вытесняет самый старый завершенный
+	assert.Nil(t, reserve("b", now))
+	assert.Nil(t, reserve("c", now))
+	require.NoError(t, repo.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{Key: "b", Status: 200}))
+	assert.Nil(t, reserve("a", now))
+	assert.Len(t, repo.records, 2)
+
+	// Освобожденный ключ можно занять заново
+	require.NoError(t, repo.DeleteIdempotencyKey(ctx, "c"))
+	assert.Nil(t, reserve("c", now))
+
+	// Истекшие ключи удаляются
+	require.NoError(t, repo.DeleteExpiredIdempotencyKeys(ctx, now.Add(time.Minute)))
+	assert.Empty(t, repo.records)
+	assert.Empty(t, repo.queue)
+}
+
+func TestIdempotencyRepository_KeepsInFlightKeys(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := NewIdempotencyRepository(2)
+	reserve := func(key string) (*domain.IdempotencyRecord, error) {
+		return repo.ReserveIdempotencyKey(ctx, &domain.IdempotencyRecord{Key: key, Fingerprint: key, ExpiresAt: now.Add(time.Minute)}, now)
+	}
+
+	_, err := reserve("in-flight")
+	require.NoError(t, err)
+	_, err = reserve("done")
+	require.NoError(t, err)
+	require.NoError(t, repo.SaveIdempotencyRecord(ctx, &domain.IdempotencyRecord{Key: "done", Status: 200}))
+
+	// Вытесняется только завершенный ключ
+	_, err = reserve("next")
+	require.NoError(t, err)
+	assert.Contains(t, repo.records, "in-flight")
+	assert.NotContains(t, repo.records, "done")
+
+	// Все ключи в работе: новый ключ отклоняется, повтор выполняющегося
+	// запроса по-прежнему видит его ключ
+	_, err = reserve("overflow")
+	require.ErrorIs(t, err, domain.ErrIdempotencyStoreFull)
+	existing, err := reserve("in-flight")
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.Done())
+}
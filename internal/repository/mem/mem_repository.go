@@ -3,6 +3,8 @@ package mem
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
@@ -12,21 +14,72 @@ import (
 
 type MemRepository struct {
 	storage *storage.MemStorage
+	history *storage.MemHistory
+	rollups *storage.MemRollups
 }
 
-var _ interfaces.MetricsRepository = (*MemRepository)(nil)
+var (
+	_ interfaces.MetricsRepository = (*MemRepository)(nil)
+	_ interfaces.SampleRepository  = (*MemRepository)(nil)
+	_ interfaces.RollupRepository  = (*MemRepository)(nil)
+)
+
+// NewMemRepository создает репозиторий с историей емкостью storage.DefaultHistoryCapacity.
+func NewMemRepository(st *storage.MemStorage) *MemRepository {
+	return NewMemRepositoryWithHistory(st, storage.NewMemHistory(storage.DefaultHistoryCapacity))
+}
 
-func NewMemRepository(storage *storage.MemStorage) *MemRepository {
-	return &MemRepository{storage: storage}
+// NewMemRepositoryWithHistory создает репозиторий, записывающий каждое обновление в history.
+func NewMemRepositoryWithHistory(st *storage.MemStorage, history *storage.MemHistory) *MemRepository {
+	return &MemRepository{storage: st, history: history, rollups: storage.NewMemRollups()}
 }
 
+// SaveOrUpdate сохраняет метрику, отмечает время ее обновления
+// и добавляет сэмпл в историю.
 func (r *MemRepository) SaveOrUpdate(ctx context.Context, metric *domain.Metrics) error {
-	r.storage.Set(*metric)
+	m := *metric
+	m.UpdatedAt = time.Now()
+	r.storage.Set(m)
+	r.appendSample(&m)
+	return nil
+}
+
+func (r *MemRepository) appendSample(m *domain.Metrics) {
+	if v, ok := m.NumericValue(); ok {
+		r.history.Append(m.SeriesID(), m.MType, domain.Sample{TS: m.UpdatedAt, Value: v})
+	}
+}
+
+func (r *MemRepository) Samples(ctx context.Context, id, metricType string, from, to time.Time) ([]domain.Sample, error) {
+	return r.history.Range(id, metricType, from, to), nil
+}
+
+func (r *MemRepository) QueryRange(ctx context.Context, q domain.RangeQuery) ([]domain.Sample, error) {
+	samples := r.history.Range(q.ID, q.MType, q.Start.Add(-domain.RangeLookback), q.End)
+	return q.Align(samples), nil
+}
+
+func (r *MemRepository) DeleteSamplesBefore(ctx context.Context, id, metricType string, before time.Time) error {
+	r.history.DeleteBefore(id, metricType, before)
+	return nil
+}
+
+func (r *MemRepository) SaveBuckets(ctx context.Context, id, metricType string, resolution time.Duration, buckets []domain.Bucket) error {
+	r.rollups.Save(id, metricType, resolution, buckets)
+	return nil
+}
+
+func (r *MemRepository) Buckets(ctx context.Context, id, metricType string, resolution time.Duration, from, to time.Time) ([]domain.Bucket, error) {
+	return r.rollups.Range(id, metricType, resolution, from, to), nil
+}
+
+func (r *MemRepository) DeleteBucketsBefore(ctx context.Context, id, metricType string, resolution time.Duration, before time.Time) error {
+	r.rollups.DeleteBefore(id, metricType, resolution, before)
 	return nil
 }
 
-func (r *MemRepository) Metric(ctx context.Context, id, t string) (*domain.Metrics, error) {
-	metric, ok := r.storage.Get(id, t)
+func (r *MemRepository) Metric(ctx context.Context, id, t string, labels domain.Labels) (*domain.Metrics, error) {
+	metric, ok := r.storage.Get(id, t, labels)
 	if !ok {
 		return nil, domain.ErrMetricNotFound
 	}
@@ -39,16 +92,19 @@ func (r *MemRepository) MetricList(ctx context.Context) ([]domain.Metrics, error
 }
 
 func (r *MemRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*domain.Metrics) error {
+	now := time.Now()
 	for _, metric := range metrics {
-		if m, ok := r.storage.Get(metric.ID, metric.MType); ok {
+		updated := *metric
+		if m, ok := r.storage.Get(metric.ID, metric.MType, metric.Labels); ok {
 			if s := strategy.StrategyFactory(metric.MType); s != nil {
-				r.storage.Set(*s.Update(&m, metric))
+				updated = *s.Update(&m, metric)
 			} else {
 				return fmt.Errorf("unsupported metric type: %s", metric.MType)
 			}
-		} else {
-			r.storage.Set(*metric)
 		}
+		updated.UpdatedAt = now
+		r.storage.Set(updated)
+		r.appendSample(&updated)
 	}
 	return nil
 }
@@ -58,6 +114,42 @@ func (r *MemRepository) MetricListByType(ctx context.Context, metricType string)
 	return metrics, nil
 }
 
+func (r *MemRepository) MetricPage(ctx context.Context, q domain.MetricQuery) ([]domain.Metrics, error) {
+	match := q.Filter()
+	metrics := slices.DeleteFunc(r.storage.GetAll(), func(m domain.Metrics) bool {
+		return !match(&m) || (q.After != nil && q.Compare(q.Cursor(&m), q.After) <= 0)
+	})
+	slices.SortFunc(metrics, func(a, b domain.Metrics) int {
+		return q.Compare(q.Cursor(&a), q.Cursor(&b))
+	})
+	return metrics[:min(len(metrics), q.Limit)], nil
+}
+
+// Delete удаляет метрику вместе с ее историей и агрегатами, чтобы
+// метрика, созданная заново с тем же id, не унаследовала старую историю.
+func (r *MemRepository) Delete(ctx context.Context, id, metricType string, labels domain.Labels) error {
+	if !r.storage.Delete(id, metricType, labels) {
+		return domain.ErrMetricNotFound
+	}
+	r.deleteHistory(id, metricType, labels)
+	return nil
+}
+
+func (r *MemRepository) DeleteBatch(ctx context.Context, metrics []domain.Metrics) error {
+	for _, m := range metrics {
+		if r.storage.Delete(m.ID, m.MType, m.Labels) {
+			r.deleteHistory(m.ID, m.MType, m.Labels)
+		}
+	}
+	return nil
+}
+
+func (r *MemRepository) deleteHistory(id, metricType string, labels domain.Labels) {
+	seriesID := domain.SeriesID(id, labels)
+	r.history.Delete(seriesID, metricType)
+	r.rollups.Delete(seriesID, metricType)
+}
+
 func (r *MemRepository) Ping(ctx context.Context) error {
 	return nil
 }
@@ -3,8 +3,10 @@ package mem
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
@@ -13,8 +15,9 @@ import (
 
 func TestMemRepository_Get(t *testing.T) {
 	type args struct {
-		id string
-		t  string
+		id     string
+		t      string
+		labels domain.Labels
 	}
 	r := NewMemRepository(storage.NewMemStorage())
 	r.SaveOrUpdate(context.Background(), &domain.Metrics{
@@ -22,6 +25,12 @@ func TestMemRepository_Get(t *testing.T) {
 		MType: domain.Gauge,
 		Value: lo.ToPtr(float64(1024)),
 	})
+	r.SaveOrUpdate(context.Background(), &domain.Metrics{
+		ID:     "Alloc",
+		MType:  domain.Gauge,
+		Labels: domain.Labels{"host": "a"},
+		Value:  lo.ToPtr(float64(2048)),
+	})
 	r.SaveOrUpdate(context.Background(), &domain.Metrics{
 		ID:    "PollCount",
 		MType: domain.Counter,
@@ -49,6 +58,22 @@ func TestMemRepository_Get(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "get gauge with labels",
+			r:    r,
+			args: args{
+				id:     "Alloc",
+				t:      domain.Gauge,
+				labels: domain.Labels{"host": "a"},
+			},
+			want: &domain.Metrics{
+				ID:     "Alloc",
+				MType:  domain.Gauge,
+				Labels: domain.Labels{"host": "a"},
+				Value:  lo.ToPtr(float64(2048)),
+			},
+			wantErr: false,
+		},
 		{
 			name: "get counter",
 			r:    r,
@@ -73,14 +98,27 @@ func TestMemRepository_Get(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "get not found by labels",
+			r:    r,
+			args: args{
+				id:     "Alloc",
+				t:      domain.Gauge,
+				labels: domain.Labels{"host": "b"},
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := tt.r.Metric(context.Background(), tt.args.id, tt.args.t)
+			got, err := tt.r.Metric(context.Background(), tt.args.id, tt.args.t, tt.args.labels)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
+				require.False(t, got.UpdatedAt.IsZero())
+				got.UpdatedAt = time.Time{}
 				require.Equal(t, tt.want, got)
 			}
 		})
@@ -173,6 +211,10 @@ func TestMemRepository_GetAll(t *testing.T) {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
+				for i := range got {
+					require.False(t, got[i].UpdatedAt.IsZero())
+					got[i].UpdatedAt = time.Time{}
+				}
 				require.ElementsMatch(t, tt.want, got)
 			}
 		})
@@ -194,3 +236,41 @@ func createRepoWithData() *MemRepository {
 	r.SaveOrUpdate(context.Background(), p)
 	return r
 }
+
+func TestMemRepository_DeleteDropsHistory(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemRepository(storage.NewMemStorage())
+	labels := domain.Labels{"host": "a"}
+	seriesID := domain.SeriesID("Alloc", labels)
+	from := time.Now().Add(-time.Minute)
+
+	require.NoError(t, r.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Labels: labels, Value: lo.ToPtr(1.0)}))
+	require.NoError(t, r.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(2.0)}))
+	require.NoError(t, r.SaveBuckets(ctx, seriesID, domain.Gauge, time.Minute, []domain.Bucket{{Start: from, Count: 1}}))
+
+	require.NoError(t, r.Delete(ctx, "Alloc", domain.Gauge, labels))
+
+	samples, err := r.Samples(ctx, seriesID, domain.Gauge, from, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+	buckets, err := r.Buckets(ctx, seriesID, domain.Gauge, time.Minute, from, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+
+	// История серии без лейблов не затронута
+	samples, err = r.Samples(ctx, "Alloc", domain.Gauge, from, time.Now())
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+
+	// Метрика, созданная заново, начинает историю с нуля
+	require.NoError(t, r.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Labels: labels, Value: lo.ToPtr(3.0)}))
+	samples, err = r.Samples(ctx, seriesID, domain.Gauge, from, time.Now())
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 3.0, samples[0].Value)
+
+	require.NoError(t, r.DeleteBatch(ctx, []domain.Metrics{{ID: "Alloc", MType: domain.Gauge}}))
+	samples, err = r.Samples(ctx, "Alloc", domain.Gauge, from, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
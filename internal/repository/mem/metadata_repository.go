@@ -0,0 +1,95 @@
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// MetadataRepository хранит описания метрик в памяти и сохраняет их в JSON
+// файл при каждом изменении. Если path пустой, описания не сохраняются.
+type MetadataRepository struct {
+	path     string
+	mu       sync.RWMutex
+	metadata map[string]domain.MetricMetadata
+}
+
+var _ interfaces.MetadataRepository = (*MetadataRepository)(nil)
+
+// NewMetadataRepository создает репозиторий и восстанавливает описания из файла path.
+func NewMetadataRepository(path string) (*MetadataRepository, error) {
+	r := &MetadataRepository{path: path, metadata: make(map[string]domain.MetricMetadata)}
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *MetadataRepository) SaveMetadata(ctx context.Context, metadata *domain.MetricMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metadata[metadata.ID] = *metadata
+	return r.save()
+}
+
+func (r *MetadataRepository) Metadata(ctx context.Context) ([]domain.MetricMetadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]domain.MetricMetadata, 0, len(r.metadata))
+	for _, m := range r.metadata {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+// save перезаписывает файл всеми описаниями. Вызывается под блокировкой.
+func (r *MetadataRepository) save() error {
+	if r.path == "" {
+		return nil
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, m := range r.metadata {
+		if err := encoder.Encode(m); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(r.path, buffer.Bytes(), 0o644)
+}
+
+// restore загружает описания из файла, если он существует.
+func (r *MetadataRepository) restore() error {
+	if r.path == "" {
+		return nil
+	}
+	file, err := os.Open(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var m domain.MetricMetadata
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.metadata[m.ID] = m
+	}
+}
@@ -0,0 +1,38 @@
+package mem
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestMetadataRepository_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+
+	repo, err := NewMetadataRepository(path)
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Second)
+	heap := &domain.MetricMetadata{ID: "MCacheSys", Description: "Память под mcache", Unit: "bytes", Owner: "runtime", UpdatedAt: now}
+	require.NoError(t, repo.SaveMetadata(ctx, heap))
+	require.NoError(t, repo.SaveMetadata(ctx, &domain.MetricMetadata{ID: "Alloc", Unit: "bytes", UpdatedAt: now}))
+
+	// Повторное сохранение заменяет описание
+	heap.Owner = "platform"
+	require.NoError(t, repo.SaveMetadata(ctx, heap))
+
+	// Описания восстанавливаются из файла после перезапуска
+	restored, err := NewMetadataRepository(path)
+	require.NoError(t, err)
+	metadata, err := restored.Metadata(ctx)
+	require.NoError(t, err)
+	require.Len(t, metadata, 2)
+	assert.Equal(t, "Alloc", metadata[0].ID)
+	assert.Equal(t, *heap, metadata[1])
+}
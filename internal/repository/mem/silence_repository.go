@@ -0,0 +1,111 @@
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// SilenceRepository хранит подавления в памяти и сохраняет их в JSON файл
+// при каждом изменении. Если path пустой, подавления не сохраняются.
+type SilenceRepository struct {
+	path     string
+	mu       sync.RWMutex
+	silences map[string]domain.Silence
+}
+
+var _ interfaces.SilenceRepository = (*SilenceRepository)(nil)
+
+// NewSilenceRepository создает репозиторий и восстанавливает подавления из файла path.
+func NewSilenceRepository(path string) (*SilenceRepository, error) {
+	r := &SilenceRepository{path: path, silences: make(map[string]domain.Silence)}
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *SilenceRepository) SaveSilence(ctx context.Context, silence *domain.Silence) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.silences[silence.ID] = *silence
+	return r.save()
+}
+
+func (r *SilenceRepository) Silences(ctx context.Context) ([]domain.Silence, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]domain.Silence, 0, len(r.silences))
+	for _, s := range r.silences {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func (r *SilenceRepository) DeleteSilence(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.silences[id]; !ok {
+		return domain.ErrSilenceNotFound
+	}
+	delete(r.silences, id)
+	return r.save()
+}
+
+// save перезаписывает файл всеми подавлениями. Вызывается под блокировкой.
+func (r *SilenceRepository) save() error {
+	if r.path == "" {
+		return nil
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, s := range r.silences {
+		if err := encoder.Encode(s); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(r.path, buffer.Bytes(), 0o644)
+}
+
+// restore загружает подавления из файла, если он существует.
+func (r *SilenceRepository) restore() error {
+	if r.path == "" {
+		return nil
+	}
+	file, err := os.Open(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for {
+		var s domain.Silence
+		err := decoder.Decode(&s)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("silence %s: %w", s.ID, err)
+		}
+		r.silences[s.ID] = s
+	}
+}
@@ -0,0 +1,35 @@
+package mem
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func TestSilenceRepository_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "silences.json")
+
+	repo, err := NewSilenceRepository(path)
+	require.NoError(t, err)
+	now := time.Now().UTC().Truncate(time.Second)
+	silence := &domain.Silence{ID: "abc", MetricID: "HeapAlloc", StartsAt: now, EndsAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repo.SaveSilence(ctx, silence))
+
+	// Подавления восстанавливаются из файла после перезапуска
+	restored, err := NewSilenceRepository(path)
+	require.NoError(t, err)
+	silences, err := restored.Silences(ctx)
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+	assert.Equal(t, *silence, silences[0])
+
+	require.NoError(t, restored.DeleteSilence(ctx, "abc"))
+	assert.ErrorIs(t, restored.DeleteSilence(ctx, "abc"), domain.ErrSilenceNotFound)
+}
@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
+
+	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func (r *PostgresRepository) Samples(ctx context.Context, id, metricType string, from, to time.Time) ([]domain.Sample, error) {
+	sqlQuery, args, err := sq.
+		Select("ts", "value").
+		From("metric_samples").
+		Where(sq.Eq{"id": id, "type": metricType}).
+		Where(sq.GtOrEq{"ts": from}).
+		Where(sq.LtOrEq{"ts": to}).
+		OrderBy("ts").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []domain.Sample
+	for rows.Next() {
+		var s domain.Sample
+		if err := rows.Scan(&s.TS, &s.Value); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+func (r *PostgresRepository) DeleteSamplesBefore(ctx context.Context, id, metricType string, before time.Time) error {
+	sqlQuery, args, err := sq.
+		Delete("metric_samples").
+		Where(sq.Eq{"id": id, "type": metricType}).
+		Where(sq.Lt{"ts": before}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
+
+// queryRangeSQL выбирает для каждой точки generate_series последний сэмпл
+// в окне (ts - lookback, ts]. Интервалы передаются в микросекундах.
+const queryRangeSQL = `SELECT g.ts, s.value
+FROM generate_series($3::TIMESTAMPTZ, $4::TIMESTAMPTZ, $5 * INTERVAL '1 microsecond') AS g(ts)
+CROSS JOIN LATERAL (
+    SELECT value FROM metric_samples
+    WHERE id = $1 AND type = $2 AND ts <= g.ts AND ts > g.ts - $6 * INTERVAL '1 microsecond'
+    ORDER BY ts DESC
+    LIMIT 1
+) s
+ORDER BY g.ts`
+
+func (r *PostgresRepository) QueryRange(ctx context.Context, q domain.RangeQuery) ([]domain.Sample, error) {
+	args := []any{q.ID, q.MType, q.Start, q.End, q.Step.Microseconds(), domain.RangeLookback.Microseconds()}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, queryRangeSQL, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	var points []domain.Sample
+	for rows.Next() {
+		var p domain.Sample
+		if err := rows.Scan(&p.TS, &p.Value); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
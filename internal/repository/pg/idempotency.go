@@ -0,0 +1,141 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
+
+	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+var _ interfaces.IdempotencyRepository = (*PostgresRepository)(nil)
+
+// ReserveIdempotencyKey занимает ключ одним запросом: вставка конфликтует с
+// существующим ключом, и запись перезаписывается, только если ключ истек.
+// Если ключ занят, читается существующая запись.
+func (r *PostgresRepository) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	insertQuery, insertArgs, err := sq.
+		Insert("idempotency_keys").
+		Columns("key", "fingerprint", "status", "content_type", "hash", "body", "expires_at").
+		Values(record.Key, record.Fingerprint, 0, "", "", nil, record.ExpiresAt).
+		Suffix(`
+			ON CONFLICT (key)
+			DO UPDATE SET
+				fingerprint = EXCLUDED.fingerprint,
+				status = 0,
+				content_type = '',
+				hash = '',
+				body = NULL,
+				expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= ?
+			RETURNING key
+		`, now).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+	selectQuery, selectArgs, err := sq.
+		Select("key", "fingerprint", "status", "content_type", "hash", "body", "expires_at").
+		From("idempotency_keys").
+		Where(sq.Eq{"key": record.Key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var existing *domain.IdempotencyRecord
+
+	operation := func() error {
+		existing = nil
+		var key string
+		err := r.pool.QueryRow(ctx, insertQuery, insertArgs...).Scan(&key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			var e domain.IdempotencyRecord
+			err = r.pool.QueryRow(ctx, selectQuery, selectArgs...).
+				Scan(&e.Key, &e.Fingerprint, &e.Status, &e.ContentType, &e.Hash, &e.Body, &e.ExpiresAt)
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Ключ освободили между запросами
+				return backoff.Permanent(domain.ErrIdempotencyKeyInUse)
+			}
+			existing = &e
+		}
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (r *PostgresRepository) SaveIdempotencyRecord(ctx context.Context, record *domain.IdempotencyRecord) error {
+	sqlQuery, args, err := sq.
+		Update("idempotency_keys").
+		Set("status", record.Status).
+		Set("content_type", record.ContentType).
+		Set("hash", record.Hash).
+		Set("body", record.Body).
+		Where(sq.Eq{"key": record.Key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+	return r.execIdempotency(ctx, sqlQuery, args)
+}
+
+func (r *PostgresRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	sqlQuery, args, err := sq.
+		Delete("idempotency_keys").
+		Where(sq.Eq{"key": key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+	return r.execIdempotency(ctx, sqlQuery, args)
+}
+
+func (r *PostgresRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	sqlQuery, args, err := sq.
+		Delete("idempotency_keys").
+		Where(sq.LtOrEq{"expires_at": now}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+	return r.execIdempotency(ctx, sqlQuery, args)
+}
+
+func (r *PostgresRepository) execIdempotency(ctx context.Context, sqlQuery string, args []any) error {
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
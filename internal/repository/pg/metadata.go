@@ -0,0 +1,92 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
+
+	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+var _ interfaces.MetadataRepository = (*PostgresRepository)(nil)
+
+func (r *PostgresRepository) SaveMetadata(ctx context.Context, metadata *domain.MetricMetadata) error {
+	sqlQuery, args, err := sq.
+		Insert("metric_metadata").
+		Columns("id", "description", "unit", "owner", "updated_at").
+		Values(metadata.ID, metadata.Description, metadata.Unit, metadata.Owner, metadata.UpdatedAt).
+		Suffix(`
+			ON CONFLICT (id)
+			DO UPDATE SET
+				description = EXCLUDED.description,
+				unit = EXCLUDED.unit,
+				owner = EXCLUDED.owner,
+				updated_at = EXCLUDED.updated_at
+		`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
+
+func (r *PostgresRepository) Metadata(ctx context.Context) ([]domain.MetricMetadata, error) {
+	sqlQuery, args, err := sq.
+		Select("id", "description", "unit", "owner", "updated_at").
+		From("metric_metadata").
+		OrderBy("id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	var metadata []domain.MetricMetadata
+	for rows.Next() {
+		var m domain.MetricMetadata
+		if err := rows.Scan(&m.ID, &m.Description, &m.Unit, &m.Owner, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		metadata = append(metadata, m)
+	}
+	return metadata, rows.Err()
+}
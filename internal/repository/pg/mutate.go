@@ -2,6 +2,7 @@ package pg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
@@ -9,6 +10,7 @@ import (
 
 	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/strategy"
 )
 
 func (r *PostgresRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*domain.Metrics) error {
@@ -16,17 +18,22 @@ func (r *PostgresRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*d
 		return nil
 	}
 
+	metrics, err := r.mergeDistributions(ctx, metrics)
+	if err != nil {
+		return err
+	}
+
 	b := sq.
 		Insert("metrics").
-		Columns("id", "type", "value", "delta", "hash").
+		Columns("id", "type", "labels", "series_id", "value", "delta", "histogram", "summary", "hash").
 		PlaceholderFormat(sq.Dollar)
 
 	for _, m := range metrics {
-		b = b.Values(m.ID, m.MType, m.Value, m.Delta, m.Hash)
+		b = b.Values(m.ID, m.MType, labelsArg(m.Labels), m.SeriesID(), m.Value, m.Delta, m.Histogram, m.Summary, m.Hash)
 	}
 
 	b = b.Suffix(`
-		ON CONFLICT (id, type)
+		ON CONFLICT (id, type, labels)
 		DO UPDATE SET
 			delta = CASE
 				WHEN metrics.type = 'counter' THEN COALESCE(metrics.delta, 0) + COALESCE(EXCLUDED.delta, 0)
@@ -36,14 +43,18 @@ func (r *PostgresRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*d
 				WHEN metrics.type = 'gauge' THEN EXCLUDED.value
 				ELSE metrics.value
 			END,
+			histogram = EXCLUDED.histogram,
+			summary = EXCLUDED.summary,
 			hash = EXCLUDED.hash,
 			updated_at = NOW()
+		RETURNING series_id, type, value, delta, updated_at
 	`)
 
 	sqlQuery, args, err := b.ToSql()
 	if err != nil {
 		return fmt.Errorf("build query: %w", err)
 	}
+	sqlQuery = withSamples(sqlQuery)
 
 	operation := func() error {
 		_, err := r.pool.Exec(ctx, sqlQuery, args...)
@@ -63,10 +74,10 @@ func (r *PostgresRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*d
 func (r *PostgresRepository) SaveOrUpdate(ctx context.Context, metric *domain.Metrics) error {
 	b := sq.
 		Insert("metrics").
-		Columns("id", "type", "value", "delta", "hash").
-		Values(metric.ID, metric.MType, metric.Value, metric.Delta, metric.Hash).
+		Columns("id", "type", "labels", "series_id", "value", "delta", "histogram", "summary", "hash").
+		Values(metric.ID, metric.MType, labelsArg(metric.Labels), metric.SeriesID(), metric.Value, metric.Delta, metric.Histogram, metric.Summary, metric.Hash).
 		Suffix(`
-			ON CONFLICT (id, type)
+			ON CONFLICT (id, type, labels)
 			DO UPDATE SET
 				delta = CASE
 					WHEN metrics.type = 'counter' THEN COALESCE(EXCLUDED.delta, 0)
@@ -76,8 +87,11 @@ func (r *PostgresRepository) SaveOrUpdate(ctx context.Context, metric *domain.Me
 					WHEN metrics.type = 'gauge' THEN EXCLUDED.value
 					ELSE metrics.value
 				END,
+				histogram = EXCLUDED.histogram,
+				summary = EXCLUDED.summary,
 				hash = EXCLUDED.hash,
 				updated_at = NOW()
+			RETURNING series_id, type, value, delta, updated_at
 		`).
 		PlaceholderFormat(sq.Dollar)
 
@@ -85,6 +99,7 @@ func (r *PostgresRepository) SaveOrUpdate(ctx context.Context, metric *domain.Me
 	if err != nil {
 		return fmt.Errorf("build query: %w", err)
 	}
+	sqlQuery = withSamples(sqlQuery)
 
 	operation := func() error {
 		_, err := r.pool.Exec(ctx, sqlQuery, args...)
@@ -100,3 +115,127 @@ func (r *PostgresRepository) SaveOrUpdate(ctx context.Context, metric *domain.Me
 
 	return backoff.Retry(operation, newBackoff())
 }
+
+func (r *PostgresRepository) Delete(ctx context.Context, id, metricType string, labels domain.Labels) error {
+	affected, err := r.deleteWhere(ctx, seriesCond(id, metricType, labels))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrMetricNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) DeleteBatch(ctx context.Context, metrics []domain.Metrics) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	cond := make(sq.Or, len(metrics))
+	for i, m := range metrics {
+		cond[i] = seriesCond(m.ID, m.MType, m.Labels)
+	}
+	_, err := r.deleteWhere(ctx, cond)
+	return err
+}
+
+// seriesCond возвращает условие на одну серию: id, тип и лейблы.
+func seriesCond(id, metricType string, labels domain.Labels) sq.Sqlizer {
+	return sq.And{
+		sq.Eq{"id": id, "type": metricType},
+		sq.Expr("labels = ?::jsonb", labelsArg(labels)),
+	}
+}
+
+// deleteWhere удаляет метрики по условию вместе с их историей и агрегатами
+// и возвращает число удаленных метрик.
+func (r *PostgresRepository) deleteWhere(ctx context.Context, cond sq.Sqlizer) (int64, error) {
+	sqlQuery, args, err := sq.
+		Delete("metrics").
+		Where(cond).
+		Suffix("RETURNING series_id, type").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build query: %w", err)
+	}
+	sqlQuery = withHistoryDelete(sqlQuery)
+
+	var affected int64
+
+	operation := func() error {
+		err := r.pool.QueryRow(ctx, sqlQuery, args...).Scan(&affected)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// mergeDistributions объединяет histogram и summary батча с сохраненными
+// значениями: в отличие от counter, сложить бакеты или бины sketch в
+// ON CONFLICT средствами SQL нельзя, поэтому upsert записывает уже
+// объединенное значение.
+func (r *PostgresRepository) mergeDistributions(ctx context.Context, metrics []*domain.Metrics) ([]*domain.Metrics, error) {
+	merged := make([]*domain.Metrics, len(metrics))
+	for i, m := range metrics {
+		merged[i] = m
+		if m.MType != domain.Histogram && m.MType != domain.Summary {
+			continue
+		}
+		old, err := r.Metric(ctx, m.ID, m.MType, m.Labels)
+		if errors.Is(err, domain.ErrMetricNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get %s %s: %w", m.MType, m.SeriesID(), err)
+		}
+		merged[i] = strategy.StrategyFactory(m.MType).Update(old, m)
+	}
+	return merged, nil
+}
+
+// withSamples оборачивает upsert метрик в CTE, которое в том же запросе
+// записывает сохраненные значения в историю metric_samples.
+// upsert должен возвращать series_id, type, value, delta, updated_at.
+func withSamples(upsert string) string {
+	return `WITH upserted AS (` + upsert + `)
+		INSERT INTO metric_samples (id, type, ts, value)
+		SELECT series_id, type, updated_at, COALESCE(value, delta::DOUBLE PRECISION)
+		FROM upserted
+		WHERE value IS NOT NULL OR delta IS NOT NULL`
+}
+
+// withHistoryDelete оборачивает удаление метрик в CTE, которое в том же
+// запросе удаляет сэмплы и агрегаты удаленных серий, и возвращает число
+// удаленных метрик. delete должен возвращать series_id, type.
+func withHistoryDelete(delete string) string {
+	return `WITH deleted AS (` + delete + `),
+		deleted_samples AS (
+			DELETE FROM metric_samples s USING deleted d
+			WHERE s.id = d.series_id AND s.type = d.type
+		),
+		deleted_rollups AS (
+			DELETE FROM metric_rollups r USING deleted d
+			WHERE r.id = d.series_id AND r.type = d.type
+		)
+		SELECT COUNT(*) FROM deleted`
+}
+
+// labelsArg возвращает лейблы для колонки labels: метрика без лейблов
+// хранится с пустым объектом, а не с JSON null, чтобы попадать в тот же ключ.
+func labelsArg(labels domain.Labels) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
+}
@@ -19,7 +19,11 @@ type PostgresRepository struct {
 	pool *pgxpool.Pool
 }
 
-var _ interfaces.MetricsRepository = (*PostgresRepository)(nil)
+var (
+	_ interfaces.MetricsRepository = (*PostgresRepository)(nil)
+	_ interfaces.SampleRepository  = (*PostgresRepository)(nil)
+	_ interfaces.RollupRepository  = (*PostgresRepository)(nil)
+)
 
 // newBackoff создает конфигурацию backoff для retry операций
 func newBackoff() *backoff.ExponentialBackOff {
@@ -53,15 +57,101 @@ func NewPostgresRepository(ctx context.Context, cfg *storage.StorageConfig) (*Po
 func (r *PostgresRepository) Bootstrap(ctx context.Context) error {
 	sql := `CREATE TABLE IF NOT EXISTS metrics (
     id VARCHAR(255) NOT NULL,
-    type VARCHAR(50) NOT NULL CHECK (type IN ('counter', 'gauge')),
+    type VARCHAR(50) NOT NULL CHECK (type IN ('counter', 'gauge', 'histogram', 'summary')),
     delta BIGINT,
     value DOUBLE PRECISION,
+    histogram JSONB,
+    summary JSONB,
     hash VARCHAR(255),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    
-    PRIMARY KEY (id, type)
-);`
+    labels JSONB NOT NULL DEFAULT '{}',
+    series_id TEXT NOT NULL,
+
+    PRIMARY KEY (id, type, labels)
+);
+CREATE TABLE IF NOT EXISTS silences (
+    id VARCHAR(64) PRIMARY KEY,
+    metric_id VARCHAR(255) NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS metric_metadata (
+    id VARCHAR(255) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    unit VARCHAR(255) NOT NULL DEFAULT '',
+    owner VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    hash VARCHAR(255) NOT NULL DEFAULT '',
+    body BYTEA,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+CREATE TABLE IF NOT EXISTS metric_samples (
+    id TEXT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    ts TIMESTAMP WITH TIME ZONE NOT NULL,
+    value DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_series_ts ON metric_samples(id, type, ts);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_ts ON metric_samples(ts);
+CREATE TABLE IF NOT EXISTS metric_rollups (
+    id TEXT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    resolution INTEGER NOT NULL,
+    bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    min DOUBLE PRECISION NOT NULL,
+    max DOUBLE PRECISION NOT NULL,
+    sum DOUBLE PRECISION NOT NULL,
+    count BIGINT NOT NULL,
+    last DOUBLE PRECISION NOT NULL,
+
+    PRIMARY KEY (id, type, resolution, bucket_start)
+);
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'metrics' AND column_name = 'labels'
+    ) THEN
+        ALTER TABLE metrics ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+        ALTER TABLE metrics ADD COLUMN series_id TEXT;
+        UPDATE metrics SET series_id = id;
+        ALTER TABLE metrics ALTER COLUMN series_id SET NOT NULL;
+        ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_pkey;
+        ALTER TABLE metrics ADD PRIMARY KEY (id, type, labels);
+        ALTER TABLE metric_samples ALTER COLUMN id TYPE TEXT;
+        ALTER TABLE metric_rollups ALTER COLUMN id TYPE TEXT;
+    END IF;
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'metrics' AND column_name = 'histogram'
+    ) THEN
+        ALTER TABLE metrics ADD COLUMN histogram JSONB;
+        ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+        ALTER TABLE metrics ADD CONSTRAINT metrics_type_check
+            CHECK (type IN ('counter', 'gauge', 'histogram'));
+    END IF;
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'metrics' AND column_name = 'summary'
+    ) THEN
+        ALTER TABLE metrics ADD COLUMN summary JSONB;
+        ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+        ALTER TABLE metrics ADD CONSTRAINT metrics_type_check
+            CHECK (type IN ('counter', 'gauge', 'histogram', 'summary'));
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_metrics_list ON metrics((id COLLATE "C"), (series_id COLLATE "C"), (type COLLATE "C"));`
 
 	operation := func() error {
 		_, err := r.pool.Exec(ctx, sql)
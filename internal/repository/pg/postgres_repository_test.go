@@ -0,0 +1,147 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// newTestRepository подключается к базе из TEST_DATABASE_DSN и пропускает
+// тест, если переменная не задана.
+func newTestRepository(t *testing.T) *PostgresRepository {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	ctx := context.Background()
+	r, err := NewPostgresRepository(ctx, &storage.StorageConfig{
+		ConnectionString: dsn,
+		MaxConns:         4,
+		MinConns:         1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+	require.NoError(t, r.Bootstrap(ctx))
+	return r
+}
+
+// testMetricID возвращает уникальный id, чтобы прогоны не пересекались в общей базе.
+func testMetricID(t *testing.T, name string) string {
+	return fmt.Sprintf("%s_%s_%d", t.Name(), name, time.Now().UnixNano())
+}
+
+func TestPostgresRepository_SaveOrUpdate(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	gauge := &domain.Metrics{
+		ID:     testMetricID(t, "gauge"),
+		MType:  domain.Gauge,
+		Labels: domain.Labels{"host": "a"},
+		Value:  lo.ToPtr(1.5),
+	}
+	require.NoError(t, r.SaveOrUpdate(ctx, gauge))
+	t.Cleanup(func() { _ = r.Delete(ctx, gauge.ID, gauge.MType, gauge.Labels) })
+
+	got, err := r.Metric(ctx, gauge.ID, gauge.MType, gauge.Labels)
+	require.NoError(t, err)
+	require.Equal(t, 1.5, *got.Value)
+
+	gauge.Value = lo.ToPtr(2.5)
+	require.NoError(t, r.SaveOrUpdate(ctx, gauge))
+	got, err = r.Metric(ctx, gauge.ID, gauge.MType, gauge.Labels)
+	require.NoError(t, err)
+	require.Equal(t, 2.5, *got.Value)
+
+	_, err = r.Metric(ctx, gauge.ID, gauge.MType, nil)
+	require.ErrorIs(t, err, domain.ErrMetricNotFound)
+
+	counter := &domain.Metrics{
+		ID:    testMetricID(t, "counter"),
+		MType: domain.Counter,
+		Delta: lo.ToPtr(int64(7)),
+	}
+	require.NoError(t, r.SaveOrUpdate(ctx, counter))
+	t.Cleanup(func() { _ = r.Delete(ctx, counter.ID, counter.MType, nil) })
+
+	got, err = r.Metric(ctx, counter.ID, counter.MType, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), *got.Delta)
+
+	summary := domain.NewSummary(0.01)
+	summary.Observe(3)
+	sm := &domain.Metrics{
+		ID:      testMetricID(t, "summary"),
+		MType:   domain.Summary,
+		Summary: summary,
+	}
+	require.NoError(t, r.SaveOrUpdate(ctx, sm))
+	t.Cleanup(func() { _ = r.Delete(ctx, sm.ID, sm.MType, nil) })
+
+	got, err = r.Metric(ctx, sm.ID, sm.MType, nil)
+	require.NoError(t, err)
+	require.NotNil(t, got.Summary)
+	require.Equal(t, summary.Count, got.Summary.Count)
+}
+
+func TestPostgresRepository_DeleteDropsHistory(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	m := &domain.Metrics{
+		ID:     testMetricID(t, "gauge"),
+		MType:  domain.Gauge,
+		Labels: domain.Labels{"host": "a"},
+		Value:  lo.ToPtr(1.0),
+	}
+	from := time.Now().Add(-time.Minute)
+	require.NoError(t, r.SaveOrUpdate(ctx, m))
+	require.NoError(t, r.SaveBuckets(ctx, m.SeriesID(), m.MType, time.Minute, []domain.Bucket{{Start: from, Count: 1}}))
+
+	samples, err := r.Samples(ctx, m.SeriesID(), m.MType, from, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+
+	require.NoError(t, r.Delete(ctx, m.ID, m.MType, m.Labels))
+	require.ErrorIs(t, r.Delete(ctx, m.ID, m.MType, m.Labels), domain.ErrMetricNotFound)
+
+	samples, err = r.Samples(ctx, m.SeriesID(), m.MType, from, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, samples)
+	buckets, err := r.Buckets(ctx, m.SeriesID(), m.MType, time.Minute, from, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+}
+
+func TestPostgresRepository_MetricPageMatch(t *testing.T) {
+	r := newTestRepository(t)
+	ctx := context.Background()
+
+	base := testMetricID(t, "m")
+	for _, suffix := range []string{"a", "b", "1", "c"} {
+		m := &domain.Metrics{ID: base + "_" + suffix, MType: domain.Gauge, Value: lo.ToPtr(1.0)}
+		require.NoError(t, r.SaveOrUpdate(ctx, m))
+		t.Cleanup(func() { _ = r.Delete(ctx, m.ID, m.MType, nil) })
+	}
+
+	q := domain.MetricQuery{Prefix: base, Match: `_[a-z]$`, SortBy: domain.SortByID, Limit: 2}
+	require.NoError(t, q.Validate())
+	page, err := r.MetricPage(ctx, q)
+	require.NoError(t, err)
+	require.Equal(t, []string{base + "_a", base + "_b"}, lo.Map(page, func(m domain.Metrics, _ int) string { return m.ID }))
+
+	q.After = q.Cursor(&page[len(page)-1])
+	page, err = r.MetricPage(ctx, q)
+	require.NoError(t, err)
+	require.Equal(t, []string{base + "_c"}, lo.Map(page, func(m domain.Metrics, _ int) string { return m.ID }))
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/cenkalti/backoff/v4"
@@ -15,11 +17,12 @@ import (
 	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
 )
 
-func (r *PostgresRepository) Metric(ctx context.Context, id, metricType string) (*domain.Metrics, error) {
+func (r *PostgresRepository) Metric(ctx context.Context, id, metricType string, labels domain.Labels) (*domain.Metrics, error) {
 	sqlQuery, args, err := sq.
-		Select("id", "type", "value", "delta", "hash").
+		Select("id", "type", "labels", "value", "delta", "histogram", "summary", "hash", "updated_at").
 		From("metrics").
 		Where(sq.Eq{"id": id, "type": metricType}).
+		Where(sq.Expr("labels = ?::jsonb", labelsArg(labels))).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
@@ -27,15 +30,19 @@ func (r *PostgresRepository) Metric(ctx context.Context, id, metricType string)
 	}
 
 	var (
-		gotID   string
-		gotType string
-		value   *float64
-		delta   *int64
-		hash    *string
+		gotID     string
+		gotType   string
+		gotLabels domain.Labels
+		value     *float64
+		delta     *int64
+		histogram *domain.HistogramValue
+		summary   *domain.SummaryValue
+		hash      *string
+		updated   time.Time
 	)
 
 	operation := func() error {
-		err := r.pool.QueryRow(ctx, sqlQuery, args...).Scan(&gotID, &gotType, &value, &delta, &hash)
+		err := r.pool.QueryRow(ctx, sqlQuery, args...).Scan(&gotID, &gotType, &gotLabels, &value, &delta, &histogram, &summary, &hash, &updated)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				zl.Log.Debug("metric not found", zap.String("id", id), zap.String("type", metricType))
@@ -58,10 +65,14 @@ func (r *PostgresRepository) Metric(ctx context.Context, id, metricType string)
 	}
 
 	m := &domain.Metrics{
-		ID:    gotID,
-		MType: gotType,
-		Value: value,
-		Delta: delta,
+		ID:        gotID,
+		MType:     gotType,
+		Labels:    gotLabels.Clone(),
+		Value:     value,
+		Delta:     delta,
+		Histogram: histogram,
+		Summary:   summary,
+		UpdatedAt: updated,
 	}
 	if hash != nil {
 		m.Hash = *hash
@@ -71,7 +82,7 @@ func (r *PostgresRepository) Metric(ctx context.Context, id, metricType string)
 
 func (r *PostgresRepository) MetricList(ctx context.Context) ([]domain.Metrics, error) {
 	sqlQuery, args, err := sq.
-		Select("id", "type", "value", "delta", "hash").
+		Select("id", "type", "labels", "value", "delta", "histogram", "summary", "hash", "updated_at").
 		From("metrics").
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -109,10 +120,11 @@ func (r *PostgresRepository) MetricList(ctx context.Context) ([]domain.Metrics,
 	metrics = make([]domain.Metrics, 0, count)
 	for rows.Next() {
 		var m domain.Metrics
-		err = rows.Scan(&m.ID, &m.MType, &m.Value, &m.Delta, &m.Hash)
+		err = rows.Scan(&m.ID, &m.MType, &m.Labels, &m.Value, &m.Delta, &m.Histogram, &m.Summary, &m.Hash, &m.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
+		m.Labels = m.Labels.Clone()
 		metrics = append(metrics, m)
 	}
 	return metrics, nil
@@ -120,7 +132,7 @@ func (r *PostgresRepository) MetricList(ctx context.Context) ([]domain.Metrics,
 
 func (r *PostgresRepository) MetricListByType(ctx context.Context, metricType string) ([]domain.Metrics, error) {
 	sqlQuery, args, err := sq.
-		Select("id", "type", "value", "delta", "hash").
+		Select("id", "type", "labels", "value", "delta", "histogram", "summary", "hash", "updated_at").
 		From("metrics").
 		Where(sq.Eq{"type": metricType}).
 		PlaceholderFormat(sq.Dollar).
@@ -152,11 +164,111 @@ func (r *PostgresRepository) MetricListByType(ctx context.Context, metricType st
 	var metrics []domain.Metrics
 	for rows.Next() {
 		var m domain.Metrics
-		err = rows.Scan(&m.ID, &m.MType, &m.Value, &m.Delta, &m.Hash)
+		err = rows.Scan(&m.ID, &m.MType, &m.Labels, &m.Value, &m.Delta, &m.Histogram, &m.Summary, &m.Hash, &m.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
+		m.Labels = m.Labels.Clone()
 		metrics = append(metrics, m)
 	}
 	return metrics, nil
 }
+
+// MetricPage возвращает страницу списка. Фильтр match проверен
+// MetricQuery.Validate и одинаково понимается Go и оператором ~ Postgres.
+func (r *PostgresRepository) MetricPage(ctx context.Context, q domain.MetricQuery) ([]domain.Metrics, error) {
+	keys, after := pageKeys(&q)
+	order := " ASC"
+	cmpOp := ">"
+	if q.Desc {
+		order, cmpOp = " DESC", "<"
+	}
+	orderBy := make([]string, len(keys))
+	for i, k := range keys {
+		orderBy[i] = k + order
+	}
+
+	builder := sq.
+		Select("id", "type", "labels", "value", "delta", "histogram", "summary", "hash", "updated_at").
+		From("metrics").
+		OrderBy(orderBy...).
+		Limit(uint64(q.Limit)).
+		PlaceholderFormat(sq.Dollar)
+	if q.Type != "" {
+		builder = builder.Where(sq.Eq{"type": q.Type})
+	}
+	if q.Prefix != "" {
+		builder = builder.Where(sq.Expr(`id COLLATE "C" LIKE ?`, likeEscaper.Replace(q.Prefix)+"%"))
+	}
+	if q.Match != "" {
+		builder = builder.Where(sq.Expr("id ~ ?", q.Match))
+	}
+	if q.After != nil {
+		builder = builder.Where(sq.Expr(
+			"("+strings.Join(keys, ", ")+") "+cmpOp+" ("+sq.Placeholders(len(keys))+")", after...))
+	}
+
+	sqlQuery, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := make([]domain.Metrics, 0, q.Limit)
+	for rows.Next() {
+		var m domain.Metrics
+		err = rows.Scan(&m.ID, &m.MType, &m.Labels, &m.Value, &m.Delta, &m.Histogram, &m.Summary, &m.Hash, &m.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		m.Labels = m.Labels.Clone()
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
+
+// likeEscaper экранирует спецсимволы LIKE в префиксе.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// pageKeys возвращает ключ сортировки списка и значения курсора для
+// него. Строки сравниваются побайтово (COLLATE "C"), как и в памяти,
+// поэтому порядок не зависит от локали базы и совпадает с индексом
+// idx_metrics_list.
+func pageKeys(q *domain.MetricQuery) ([]string, []any) {
+	const (
+		id     = `id COLLATE "C"`
+		series = `series_id COLLATE "C"`
+		typ    = `type COLLATE "C"`
+	)
+	var c domain.MetricCursor
+	if q.After != nil {
+		c = *q.After
+	}
+	switch q.SortBy {
+	case domain.SortByType:
+		return []string{typ, id, series}, []any{c.Type, c.ID, c.SeriesID}
+	case domain.SortByUpdatedAt:
+		return []string{"updated_at", id, series, typ}, []any{c.UpdatedAt, c.ID, c.SeriesID, c.Type}
+	default:
+		return []string{id, series, typ}, []any{c.ID, c.SeriesID, c.Type}
+	}
+}
@@ -0,0 +1,129 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
+
+	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+func (r *PostgresRepository) SaveBuckets(ctx context.Context, id, metricType string, resolution time.Duration, buckets []domain.Bucket) error {
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	b := sq.
+		Insert("metric_rollups").
+		Columns("id", "type", "resolution", "bucket_start", "min", "max", "sum", "count", "last").
+		PlaceholderFormat(sq.Dollar)
+
+	for _, bucket := range buckets {
+		b = b.Values(id, metricType, int64(resolution.Seconds()), bucket.Start, bucket.Min, bucket.Max, bucket.Sum, bucket.Count, bucket.Last)
+	}
+
+	b = b.Suffix(`
+		ON CONFLICT (id, type, resolution, bucket_start)
+		DO UPDATE SET
+			min = EXCLUDED.min,
+			max = EXCLUDED.max,
+			sum = EXCLUDED.sum,
+			count = EXCLUDED.count,
+			last = EXCLUDED.last
+	`)
+
+	sqlQuery, args, err := b.ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
+
+func (r *PostgresRepository) Buckets(ctx context.Context, id, metricType string, resolution time.Duration, from, to time.Time) ([]domain.Bucket, error) {
+	sqlQuery, args, err := sq.
+		Select("bucket_start", "min", "max", "sum", "count", "last").
+		From("metric_rollups").
+		Where(sq.Eq{"id": id, "type": metricType, "resolution": int64(resolution.Seconds())}).
+		Where(sq.GtOrEq{"bucket_start": from}).
+		Where(sq.LtOrEq{"bucket_start": to}).
+		OrderBy("bucket_start").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.Bucket
+	for rows.Next() {
+		var b domain.Bucket
+		if err := rows.Scan(&b.Start, &b.Min, &b.Max, &b.Sum, &b.Count, &b.Last); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (r *PostgresRepository) DeleteBucketsBefore(ctx context.Context, id, metricType string, resolution time.Duration, before time.Time) error {
+	sqlQuery, args, err := sq.
+		Delete("metric_rollups").
+		Where(sq.Eq{"id": id, "type": metricType, "resolution": int64(resolution.Seconds())}).
+		Where(sq.Lt{"bucket_start": before}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
@@ -0,0 +1,137 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/jackc/pgx/v5"
+
+	pgerrors "github.com/bigsm0uk/metrics-alert-server/internal/app/storage/pgerror"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+var _ interfaces.SilenceRepository = (*PostgresRepository)(nil)
+
+func (r *PostgresRepository) SaveSilence(ctx context.Context, silence *domain.Silence) error {
+	sqlQuery, args, err := sq.
+		Insert("silences").
+		Columns("id", "metric_id", "is_regex", "starts_at", "ends_at", "comment", "created_at").
+		Values(silence.ID, silence.MetricID, silence.IsRegex, silence.StartsAt, silence.EndsAt, silence.Comment, silence.CreatedAt).
+		Suffix(`
+			ON CONFLICT (id)
+			DO UPDATE SET
+				metric_id = EXCLUDED.metric_id,
+				is_regex = EXCLUDED.is_regex,
+				starts_at = EXCLUDED.starts_at,
+				ends_at = EXCLUDED.ends_at,
+				comment = EXCLUDED.comment
+		`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	operation := func() error {
+		_, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	return backoff.Retry(operation, newBackoff())
+}
+
+func (r *PostgresRepository) Silences(ctx context.Context) ([]domain.Silence, error) {
+	sqlQuery, args, err := sq.
+		Select("id", "metric_id", "is_regex", "starts_at", "ends_at", "comment", "created_at").
+		From("silences").
+		OrderBy("created_at").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	var rows pgx.Rows
+
+	operation := func() error {
+		r, err := r.pool.Query(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		rows = r
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return nil, fmt.Errorf("exec query: %w", err)
+	}
+	defer rows.Close()
+
+	var silences []domain.Silence
+	for rows.Next() {
+		var (
+			s       domain.Silence
+			comment *string
+		)
+		err = rows.Scan(&s.ID, &s.MetricID, &s.IsRegex, &s.StartsAt, &s.EndsAt, &comment, &s.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		if comment != nil {
+			s.Comment = *comment
+		}
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("silence %s: %w", s.ID, err)
+		}
+		silences = append(silences, s)
+	}
+	return silences, rows.Err()
+}
+
+func (r *PostgresRepository) DeleteSilence(ctx context.Context, id string) error {
+	sqlQuery, args, err := sq.
+		Delete("silences").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	var affected int64
+
+	operation := func() error {
+		tag, err := r.pool.Exec(ctx, sqlQuery, args...)
+		if err != nil {
+			pgErrClassifier := pgerrors.NewPostgresErrorClassifier()
+			if pgErrClassifier.Classify(err) == pgerrors.NonRetriable {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		affected = tag.RowsAffected()
+		return nil
+	}
+
+	if err := backoff.Retry(operation, newBackoff()); err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrSilenceNotFound
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/config"
 	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
@@ -14,6 +15,52 @@ func InitRepository(ctx context.Context, cfg *config.ServerConfig) (interfaces.M
 	if cfg.IsPgStoreStorage() {
 		return pg.NewPostgresRepository(ctx, &cfg.Storage)
 	} else {
-		return mem.NewMemRepository(storage.NewMemStorage()), nil
+		return mem.NewMemRepositoryWithHistory(storage.NewMemStorage(), storage.NewMemHistory(cfg.History.MaxSamples)), nil
 	}
 }
+
+// InitSilenceRepository возвращает репозиторий подавлений: при хранении метрик
+// в Postgres подавления хранятся в той же базе, иначе - в JSON файле.
+func InitSilenceRepository(cfg *config.ServerConfig, metrics interfaces.MetricsRepository) (interfaces.SilenceRepository, error) {
+	if pgRepo, ok := metrics.(*pg.PostgresRepository); ok {
+		return pgRepo, nil
+	}
+	return mem.NewSilenceRepository(cfg.Alert.SilencesFile)
+}
+
+// InitMetadataRepository возвращает репозиторий описаний метрик: при хранении
+// метрик в Postgres описания хранятся в той же базе, иначе - в JSON файле.
+func InitMetadataRepository(cfg *config.ServerConfig, metrics interfaces.MetricsRepository) (interfaces.MetadataRepository, error) {
+	if pgRepo, ok := metrics.(*pg.PostgresRepository); ok {
+		return pgRepo, nil
+	}
+	return mem.NewMetadataRepository(cfg.MetadataFile)
+}
+
+// InitIdempotencyRepository возвращает хранилище ключей идемпотентности: при
+// хранении метрик в Postgres ключи хранятся в той же базе и переживают
+// перезапуск, иначе - в памяти.
+func InitIdempotencyRepository(cfg *config.ServerConfig, metrics interfaces.MetricsRepository) interfaces.IdempotencyRepository {
+	if pgRepo, ok := metrics.(*pg.PostgresRepository); ok {
+		return pgRepo
+	}
+	return mem.NewIdempotencyRepository(cfg.Idempotency.MaxKeys)
+}
+
+// InitSampleRepository возвращает историю значений метрик того же хранилища, что и metrics.
+func InitSampleRepository(metrics interfaces.MetricsRepository) (interfaces.SampleRepository, error) {
+	samples, ok := metrics.(interfaces.SampleRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository %T does not store metric history", metrics)
+	}
+	return samples, nil
+}
+
+// InitRollupRepository возвращает хранилище агрегатов истории того же хранилища, что и metrics.
+func InitRollupRepository(metrics interfaces.MetricsRepository) (interfaces.RollupRepository, error) {
+	rollups, ok := metrics.(interfaces.RollupRepository)
+	if !ok {
+		return nil, fmt.Errorf("repository %T does not store metric rollups", metrics)
+	}
+	return rollups, nil
+}
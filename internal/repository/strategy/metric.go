@@ -21,10 +21,11 @@ func (s *CounterStrategy) Update(oldMetric, newMetric *domain.Metrics) *domain.M
 	newDelta := lo.FromPtr(newMetric.Delta)
 
 	return &domain.Metrics{
-		ID:    newMetric.ID,
-		MType: domain.Counter,
-		Delta: lo.ToPtr(oldDelta + newDelta),
-		Hash:  newMetric.Hash,
+		ID:     newMetric.ID,
+		MType:  domain.Counter,
+		Labels: newMetric.Labels,
+		Delta:  lo.ToPtr(oldDelta + newDelta),
+		Hash:   newMetric.Hash,
 	}
 }
 
@@ -34,18 +35,51 @@ type GaugeStrategy struct{}
 func (s *GaugeStrategy) Update(oldMetric, newMetric *domain.Metrics) *domain.Metrics {
 	// Gauge метрики заменяют старое значение новым
 	return &domain.Metrics{
-		ID:    newMetric.ID,
-		MType: domain.Gauge,
-		Value: newMetric.Value,
-		Hash:  newMetric.Hash,
+		ID:     newMetric.ID,
+		MType:  domain.Gauge,
+		Labels: newMetric.Labels,
+		Value:  newMetric.Value,
+		Hash:   newMetric.Hash,
+	}
+}
+
+// HistogramStrategy реализует логику обновления histogram метрик: числа
+// наблюдений по бакетам, сумма и количество складываются. Если границы
+// бакетов изменились, сохраняется новое значение (см. HistogramValue.Merge).
+type HistogramStrategy struct{}
+
+func (s *HistogramStrategy) Update(oldMetric, newMetric *domain.Metrics) *domain.Metrics {
+	return &domain.Metrics{
+		ID:        newMetric.ID,
+		MType:     domain.Histogram,
+		Labels:    newMetric.Labels,
+		Histogram: oldMetric.Histogram.Merge(newMetric.Histogram),
+		Hash:      newMetric.Hash,
+	}
+}
+
+// SummaryStrategy реализует логику обновления summary метрик: sketch
+// объединяются, поэтому агенты могут присылать как отдельные наблюдения,
+// так и частичные sketch. При смене точности сохраняется новое значение.
+type SummaryStrategy struct{}
+
+func (s *SummaryStrategy) Update(oldMetric, newMetric *domain.Metrics) *domain.Metrics {
+	return &domain.Metrics{
+		ID:      newMetric.ID,
+		MType:   domain.Summary,
+		Labels:  newMetric.Labels,
+		Summary: oldMetric.Summary.Merge(newMetric.Summary),
+		Hash:    newMetric.Hash,
 	}
 }
 
 // Singleton экземпляры стратегий - создаются один раз при инициализации пакета.
 // Поскольку стратегии не имеют состояния (stateless), они безопасны для многопоточного использования.
 var (
-	counterStrategy = &CounterStrategy{}
-	gaugeStrategy   = &GaugeStrategy{}
+	counterStrategy   = &CounterStrategy{}
+	gaugeStrategy     = &GaugeStrategy{}
+	histogramStrategy = &HistogramStrategy{}
+	summaryStrategy   = &SummaryStrategy{}
 )
 
 // StrategyFactory возвращает соответствующую стратегию для типа метрики.
@@ -56,6 +90,10 @@ func StrategyFactory(metricType string) MetricUpdateStrategy {
 		return counterStrategy
 	case domain.Gauge:
 		return gaugeStrategy
+	case domain.Histogram:
+		return histogramStrategy
+	case domain.Summary:
+		return summaryStrategy
 	default:
 		return nil
 	}
@@ -173,6 +173,89 @@ func TestGaugeStrategy_Update(t *testing.T) {
 	}
 }
 
+func TestHistogramStrategy_Update(t *testing.T) {
+	bounds := []float64{1, 5}
+	newHist := &domain.HistogramValue{Bounds: bounds, Counts: []uint64{1, 0, 2}, Count: 3, Sum: 21}
+
+	tests := []struct {
+		name     string
+		old      *domain.HistogramValue
+		expected *domain.HistogramValue
+	}{
+		{
+			name:     "новая histogram",
+			old:      nil,
+			expected: &domain.HistogramValue{Bounds: bounds, Counts: []uint64{1, 0, 2}, Count: 3, Sum: 21},
+		},
+		{
+			name:     "сложение бакетов",
+			old:      &domain.HistogramValue{Bounds: bounds, Counts: []uint64{2, 3, 0}, Count: 5, Sum: 10},
+			expected: &domain.HistogramValue{Bounds: bounds, Counts: []uint64{3, 3, 2}, Count: 8, Sum: 31},
+		},
+		{
+			name:     "смена границ сбрасывает значение",
+			old:      &domain.HistogramValue{Bounds: []float64{10}, Counts: []uint64{4, 1}, Count: 5, Sum: 60},
+			expected: &domain.HistogramValue{Bounds: bounds, Counts: []uint64{1, 0, 2}, Count: 3, Sum: 21},
+		},
+	}
+
+	strategy := &HistogramStrategy{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldMetric := &domain.Metrics{ID: "latency", MType: domain.Histogram, Histogram: tt.old}
+			newMetric := &domain.Metrics{ID: "latency", MType: domain.Histogram, Histogram: newHist, Hash: "h"}
+
+			result := strategy.Update(oldMetric, newMetric)
+
+			assert.Equal(t, &domain.Metrics{ID: "latency", MType: domain.Histogram, Histogram: tt.expected, Hash: "h"}, result)
+		})
+	}
+	assert.Equal(t, uint64(3), newHist.Count, "исходное значение не должно изменяться")
+}
+
+func TestSummaryStrategy_Update(t *testing.T) {
+	// Частичные sketch двух агентов и sketch всех наблюдений сразу
+	first, second, all := domain.NewSummary(0.01), domain.NewSummary(0.01), domain.NewSummary(0.01)
+	for i := 1; i <= 1000; i++ {
+		v := float64(i) / 10
+		if i%3 == 0 {
+			first.Observe(v)
+		} else {
+			second.Observe(v)
+		}
+		all.Observe(v)
+	}
+
+	strategy := &SummaryStrategy{}
+	result := strategy.Update(
+		&domain.Metrics{ID: "latency", MType: domain.Summary, Summary: first},
+		&domain.Metrics{ID: "latency", MType: domain.Summary, Summary: second, Hash: "h"},
+	)
+
+	assert.Equal(t, domain.Summary, result.MType)
+	assert.Equal(t, "h", result.Hash)
+	assert.Equal(t, uint64(1000), result.Summary.Count)
+	assert.InDelta(t, all.Sum, result.Summary.Sum, 1e-6)
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		assert.Equal(t, all.Quantile(q), result.Summary.Quantile(q), "объединение не должно терять точность")
+		exact := q*99.9 + 0.1
+		assert.InEpsilon(t, exact, result.Summary.Quantile(q), 0.02, "q=%v", q)
+	}
+	assert.Equal(t, 0.1, result.Summary.Quantile(0))
+	assert.Equal(t, 100.0, result.Summary.Quantile(1))
+	assert.Equal(t, uint64(333), first.Count, "исходное значение не должно изменяться")
+
+	t.Run("смена точности сбрасывает значение", func(t *testing.T) {
+		coarse := domain.NewSummary(0.05)
+		coarse.Observe(42)
+		result := strategy.Update(
+			&domain.Metrics{ID: "latency", MType: domain.Summary, Summary: first},
+			&domain.Metrics{ID: "latency", MType: domain.Summary, Summary: coarse},
+		)
+		assert.Equal(t, coarse, result.Summary)
+	})
+}
+
 func TestStrategyFactory(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -192,6 +275,18 @@ func TestStrategyFactory(t *testing.T) {
 			expectNil:    false,
 			strategyType: "*strategy.GaugeStrategy",
 		},
+		{
+			name:         "histogram strategy",
+			metricType:   domain.Histogram,
+			expectNil:    false,
+			strategyType: "*strategy.HistogramStrategy",
+		},
+		{
+			name:         "summary strategy",
+			metricType:   domain.Summary,
+			expectNil:    false,
+			strategyType: "*strategy.SummaryStrategy",
+		},
 		{
 			name:       "неизвестный тип метрики",
 			metricType: "unknown",
@@ -220,6 +315,12 @@ func TestStrategyFactory(t *testing.T) {
 				case domain.Gauge:
 					_, ok := strategy.(*GaugeStrategy)
 					assert.True(t, ok, "ожидается GaugeStrategy")
+				case domain.Histogram:
+					_, ok := strategy.(*HistogramStrategy)
+					assert.True(t, ok, "ожидается HistogramStrategy")
+				case domain.Summary:
+					_, ok := strategy.(*SummaryStrategy)
+					assert.True(t, ok, "ожидается SummaryStrategy")
 				}
 			}
 		})
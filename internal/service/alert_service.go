@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// alertQueueSize - число уведомлений, ожидающих отправки в один канал.
+const alertQueueSize = 100
+
+// AlertService периодически вычисляет правила алертинга по значениям
+// из репозитория метрик, хранит состояния алертов в памяти
+// и уведомляет каналы правила о срабатывании и восстановлении.
+// Уведомления отправляются в фоне: у каждого канала своя очередь и свой
+// обработчик, так что медленный канал не задерживает вычисление правил
+// и другие каналы.
+type AlertService struct {
+	repository interfaces.MetricsRepository
+	cfg        *alert.AlertConfig
+	rules      []domain.AlertRule
+	absence    []absenceRule
+	notifiers  map[string]interfaces.AlertNotifier
+	silences   *SilenceService
+	logger     *zap.Logger
+
+	mu     sync.RWMutex
+	alerts map[string]*domain.Alert // Ключ - имя правила
+	now    func() time.Time
+
+	queues   map[string]chan domain.AlertNotification // Ключ - имя канала
+	queueMu  sync.RWMutex
+	closed   bool
+	pending  sync.WaitGroup // Уведомления в очередях и в отправке
+	workers  sync.WaitGroup
+	sendCtx  context.Context
+	cancel   context.CancelFunc
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewAlertService создает сервис алертинга по правилам из конфигурации.
+// notifiers - каналы уведомлений по именам.
+// silences - сервис подавлений; если nil, уведомления не подавляются.
+// Возвращает ошибку, если хотя бы одно правило некорректно.
+func NewAlertService(repository interfaces.MetricsRepository, cfg *alert.AlertConfig, notifiers map[string]interfaces.AlertNotifier, silences *SilenceService, log *zap.Logger) (*AlertService, error) {
+	s := &AlertService{
+		repository: repository,
+		cfg:        cfg,
+		notifiers:  notifiers,
+		silences:   silences,
+		logger:     log.Named("alert-service"),
+		alerts:     make(map[string]*domain.Alert, len(cfg.Rules)+len(cfg.Absence)),
+		now:        time.Now,
+		queues:     make(map[string]chan domain.AlertNotification, len(notifiers)),
+		stopChan:   make(chan struct{}),
+	}
+	s.sendCtx, s.cancel = context.WithCancel(context.Background())
+
+	for _, rc := range cfg.Rules {
+		rule := domain.AlertRule{
+			Name:       rc.Name,
+			MetricID:   rc.MetricID,
+			MetricType: rc.MetricType,
+			Comparison: rc.Comparison,
+			Threshold:  rc.Threshold,
+			For:        rc.For,
+			Channels:   rc.Channels,
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.register(rule); err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, rule)
+	}
+
+	for _, ac := range cfg.Absence {
+		rule := domain.AbsenceRule{
+			Name:       ac.Name,
+			MetricID:   ac.MetricID,
+			MetricType: ac.MetricType,
+			Pattern:    ac.Pattern,
+			Timeout:    ac.Timeout,
+			Channels:   ac.Channels,
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.register(rule.AlertRule()); err != nil {
+			return nil, err
+		}
+		s.absence = append(s.absence, absenceRule{AbsenceRule: rule, alert: rule.AlertRule()})
+	}
+	for name, n := range notifiers {
+		queue := make(chan domain.AlertNotification, alertQueueSize)
+		s.queues[name] = queue
+		s.workers.Add(1)
+		go s.deliver(n, queue)
+	}
+	return s, nil
+}
+
+// register проверяет каналы и уникальность имени правила
+// и создает для него неактивный алерт.
+func (s *AlertService) register(rule domain.AlertRule) error {
+	for _, ch := range rule.Channels {
+		if _, ok := s.notifiers[ch]; !ok {
+			return fmt.Errorf("%w: rule %s: unknown channel %s", domain.ErrInvalidAlertRule, rule.Name, ch)
+		}
+	}
+	if _, ok := s.alerts[rule.Name]; ok {
+		return fmt.Errorf("%w: duplicate rule name %s", domain.ErrInvalidAlertRule, rule.Name)
+	}
+	s.alerts[rule.Name] = &domain.Alert{
+		Rule:       rule.Name,
+		MetricID:   rule.MetricID,
+		MetricType: rule.MetricType,
+		Comparison: rule.Comparison,
+		Threshold:  rule.Threshold,
+		State:      domain.AlertInactive,
+	}
+	return nil
+}
+
+// absenceRule - правило отсутствия вместе с его представлением в виде правила алертинга.
+// since - время первого вычисления правила: до него метрики не могли прийти,
+// поэтому таймаут отсчитывается не раньше этого момента.
+type absenceRule struct {
+	domain.AbsenceRule
+	alert domain.AlertRule
+	since time.Time
+}
+
+// StartProcess запускает периодическое вычисление правил.
+func (s *AlertService) StartProcess(ctx context.Context) {
+	if !s.cfg.IsEnabled() || s.cfg.EvaluationInterval <= 0 {
+		return
+	}
+	s.ticker = time.NewTicker(s.cfg.EvaluationInterval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				s.Evaluate(ctx)
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	s.logger.Info("alert evaluation started",
+		zap.Int("rules", len(s.rules)),
+		zap.Int("absence_rules", len(s.absence)),
+		zap.Duration("interval", s.cfg.EvaluationInterval),
+	)
+}
+
+// Close останавливает периодическое вычисление правил и дожидается отправки
+// уведомлений из очередей, но не дольше ctx: по его истечении текущие
+// отправки прерываются.
+func (s *AlertService) Close(ctx context.Context) error {
+	if s.ticker != nil {
+		s.ticker.Stop()
+		close(s.stopChan)
+		s.ticker = nil
+	}
+
+	s.queueMu.Lock()
+	if !s.closed {
+		s.closed = true
+		for _, queue := range s.queues {
+			close(queue)
+		}
+	}
+	s.queueMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Evaluate однократно вычисляет все правила (включая правила отсутствия),
+// обновляет состояния алертов и отправляет уведомления о переходах в firing и resolved.
+func (s *AlertService) Evaluate(ctx context.Context) {
+	var notifications []notification
+
+	for i := range s.rules {
+		rule := &s.rules[i]
+
+		var value *float64
+		m, err := s.repository.Metric(ctx, rule.MetricID, rule.MetricType, nil)
+		switch {
+		case err == nil:
+			if v, ok := m.NumericValue(); ok {
+				value = &v
+			}
+		case errors.Is(err, domain.ErrMetricNotFound):
+		default:
+			s.logger.Error("failed to get metric for rule",
+				zap.String("rule", rule.Name),
+				zap.String("id", rule.MetricID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		active := value != nil && rule.Matches(*value)
+		if n, ok := s.apply(rule, active, value); ok {
+			notifications = append(notifications, n)
+		}
+	}
+
+	notifications = append(notifications, s.evaluateAbsence(ctx)...)
+
+	for _, n := range notifications {
+		s.notify(ctx, n.rule, n.message)
+	}
+}
+
+// evaluateAbsence вычисляет правила отсутствия по времени последнего обновления метрик.
+func (s *AlertService) evaluateAbsence(ctx context.Context) []notification {
+	if len(s.absence) == 0 {
+		return nil
+	}
+	metrics, err := s.repository.MetricList(ctx)
+	if err != nil {
+		s.logger.Error("failed to list metrics for absence rules", zap.Error(err))
+		return nil
+	}
+
+	var notifications []notification
+	now := s.now()
+	for i := range s.absence {
+		rule := &s.absence[i]
+
+		// Значение - количество секунд с последнего обновления;
+		// если метрики нет совсем, значение не задано. Таймаут отсчитывается
+		// от последнего обновления, но не раньше первого вычисления правила,
+		// чтобы после рестарта сервера агенты успели прислать метрики.
+		if rule.since.IsZero() {
+			rule.since = now
+		}
+		var value *float64
+		from := rule.since
+		last, found := rule.LastUpdate(metrics)
+		if found {
+			age := now.Sub(last).Seconds()
+			value = &age
+			if last.After(from) {
+				from = last
+			}
+		}
+		active := now.Sub(from) > rule.Timeout
+		if n, ok := s.apply(&rule.alert, active, value); ok {
+			if rule.Pattern != "" {
+				n.message.MetricIDs = rule.MatchingIDs(metrics)
+			}
+			notifications = append(notifications, n)
+		}
+	}
+	return notifications
+}
+
+// apply переводит алерт правила в следующее состояние и возвращает уведомление,
+// если алерт перешел в firing или resolved и у правила есть каналы.
+func (s *AlertService) apply(rule *domain.AlertRule, active bool, value *float64) (notification, bool) {
+	now := s.now()
+	s.mu.Lock()
+	changed := s.transition(rule, s.alerts[rule.Name], active, value, now)
+	s.mu.Unlock()
+
+	if changed == "" || len(rule.Channels) == 0 {
+		return notification{}, false
+	}
+	return notification{
+		rule: rule,
+		message: domain.AlertNotification{
+			TS:         now.Unix(),
+			Status:     changed,
+			Rule:       rule.Name,
+			MetricID:   rule.MetricID,
+			MetricType: rule.MetricType,
+			Comparison: rule.Comparison,
+			Threshold:  rule.Threshold,
+			Value:      value,
+		},
+	}, true
+}
+
+// notification - уведомление, ожидающее отправки в каналы правила.
+type notification struct {
+	rule    *domain.AlertRule
+	message domain.AlertNotification
+}
+
+// notify ставит уведомление в очереди всех каналов правила, если подавлены
+// не все метрики, к которым оно относится. Если очередь канала заполнена,
+// уведомление в этот канал отбрасывается.
+func (s *AlertService) notify(ctx context.Context, rule *domain.AlertRule, message domain.AlertNotification) {
+	if s.silences != nil {
+		ids := message.AffectedIDs()
+		silenced, err := s.silences.AllSilenced(ctx, ids)
+		if err != nil {
+			s.logger.Error("failed to check silences", zap.String("rule", rule.Name), zap.Error(err))
+		}
+		if silenced {
+			s.logger.Info("alert notification silenced",
+				zap.String("rule", rule.Name),
+				zap.Strings("ids", ids),
+				zap.String("status", message.Status),
+			)
+			return
+		}
+	}
+
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	if s.closed {
+		return
+	}
+	for _, ch := range rule.Channels {
+		s.pending.Add(1)
+		select {
+		case s.queues[ch] <- message:
+		default:
+			s.pending.Done()
+			s.logger.Error("alert notification queue is full, dropping notification",
+				zap.String("rule", rule.Name),
+				zap.String("channel", ch),
+				zap.String("status", message.Status),
+			)
+		}
+	}
+}
+
+// deliver отправляет уведомления из очереди канала, пока очередь не закрыта.
+func (s *AlertService) deliver(n interfaces.AlertNotifier, queue <-chan domain.AlertNotification) {
+	defer s.workers.Done()
+	for message := range queue {
+		s.send(n, message)
+		s.pending.Done()
+	}
+}
+
+// send отправляет уведомление в канал, ограничивая отправку NotifyTimeout.
+func (s *AlertService) send(n interfaces.AlertNotifier, message domain.AlertNotification) {
+	ctx := s.sendCtx
+	if s.cfg.NotifyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.NotifyTimeout)
+		defer cancel()
+	}
+	if err := n.Notify(ctx, message); err != nil {
+		s.logger.Error("failed to send alert notification",
+			zap.String("rule", message.Rule),
+			zap.String("channel", n.GetID()),
+			zap.Error(err),
+		)
+	}
+}
+
+// transition переводит алерт в следующее состояние:
+// inactive/resolved -> pending -> firing -> resolved.
+// Возвращает новое состояние, если алерт перешел в firing или resolved, иначе пустую строку.
+func (s *AlertService) transition(rule *domain.AlertRule, a *domain.Alert, active bool, value *float64, now time.Time) string {
+	a.Value = value
+
+	if !active {
+		switch a.State {
+		case domain.AlertPending:
+			a.State = domain.AlertInactive
+			a.ActiveAt = nil
+		case domain.AlertFiring:
+			a.State = domain.AlertResolved
+			a.ResolvedAt = &now
+			s.logger.Info("alert resolved", zap.String("rule", rule.Name))
+			return domain.AlertResolved
+		}
+		return ""
+	}
+
+	switch a.State {
+	case domain.AlertInactive, domain.AlertResolved:
+		a.State = domain.AlertPending
+		a.ActiveAt = &now
+		a.FiredAt = nil
+		a.ResolvedAt = nil
+	}
+	if a.State == domain.AlertPending && now.Sub(*a.ActiveAt) >= rule.For {
+		a.State = domain.AlertFiring
+		a.FiredAt = &now
+		s.logger.Warn("alert firing",
+			zap.String("rule", rule.Name),
+			zap.String("id", rule.MetricID),
+			zap.Float64p("value", value),
+		)
+		return domain.AlertFiring
+	}
+	return ""
+}
+
+// Alerts возвращает алерты в состояниях pending, firing и resolved,
+// отсортированные по имени правила.
+func (s *AlertService) Alerts() []domain.Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]domain.Alert, 0, len(s.alerts))
+	for _, a := range s.alerts {
+		if a.State == domain.AlertInactive {
+			continue
+		}
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Rule < result[j].Rule
+	})
+	return result
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestAlertService_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	cfg := &alert.AlertConfig{
+		EvaluationInterval: time.Second,
+		Rules: []alert.RuleConfig{
+			{
+				Name:       "high_alloc",
+				MetricID:   "Alloc",
+				MetricType: domain.Gauge,
+				Comparison: domain.CompareGreater,
+				Threshold:  100,
+				For:        time.Minute,
+			},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, nil, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	setAlloc := func(v float64) {
+		require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(v)}))
+	}
+
+	// Метрики нет - алертов нет
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+
+	// Условие выполнилось - pending
+	setAlloc(150)
+	evaluate(ctx, s)
+	alerts := s.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, domain.AlertPending, alerts[0].State)
+	assert.Equal(t, 150.0, *alerts[0].Value)
+
+	// Прошло меньше For - все еще pending
+	now = now.Add(30 * time.Second)
+	evaluate(ctx, s)
+	assert.Equal(t, domain.AlertPending, s.Alerts()[0].State)
+
+	// Прошло For - firing
+	now = now.Add(30 * time.Second)
+	evaluate(ctx, s)
+	alerts = s.Alerts()
+	assert.Equal(t, domain.AlertFiring, alerts[0].State)
+	require.NotNil(t, alerts[0].FiredAt)
+	assert.Equal(t, now, *alerts[0].FiredAt)
+
+	// Условие перестало выполняться - resolved
+	setAlloc(50)
+	now = now.Add(10 * time.Second)
+	evaluate(ctx, s)
+	alerts = s.Alerts()
+	assert.Equal(t, domain.AlertResolved, alerts[0].State)
+	require.NotNil(t, alerts[0].ResolvedAt)
+
+	// Pending без срабатывания сбрасывается в inactive
+	setAlloc(200)
+	evaluate(ctx, s)
+	assert.Equal(t, domain.AlertPending, s.Alerts()[0].State)
+	setAlloc(10)
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+}
+
+// evaluate вычисляет правила и дожидается отправки уведомлений.
+func evaluate(ctx context.Context, s *AlertService) {
+	s.Evaluate(ctx)
+	s.pending.Wait()
+}
+
+type fakeNotifier struct {
+	messages []domain.AlertNotification
+}
+
+func (n *fakeNotifier) GetID() string {
+	return "fake"
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, message domain.AlertNotification) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestAlertService_Notify(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	notifier := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		Rules: []alert.RuleConfig{
+			{
+				Name:       "poll_count",
+				MetricID:   "PollCount",
+				MetricType: domain.Counter,
+				Comparison: domain.CompareGreaterEqual,
+				Threshold:  10,
+				Channels:   []string{"ops"},
+			},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"ops": notifier}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	// Превышение порога при For = 0 сразу переводит алерт в firing
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(10))}))
+	evaluate(ctx, s)
+	evaluate(ctx, s)
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, domain.AlertFiring, notifier.messages[0].Status)
+	assert.Equal(t, 10.0, *notifier.messages[0].Value)
+
+	// Восстановление
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(1))}))
+	evaluate(ctx, s)
+	require.Len(t, notifier.messages, 2)
+	assert.Equal(t, domain.AlertResolved, notifier.messages[1].Status)
+}
+
+// blockingNotifier ждет отмены контекста отправки.
+type blockingNotifier struct {
+	errs chan error
+}
+
+func (n *blockingNotifier) GetID() string {
+	return "blocking"
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, _ domain.AlertNotification) error {
+	<-ctx.Done()
+	n.errs <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestAlertService_NotifyAsync(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	slow := &blockingNotifier{errs: make(chan error, 2)}
+	fast := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		NotifyTimeout: 100 * time.Millisecond,
+		Rules: []alert.RuleConfig{
+			{
+				Name:       "poll_count",
+				MetricID:   "PollCount",
+				MetricType: domain.Counter,
+				Comparison: domain.CompareGreaterEqual,
+				Threshold:  10,
+				Channels:   []string{"slow", "fast"},
+			},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"slow": slow, "fast": fast}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	// Вычисление правил не ждет отправки в медленный канал
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(10))}))
+	start := time.Now()
+	s.Evaluate(ctx)
+	assert.Less(t, time.Since(start), cfg.NotifyTimeout)
+
+	// Отправка прерывается по таймауту канала, быстрый канал получает уведомление
+	assert.ErrorIs(t, <-slow.errs, context.DeadlineExceeded)
+	s.pending.Wait()
+	require.Len(t, fast.messages, 1)
+
+	// Close прерывает отправку, если не дождался ее в пределах ctx
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(-15))}))
+	s.Evaluate(ctx)
+	closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Close(closeCtx), context.DeadlineExceeded)
+	assert.ErrorIs(t, <-slow.errs, context.Canceled)
+}
+
+func TestNewAlertService_InvalidRules(t *testing.T) {
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+
+	tests := []struct {
+		name    string
+		rules   []alert.RuleConfig
+		absence []alert.AbsenceRuleConfig
+	}{
+		{
+			name:  "unknown comparison",
+			rules: []alert.RuleConfig{{Name: "r", MetricID: "Alloc", MetricType: domain.Gauge, Comparison: "~"}},
+		},
+		{
+			name:  "invalid metric type",
+			rules: []alert.RuleConfig{{Name: "r", MetricID: "Alloc", MetricType: "histogram", Comparison: ">"}},
+		},
+		{
+			name:  "unknown channel",
+			rules: []alert.RuleConfig{{Name: "r", MetricID: "Alloc", MetricType: domain.Gauge, Comparison: ">", Channels: []string{"ops"}}},
+		},
+		{
+			name: "duplicate name",
+			rules: []alert.RuleConfig{
+				{Name: "r", MetricID: "Alloc", MetricType: domain.Gauge, Comparison: ">"},
+				{Name: "r", MetricID: "Sys", MetricType: domain.Gauge, Comparison: ">"},
+			},
+		},
+		{
+			name:    "absence without timeout",
+			absence: []alert.AbsenceRuleConfig{{Name: "a", MetricID: "Alloc"}},
+		},
+		{
+			name:    "absence with metric id and pattern",
+			absence: []alert.AbsenceRuleConfig{{Name: "a", MetricID: "Alloc", Pattern: ".*", Timeout: time.Minute}},
+		},
+		{
+			name:    "absence with invalid pattern",
+			absence: []alert.AbsenceRuleConfig{{Name: "a", Pattern: "(", Timeout: time.Minute}},
+		},
+		{
+			name:    "absence duplicates rule name",
+			rules:   []alert.RuleConfig{{Name: "r", MetricID: "Alloc", MetricType: domain.Gauge, Comparison: ">"}},
+			absence: []alert.AbsenceRuleConfig{{Name: "r", MetricID: "Alloc", Timeout: time.Minute}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAlertService(repo, &alert.AlertConfig{Rules: tt.rules, Absence: tt.absence}, nil, nil, zap.NewNop())
+			assert.ErrorIs(t, err, domain.ErrInvalidAlertRule)
+		})
+	}
+}
+
+func TestAlertService_Absence(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	notifier := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		Absence: []alert.AbsenceRuleConfig{
+			{Name: "agent_down", Pattern: "^(Alloc|PollCount)$", Timeout: time.Minute, Channels: []string{"ops"}},
+			{Name: "random_absent", MetricID: "RandomValue", Timeout: time.Minute},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"ops": notifier}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(1.0)}))
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(1))}))
+
+	// Метрики агента свежие, RandomValue не приходила ни разу,
+	// но таймаут с первого вычисления правил еще не истек
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+
+	now = now.Add(30 * time.Second)
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+
+	// Агент перестал присылать метрики, RandomValue так и не пришла
+	now = now.Add(90 * time.Second)
+	evaluate(ctx, s)
+	alerts := s.Alerts()
+	require.Len(t, alerts, 2)
+	for _, a := range alerts {
+		assert.Equal(t, domain.AlertFiring, a.State)
+	}
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, domain.AlertFiring, notifier.messages[0].Status)
+	assert.Equal(t, domain.CompareAbsent, notifier.messages[0].Comparison)
+	assert.Equal(t, 60.0, notifier.messages[0].Threshold)
+	assert.Greater(t, *notifier.messages[0].Value, 60.0)
+
+	// Достаточно одной обновленной метрики набора, чтобы агент считался живым
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(2.0)}))
+	now = time.Now()
+	evaluate(ctx, s)
+	require.Len(t, notifier.messages, 2)
+	assert.Equal(t, domain.AlertResolved, notifier.messages[1].Status)
+}
+
+func TestAlertService_AbsenceAfterStartup(t *testing.T) {
+	ctx := context.Background()
+	// Пустое хранилище, как сразу после рестарта сервера
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	notifier := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		Absence: []alert.AbsenceRuleConfig{
+			{Name: "agent_down", MetricID: "PollCount", MetricType: domain.Counter, Timeout: time.Minute, Channels: []string{"ops"}},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"ops": notifier}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+	assert.Empty(t, notifier.messages)
+
+	// Агент отчитался в пределах таймаута - алерта нет
+	now = now.Add(50 * time.Second)
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(1))}))
+	evaluate(ctx, s)
+	assert.Empty(t, s.Alerts())
+	assert.Empty(t, notifier.messages)
+}
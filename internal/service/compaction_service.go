@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/history"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// retentionPolicy - сроки хранения истории для метрик, подходящих под pattern.
+type retentionPolicy struct {
+	pattern *regexp.Regexp // nil у политики по умолчанию
+	raw     time.Duration
+	minute  time.Duration
+	hour    time.Duration
+}
+
+// CompactionService периодически агрегирует сырые сэмплы в интервалы 1m,
+// интервалы 1m - в интервалы 1h, и удаляет данные старше сроков хранения.
+// Обрабатываются только завершенные интервалы; границы уже обработанных
+// интервалов хранятся в watermarks.
+type CompactionService struct {
+	metrics  interfaces.MetricsRepository
+	samples  interfaces.SampleRepository
+	rollups  interfaces.RollupRepository
+	cfg      *history.HistoryConfig
+	policies []retentionPolicy
+	defaults retentionPolicy
+	logger   *zap.Logger
+	now      func() time.Time
+
+	watermarks map[time.Duration]time.Time // Ключ - разрешение агрегатов
+
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewCompactionService создает сервис компакции истории.
+// Возвращает ошибку, если политика хранения некорректна.
+func NewCompactionService(metrics interfaces.MetricsRepository, samples interfaces.SampleRepository, rollups interfaces.RollupRepository, cfg *history.HistoryConfig, log *zap.Logger) (*CompactionService, error) {
+	s := &CompactionService{
+		metrics:    metrics,
+		samples:    samples,
+		rollups:    rollups,
+		cfg:        cfg,
+		logger:     log.Named("compaction-service"),
+		now:        time.Now,
+		watermarks: make(map[time.Duration]time.Time, 2),
+		stopChan:   make(chan struct{}),
+	}
+
+	s.defaults = retentionPolicy{raw: cfg.Retention, minute: cfg.MinuteRetention, hour: cfg.HourRetention}
+	if err := s.defaults.validate(); err != nil {
+		return nil, err
+	}
+	for _, pc := range cfg.Policies {
+		re, err := regexp.Compile(pc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("history policy %q: %w", pc.Pattern, err)
+		}
+		p := retentionPolicy{pattern: re, raw: pc.Retention, minute: pc.MinuteRetention, hour: pc.HourRetention}
+		if p.raw == 0 {
+			p.raw = s.defaults.raw
+		}
+		if p.minute == 0 {
+			p.minute = s.defaults.minute
+		}
+		if p.hour == 0 {
+			p.hour = s.defaults.hour
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("history policy %q: %w", pc.Pattern, err)
+		}
+		s.policies = append(s.policies, p)
+	}
+	return s, nil
+}
+
+// validate проверяет, что данные хранятся не меньше интервала следующего уровня
+// агрегации, иначе они будут удалены до компакции.
+func (p *retentionPolicy) validate() error {
+	if p.raw > 0 && p.raw < domain.RollupMinute {
+		return errors.New("raw retention must be at least 1m")
+	}
+	if p.minute > 0 && p.minute < domain.RollupHour {
+		return errors.New("minute retention must be at least 1h")
+	}
+	return nil
+}
+
+// policyFor возвращает первую политику, подходящую под id метрики (без
+// лейблов), или политику по умолчанию.
+func (s *CompactionService) policyFor(id string) retentionPolicy {
+	for _, p := range s.policies {
+		if p.pattern.MatchString(id) {
+			return p
+		}
+	}
+	return s.defaults
+}
+
+// StartProcess запускает периодическую компакцию.
+func (s *CompactionService) StartProcess(ctx context.Context) {
+	if !s.cfg.IsEnabled() {
+		return
+	}
+	s.startPeriodicCompaction(ctx)
+	s.logger.Info("history compaction started",
+		zap.Duration("interval", s.cfg.CompactionInterval),
+		zap.Int("policies", len(s.policies)),
+	)
+}
+
+func (s *CompactionService) startPeriodicCompaction(ctx context.Context) {
+	s.ticker = time.NewTicker(s.cfg.CompactionInterval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				if err := s.Compact(ctx); err != nil {
+					s.logger.Error("failed to compact history", zap.Error(err))
+				}
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close останавливает периодическую компакцию.
+func (s *CompactionService) Close() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+		close(s.stopChan)
+		s.ticker = nil
+	}
+}
+
+// Compact однократно агрегирует завершенные интервалы всех серий
+// и удаляет данные старше сроков хранения их политик.
+func (s *CompactionService) Compact(ctx context.Context) error {
+	metrics, err := s.metrics.MetricList(ctx)
+	if err != nil {
+		return fmt.Errorf("list metrics: %w", err)
+	}
+
+	now := s.now()
+	minuteFrom, minuteTo := s.window(domain.RollupMinute, s.maxRetention(func(p retentionPolicy) time.Duration { return p.raw }), now)
+	hourFrom, hourTo := s.window(domain.RollupHour, s.maxRetention(func(p retentionPolicy) time.Duration { return p.minute }), now)
+
+	var errs []error
+	for _, m := range metrics {
+		// Политики сопоставляются с id метрики, а хранилище адресуется по серии:
+		// шаблон ^PollCount$ относится и к PollCount{host="a"}
+		series := m.SeriesID()
+		if err := s.compactSeries(ctx, series, m.MType, s.policyFor(m.ID), minuteFrom, minuteTo, hourFrom, hourTo, now); err != nil {
+			errs = append(errs, fmt.Errorf("series %s %s: %w", m.MType, series, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	s.watermarks[domain.RollupMinute] = minuteTo
+	s.watermarks[domain.RollupHour] = hourTo
+	s.logger.Debug("history compacted", zap.Int("series", len(metrics)), zap.Time("minute_watermark", minuteTo))
+	return nil
+}
+
+// window возвращает интервал [from, to) завершенных, еще не агрегированных интервалов
+// длиной resolution. При первом запуске начинает с первого полного интервала
+// в пределах срока хранения исходных данных.
+func (s *CompactionService) window(resolution, sourceRetention time.Duration, now time.Time) (time.Time, time.Time) {
+	to := now.Truncate(resolution)
+	from, ok := s.watermarks[resolution]
+	if !ok {
+		from = now.Add(-sourceRetention).Truncate(resolution).Add(resolution)
+	}
+	return from, to
+}
+
+func (s *CompactionService) maxRetention(get func(retentionPolicy) time.Duration) time.Duration {
+	result := get(s.defaults)
+	for _, p := range s.policies {
+		result = max(result, get(p))
+	}
+	return result
+}
+
+func (s *CompactionService) compactSeries(ctx context.Context, id, mType string, p retentionPolicy, minuteFrom, minuteTo, hourFrom, hourTo, now time.Time) error {
+	if minuteFrom.Before(minuteTo) {
+		samples, err := s.samples.Samples(ctx, id, mType, minuteFrom, minuteTo.Add(-time.Nanosecond))
+		if err != nil {
+			return err
+		}
+		if err := s.rollups.SaveBuckets(ctx, id, mType, domain.RollupMinute, domain.Downsample(samples, domain.RollupMinute)); err != nil {
+			return err
+		}
+	}
+	if hourFrom.Before(hourTo) {
+		buckets, err := s.rollups.Buckets(ctx, id, mType, domain.RollupMinute, hourFrom, hourTo.Add(-time.Nanosecond))
+		if err != nil {
+			return err
+		}
+		if err := s.rollups.SaveBuckets(ctx, id, mType, domain.RollupHour, domain.MergeBuckets(buckets, domain.RollupHour)); err != nil {
+			return err
+		}
+	}
+
+	if p.raw > 0 {
+		if err := s.samples.DeleteSamplesBefore(ctx, id, mType, now.Add(-p.raw)); err != nil {
+			return err
+		}
+	}
+	if p.minute > 0 {
+		if err := s.rollups.DeleteBucketsBefore(ctx, id, mType, domain.RollupMinute, now.Add(-p.minute)); err != nil {
+			return err
+		}
+	}
+	if p.hour > 0 {
+		if err := s.rollups.DeleteBucketsBefore(ctx, id, mType, domain.RollupHour, now.Add(-p.hour)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/history"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestCompactionService_Compact(t *testing.T) {
+	ctx := context.Background()
+	st := storage.NewMemStorage()
+	samples := storage.NewMemHistory(0)
+	repo := mem.NewMemRepositoryWithHistory(st, samples)
+	cfg := &history.HistoryConfig{
+		Retention:       10 * time.Minute,
+		MinuteRetention: 2 * time.Hour,
+		HourRetention:   24 * time.Hour,
+		Policies: []history.PolicyConfig{
+			{Pattern: "^RandomValue$", Retention: time.Minute},
+		},
+	}
+	s, err := NewCompactionService(repo, repo, repo, cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	// Сэмплы с 10:00 до 10:03 каждые 20 секунд: значения 0..9
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, id := range []string{"Alloc", "RandomValue"} {
+		st.Set(domain.Metrics{ID: id, MType: domain.Gauge, Value: lo.ToPtr(9.0)})
+		for i := range 10 {
+			samples.Append(id, domain.Gauge, domain.Sample{TS: base.Add(time.Duration(i) * 20 * time.Second), Value: float64(i)})
+		}
+	}
+
+	labeled := domain.Metrics{ID: "RandomValue", MType: domain.Gauge, Labels: domain.Labels{"host": "a"}, Value: lo.ToPtr(9.0)}
+	st.Set(labeled)
+	for i := range 10 {
+		samples.Append(labeled.SeriesID(), domain.Gauge, domain.Sample{TS: base.Add(time.Duration(i) * 20 * time.Second), Value: float64(i)})
+	}
+
+	s.now = func() time.Time { return base.Add(3*time.Minute + 30*time.Second) }
+	require.NoError(t, s.Compact(ctx))
+
+	// Три завершенных интервала 1m, незавершенный 10:03 не агрегируется
+	buckets, err := repo.Buckets(ctx, "Alloc", domain.Gauge, domain.RollupMinute, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+	assert.Equal(t, domain.Bucket{Start: base, Min: 0, Max: 2, Sum: 3, Count: 3, Last: 2}, buckets[0])
+	assert.Equal(t, 4.0, buckets[1].Avg())
+	assert.Equal(t, 8.0, buckets[2].Last)
+
+	// Сырые данные RandomValue старше 1m (до 10:02:30) удалены по политике, Alloc - сохранены
+	raw, err := repo.Samples(ctx, "RandomValue", domain.Gauge, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, raw, 2)
+	raw, err = repo.Samples(ctx, "Alloc", domain.Gauge, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, raw, 10)
+	// Политика выбирается по id метрики, поэтому действует и на серии с лейблами
+	raw, err = repo.Samples(ctx, labeled.SeriesID(), domain.Gauge, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, raw, 2)
+
+	// Через час интервал 10:00 агрегируется в 1h, сырые данные удалены
+	s.now = func() time.Time { return base.Add(time.Hour + time.Minute) }
+	require.NoError(t, s.Compact(ctx))
+	buckets, err = repo.Buckets(ctx, "Alloc", domain.Gauge, domain.RollupHour, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, domain.Bucket{Start: base, Min: 0, Max: 9, Sum: 45, Count: 10, Last: 9}, buckets[0])
+	raw, err = repo.Samples(ctx, "Alloc", domain.Gauge, base, base.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, raw)
+}
+
+func TestNewCompactionService_InvalidPolicy(t *testing.T) {
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+
+	_, err := NewCompactionService(repo, repo, repo, &history.HistoryConfig{Policies: []history.PolicyConfig{{Pattern: "("}}}, zap.NewNop())
+	assert.Error(t, err)
+	_, err = NewCompactionService(repo, repo, repo, &history.HistoryConfig{Retention: time.Second}, zap.NewNop())
+	assert.Error(t, err)
+}
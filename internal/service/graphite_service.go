@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/ingest"
+	ingestBatch "github.com/bigsm0uk/metrics-alert-server/internal/app/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/graphite"
+)
+
+// maxGraphiteLineSize ограничивает длину строки протокола.
+const maxGraphiteLineSize = 64 * 1024
+
+// GraphiteService принимает plaintext-протокол Graphite по TCP и сохраняет
+// каждый path как gauge. Значения копятся в общем батче и пишутся через
+// MetricService, когда батч достигает BatchSize или по таймеру FlushInterval.
+type GraphiteService struct {
+	metrics *MetricService
+	cfg     *ingest.GraphiteConfig
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	pending *ingestBatch.Batch
+	conns   map[net.Conn]struct{}
+
+	listener net.Listener
+	wg       sync.WaitGroup
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewGraphiteService создает сервис приема Graphite.
+func NewGraphiteService(metrics *MetricService, cfg *ingest.GraphiteConfig, log *zap.Logger) *GraphiteService {
+	return &GraphiteService{
+		metrics:  metrics,
+		cfg:      cfg,
+		logger:   log.Named("graphite-service"),
+		pending:  ingestBatch.NewBatch(),
+		conns:    make(map[net.Conn]struct{}),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// StartProcess открывает TCP-сокет и запускает прием соединений и периодическую запись.
+// Если listener отключен в конфигурации, ничего не делает.
+func (s *GraphiteService) StartProcess(ctx context.Context) error {
+	if !s.cfg.IsEnabled() {
+		return nil
+	}
+	l, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = l
+
+	s.wg.Add(1)
+	go s.acceptLoop(ctx)
+	s.startPeriodicFlush(ctx)
+
+	s.logger.Info("graphite listener started",
+		zap.String("addr", l.Addr().String()),
+		zap.Int("batch_size", s.cfg.BatchSize),
+		zap.Duration("flush_interval", s.cfg.FlushInterval),
+	)
+	return nil
+}
+
+// Addr возвращает фактический адрес сокета (nil, если listener не запущен).
+func (s *GraphiteService) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *GraphiteService) acceptLoop(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Warn("failed to accept graphite connection", zap.Error(err))
+			continue
+		}
+		// Close закрывает stopChan до того, как под s.mu закрыть открытые
+		// соединения: принятое после этого соединение закрывается здесь
+		s.mu.Lock()
+		select {
+		case <-s.stopChan:
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		default:
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(ctx, conn)
+	}
+}
+
+func (s *GraphiteService) serveConn(ctx context.Context, conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 4096), maxGraphiteLineSize)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		p, err := graphite.ParseLine(line, time.Now())
+		if err != nil {
+			s.logger.Debug("invalid graphite line", zap.String("line", line), zap.Error(err))
+			continue
+		}
+		if s.add(p) {
+			if err := s.Flush(ctx); err != nil {
+				s.logger.Error("failed to flush graphite metrics", zap.Error(err))
+			}
+		}
+	}
+	if err := sc.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		s.logger.Warn("graphite connection closed with error",
+			zap.String("remote", conn.RemoteAddr().String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// add добавляет точку в батч и сообщает, заполнен ли он.
+func (s *GraphiteService) add(p graphite.Point) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending.AddGauge(p.ID, p.Labels, p.Value, p.TS)
+	return s.cfg.BatchSize > 0 && s.pending.Len() >= s.cfg.BatchSize
+}
+
+func (s *GraphiteService) startPeriodicFlush(ctx context.Context) {
+	s.ticker = time.NewTicker(s.cfg.FlushInterval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				if err := s.Flush(ctx); err != nil {
+					s.logger.Error("failed to flush graphite metrics", zap.Error(err))
+				}
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Flush записывает накопленный батч.
+func (s *GraphiteService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = ingestBatch.NewBatch()
+	s.mu.Unlock()
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	if err := s.metrics.SaveOrUpdateMetricsBatch(ctx, batch.Metrics()); err != nil {
+		return err
+	}
+	s.logger.Debug("graphite metrics flushed", zap.Int("count", batch.Len()))
+	return nil
+}
+
+// Close перестает принимать соединения, закрывает открытые,
+// дожидается их обработчиков и записывает остаток батча. Если обработчики
+// не завершились до истечения ctx, возвращает ошибку ctx без записи.
+func (s *GraphiteService) Close(ctx context.Context) error {
+	if s.listener == nil {
+		return nil
+	}
+	s.ticker.Stop()
+	close(s.stopChan)
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	s.listener = nil
+	select {
+	case <-done:
+		return errors.Join(err, s.Flush(ctx))
+	case <-ctx.Done():
+		return errors.Join(err, ctx.Err())
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestGraphiteService(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	ms := NewService(repo, nil)
+	s := NewGraphiteService(ms, &ingest.GraphiteConfig{Addr: "127.0.0.1:0", BatchSize: 2, FlushInterval: time.Hour}, zap.NewNop())
+	require.NoError(t, s.StartProcess(ctx))
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("a.b 1 -1\nbroken\na.c 2 -1\n"))
+	require.NoError(t, err)
+
+	// Батч из двух точек пишется, не дожидаясь таймера.
+	require.Eventually(t, func() bool {
+		_, err := ms.GetMetric(ctx, "a.c", domain.Gauge, nil)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// Неполный батч дописывается при закрытии, открытое соединение закрывается сервером.
+	_, err = conn.Write([]byte("a.d 3 -1\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.pending.Len() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Close(ctx))
+
+	m, err := ms.GetMetric(ctx, "a.d", domain.Gauge, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, *m.Value)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+}
+
+// blockingBatchRepository сообщает о записи батча в started и блокирует ее
+// до закрытия release.
+type blockingBatchRepository struct {
+	*mem.MemRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingBatchRepository) SaveOrUpdateBatch(ctx context.Context, metrics []*domain.Metrics) error {
+	r.started <- struct{}{}
+	<-r.release
+	return r.MemRepository.SaveOrUpdateBatch(ctx, metrics)
+}
+
+func TestGraphiteService_CloseTimeout(t *testing.T) {
+	ctx := context.Background()
+	repo := &blockingBatchRepository{MemRepository: mem.NewMemRepository(storage.NewMemStorage()), started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(repo.release)
+	s := NewGraphiteService(NewService(repo, nil), &ingest.GraphiteConfig{Addr: "127.0.0.1:0", BatchSize: 1, FlushInterval: time.Hour}, zap.NewNop())
+	require.NoError(t, s.StartProcess(ctx))
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("a.b 1 -1\n"))
+	require.NoError(t, err)
+	<-repo.started
+
+	// Обработчик соединения завис на записи батча - Close не ждет дольше ctx
+	closeCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Close(closeCtx), context.DeadlineExceeded)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// HistoryService предоставляет доступ к истории значений метрик.
+// Удалением устаревших сэмплов занимается CompactionService.
+type HistoryService struct {
+	repository interfaces.SampleRepository
+	logger     *zap.Logger
+}
+
+// NewHistoryService создает сервис истории метрик.
+func NewHistoryService(repository interfaces.SampleRepository, log *zap.Logger) *HistoryService {
+	return &HistoryService{
+		repository: repository,
+		logger:     log.Named("history-service"),
+	}
+}
+
+// Samples возвращает сэмплы серии в интервале [from, to], упорядоченные по времени.
+func (s *HistoryService) Samples(ctx context.Context, id, metricType string, from, to time.Time) ([]domain.Sample, error) {
+	samples, err := s.repository.Samples(ctx, id, metricType, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		samples = []domain.Sample{}
+	}
+	return samples, nil
+}
+
+// QueryRange проверяет запрос и возвращает значения серии, выровненные по шагу.
+func (s *HistoryService) QueryRange(ctx context.Context, q domain.RangeQuery) ([]domain.Sample, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	points, err := s.repository.QueryRange(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if points == nil {
+		points = []domain.Sample{}
+	}
+	return points, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestHistoryService(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	s := NewHistoryService(repo, zap.NewNop())
+
+	svc := NewService(repo, nil)
+	require.NoError(t, svc.SaveOrUpdateMetric(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(1.5)}))
+	require.NoError(t, svc.SaveOrUpdateMetric(ctx, &domain.Metrics{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(2.5)}))
+	require.NoError(t, svc.SaveOrUpdateMetric(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(2))}))
+	require.NoError(t, svc.SaveOrUpdateMetric(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(3))}))
+
+	now := time.Now()
+	samples, err := s.Samples(ctx, "Alloc", domain.Gauge, now.Add(-time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 1.5, samples[0].Value)
+	assert.Equal(t, 2.5, samples[1].Value)
+
+	// Для counter хранится накопленное значение
+	samples, err = s.Samples(ctx, "PollCount", domain.Counter, now.Add(-time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 5.0, samples[1].Value)
+}
+
+func TestHistoryService_QueryRange(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	s := NewHistoryService(repo, zap.NewNop())
+
+	start := time.Now().Truncate(time.Second)
+	q := domain.RangeQuery{ID: "Alloc", MType: domain.Gauge, Start: start, End: start.Add(20 * time.Minute), Step: 5 * time.Minute}
+	samples := []domain.Sample{
+		{TS: start.Add(-time.Minute), Value: 1},
+		{TS: start.Add(4 * time.Minute), Value: 2},
+		{TS: start.Add(6 * time.Minute), Value: 3},
+	}
+
+	// В точке берется последний сэмпл не старше RangeLookback,
+	// точки 15m и 20m остаются без значений
+	points := q.Align(samples)
+	assert.Equal(t, []domain.Sample{
+		{TS: start, Value: 1},
+		{TS: start.Add(5 * time.Minute), Value: 2},
+		{TS: start.Add(10 * time.Minute), Value: 3},
+	}, points)
+
+	_, err := s.QueryRange(ctx, domain.RangeQuery{ID: "Alloc", MType: domain.Gauge, Start: start, End: start, Step: 0})
+	assert.ErrorIs(t, err, domain.ErrInvalidRangeQuery)
+	_, err = s.QueryRange(ctx, domain.RangeQuery{ID: "Alloc", MType: domain.Gauge, Start: start, End: start.Add(24 * time.Hour), Step: time.Second})
+	assert.ErrorIs(t, err, domain.ErrInvalidRangeQuery)
+
+	points, err = s.QueryRange(ctx, q)
+	require.NoError(t, err)
+	assert.Empty(t, points)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/idempotency"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+const (
+	defaultIdempotencyTTL     = time.Hour
+	defaultIdempotencyCleanup = time.Minute
+)
+
+// IdempotencyService запоминает ответы на запросы с ключом идемпотентности,
+// чтобы повтор запроса после потерянного ответа не применял изменения
+// (например, приращения counter) второй раз.
+type IdempotencyService struct {
+	repository interfaces.IdempotencyRepository
+	ttl        time.Duration
+	cleanup    time.Duration
+	logger     *zap.Logger
+	now        func() time.Time
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewIdempotencyService создает сервис с временем жизни ключей и
+// периодичностью удаления истекших ключей из конфигурации.
+func NewIdempotencyService(repository interfaces.IdempotencyRepository, cfg *idempotency.IdempotencyConfig, log *zap.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		repository: repository,
+		ttl:        cmp.Or(cfg.TTL, defaultIdempotencyTTL),
+		cleanup:    cmp.Or(cfg.CleanupInterval, defaultIdempotencyCleanup),
+		logger:     log.Named("idempotency-service"),
+		now:        time.Now,
+	}
+}
+
+// Begin занимает ключ key для запроса с отпечатком fingerprint. Возвращает
+// nil, если запрос нужно выполнить, и сохраненную запись, если ответ на
+// него уже есть. Если запрос с этим ключом еще выполняется, возвращает
+// domain.ErrIdempotencyKeyInUse, а если ключ использован для другого
+// запроса - domain.ErrIdempotencyKeyReused.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	if err := domain.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	s.deleteExpired(ctx, now)
+
+	existing, err := s.repository.ReserveIdempotencyKey(ctx, &domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(s.ttl),
+	}, now)
+	switch {
+	case err != nil:
+		return nil, err
+	case existing == nil:
+		return nil, nil
+	case existing.Fingerprint != fingerprint:
+		return nil, domain.ErrIdempotencyKeyReused
+	case !existing.Done():
+		return nil, domain.ErrIdempotencyKeyInUse
+	}
+	s.logger.Debug("idempotent request replayed", zap.String("key", key))
+	return existing, nil
+}
+
+// Complete сохраняет ответ на запрос, начатый через Begin.
+func (s *IdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return s.repository.SaveIdempotencyRecord(ctx, record)
+}
+
+// Abort освобождает ключ запроса, ответ на который не нужно запоминать,
+// чтобы клиент мог повторить запрос.
+func (s *IdempotencyService) Abort(ctx context.Context, key string) {
+	if err := s.repository.DeleteIdempotencyKey(ctx, key); err != nil {
+		s.logger.Warn("failed to release idempotency key", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// deleteExpired удаляет истекшие ключи не чаще раза в cleanup.
+func (s *IdempotencyService) deleteExpired(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < s.cleanup {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+
+	if err := s.repository.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+		s.logger.Warn("failed to delete expired idempotency keys", zap.Error(err))
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/idempotency"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewIdempotencyService(mem.NewIdempotencyRepository(0), &idempotency.IdempotencyConfig{TTL: time.Minute}, zap.NewNop())
+	s.now = func() time.Time { return now }
+
+	_, err := s.Begin(ctx, "", "fp")
+	require.ErrorIs(t, err, domain.ErrInvalidIdempotencyKey)
+
+	record, err := s.Begin(ctx, "key", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// Пока ответа нет, повтор отклоняется
+	_, err = s.Begin(ctx, "key", "fp")
+	require.ErrorIs(t, err, domain.ErrIdempotencyKeyInUse)
+
+	require.NoError(t, s.Complete(ctx, &domain.IdempotencyRecord{Key: "key", Fingerprint: "fp", Status: 200, Body: []byte("{}")}))
+	record, err = s.Begin(ctx, "key", "fp")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 200, record.Status)
+
+	_, err = s.Begin(ctx, "key", "other")
+	require.ErrorIs(t, err, domain.ErrIdempotencyKeyReused)
+
+	// Освобожденный и истекший ключи занимаются заново
+	s.Abort(ctx, "key")
+	record, err = s.Begin(ctx, "key", "other")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	now = now.Add(time.Minute)
+	record, err = s.Begin(ctx, "key", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
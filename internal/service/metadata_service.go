@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// MetadataService ведет реестр описаний метрик (назначение, единица
+// измерения, владелец), которые показываются на дашборде и в HELP
+// экспозиции Prometheus.
+type MetadataService struct {
+	repository interfaces.MetadataRepository
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewMetadataService создает сервис описаний метрик.
+func NewMetadataService(repository interfaces.MetadataRepository, log *zap.Logger) *MetadataService {
+	return &MetadataService{
+		repository: repository,
+		logger:     log.Named("metadata-service"),
+		now:        time.Now,
+	}
+}
+
+// Set валидирует и сохраняет описание метрики, заменяя предыдущее.
+func (s *MetadataService) Set(ctx context.Context, metadata *domain.MetricMetadata) error {
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+	metadata.UpdatedAt = s.now()
+	if err := s.repository.SaveMetadata(ctx, metadata); err != nil {
+		return err
+	}
+	s.logger.Info("metadata updated",
+		zap.String("id", metadata.ID),
+		zap.String("unit", metadata.Unit),
+		zap.String("owner", metadata.Owner),
+	)
+	return nil
+}
+
+// List возвращает описания всех метрик, отсортированные по id.
+func (s *MetadataService) List(ctx context.Context) ([]domain.MetricMetadata, error) {
+	metadata, err := s.repository.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		metadata = []domain.MetricMetadata{}
+	}
+	return metadata, nil
+}
+
+// Index возвращает описания метрик по id.
+func (s *MetadataService) Index(ctx context.Context) (map[string]domain.MetricMetadata, error) {
+	metadata, err := s.repository.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]domain.MetricMetadata, len(metadata))
+	for _, m := range metadata {
+		index[m.ID] = m
+	}
+	return index, nil
+}
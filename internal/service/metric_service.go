@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 
 	"go.uber.org/zap"
 
@@ -19,25 +20,41 @@ import (
 type MetricService struct {
 	repository interfaces.MetricsRepository
 	store      interfaces.MetricsStore
+	stream     *StreamService
+}
+
+// ServiceOption задает необязательные зависимости сервиса метрик.
+type ServiceOption func(*MetricService)
+
+// WithStream подключает поток, в который публикуются принятые обновления.
+func WithStream(stream *StreamService) ServiceOption {
+	return func(s *MetricService) {
+		s.stream = stream
+	}
 }
 
 // NewService создает сервис метрик с переданным репозиторием и стором.
-func NewService(repository interfaces.MetricsRepository, store interfaces.MetricsStore) *MetricService {
-	return &MetricService{repository: repository, store: store}
+func NewService(repository interfaces.MetricsRepository, store interfaces.MetricsStore, opts ...ServiceOption) *MetricService {
+	s := &MetricService{repository: repository, store: store}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // SaveOrUpdateMetric сохраняет или обновляет одну метрику
 // с применением стратегии обновления (counter/gauge).
 func (s *MetricService) SaveOrUpdateMetric(ctx context.Context, metric *domain.Metrics) error {
 	// Получаем существующую метрику или создаем пустую для новой
-	oldMetric, err := s.repository.Metric(ctx, metric.ID, metric.MType)
+	oldMetric, err := s.repository.Metric(ctx, metric.ID, metric.MType, metric.Labels)
 	if err != nil {
 		if errors.Is(err, domain.ErrMetricNotFound) {
 			zl.Log.Debug("new metric", zap.String("id", metric.ID), zap.String("type", metric.MType))
 			// Для новой метрики создаем пустую с нулевыми значениями
 			oldMetric = &domain.Metrics{
-				ID:    metric.ID,
-				MType: metric.MType,
+				ID:     metric.ID,
+				MType:  metric.MType,
+				Labels: metric.Labels,
 			}
 		} else {
 			return err
@@ -78,6 +95,7 @@ func (s *MetricService) SaveOrUpdateMetric(ctx context.Context, metric *domain.M
 		zap.String("value", fmt.Sprintf("%v", util.GetDefault(metric.Value))),
 		zap.String("delta", fmt.Sprintf("%v", util.GetDefault(metric.Delta))),
 	)
+	s.publish(*updatedMetric)
 	return nil
 }
 
@@ -91,6 +109,7 @@ func (s *MetricService) SaveOrUpdateMetricsBatch(ctx context.Context, metrics []
 	if s.store != nil && s.store.IsActive() && s.store.IsSyncMode() {
 		s.store.SaveAllMetrics(ctx)
 	}
+	s.publishBatch(ctx, metrics)
 	return nil
 }
 
@@ -104,9 +123,37 @@ func (s *MetricService) GetAllMetrics(ctx context.Context) ([]domain.Metrics, er
 	return m, nil
 }
 
-// GetMetric возвращает метрику по id и типу.
-func (s *MetricService) GetMetric(ctx context.Context, id, t string) (*domain.Metrics, error) {
-	m, err := s.repository.Metric(ctx, id, t)
+// GetMetricsByType возвращает список метрик указанного типа.
+func (s *MetricService) GetMetricsByType(ctx context.Context, mType string) ([]domain.Metrics, error) {
+	m, err := s.repository.MetricListByType(ctx, mType)
+	if err != nil {
+		return nil, err
+	}
+	zl.Log.Debug("Total metrics by type", zap.String("type", mType), zap.Int("len", len(m)))
+	return m, nil
+}
+
+// ListMetrics возвращает страницу списка метрик и курсор следующей
+// страницы; пустой курсор означает, что страница последняя. Репозиторий
+// запрашивается на одну метрику больше лимита, чтобы узнать, есть ли
+// продолжение.
+func (s *MetricService) ListMetrics(ctx context.Context, q domain.MetricQuery) ([]domain.Metrics, string, error) {
+	limit := q.Limit
+	q.Limit++
+	m, err := s.repository.MetricPage(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(m) <= limit {
+		return m, "", nil
+	}
+	m = m[:limit]
+	return m, q.Cursor(&m[limit-1]).Encode(), nil
+}
+
+// GetMetric возвращает метрику по id, типу и лейблам; nil лейблы - метрика без лейблов.
+func (s *MetricService) GetMetric(ctx context.Context, id, t string, labels domain.Labels) (*domain.Metrics, error) {
+	m, err := s.repository.Metric(ctx, id, t, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +162,121 @@ func (s *MetricService) GetMetric(ctx context.Context, id, t string) (*domain.Me
 }
 
 // GetEnrichMetric возвращает метрику с актуализированными значениями.
-func (s *MetricService) GetEnrichMetric(ctx context.Context, id, mType string) (*domain.Metrics, error) {
-	return s.repository.Metric(ctx, id, mType)
+func (s *MetricService) GetEnrichMetric(ctx context.Context, id, mType string, labels domain.Labels) (*domain.Metrics, error) {
+	return s.repository.Metric(ctx, id, mType, labels)
+}
+
+// DeleteMetric удаляет метрику по id, типу и лейблам.
+// Возвращает domain.ErrMetricNotFound, если метрика не найдена.
+func (s *MetricService) DeleteMetric(ctx context.Context, id, t string, labels domain.Labels) error {
+	if err := s.repository.Delete(ctx, id, t, labels); err != nil {
+		return err
+	}
+	zl.Log.Info("metric deleted", zap.String("id", domain.SeriesID(id, labels)), zap.String("type", t))
+	return s.rewriteStore(ctx)
+}
+
+// DeleteMetricsByPattern удаляет все серии метрик, id которых подходит под
+// pattern; пустой mType - метрики любого типа. Возвращает удаленные метрики.
+func (s *MetricService) DeleteMetricsByPattern(ctx context.Context, pattern *regexp.Regexp, mType string) ([]domain.Metrics, error) {
+	var (
+		metrics []domain.Metrics
+		err     error
+	)
+	if mType == "" {
+		metrics, err = s.repository.MetricList(ctx)
+	} else {
+		metrics, err = s.repository.MetricListByType(ctx, mType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]domain.Metrics, 0, len(metrics))
+	for _, m := range metrics {
+		if pattern.MatchString(m.ID) {
+			matched = append(matched, m)
+		}
+	}
+	if len(matched) == 0 {
+		return matched, nil
+	}
+	if err := s.repository.DeleteBatch(ctx, matched); err != nil {
+		return nil, err
+	}
+	zl.Log.Info("metrics deleted by pattern", zap.String("pattern", pattern.String()), zap.Int("count", len(matched)))
+	return matched, s.rewriteStore(ctx)
+}
+
+// ResetCounter обнуляет counter метрику. В отличие от обновления, значение
+// не суммируется с сохраненным, а заменяется нулем.
+// Возвращает domain.ErrMetricNotFound, если метрика не найдена.
+func (s *MetricService) ResetCounter(ctx context.Context, id string, labels domain.Labels) (*domain.Metrics, error) {
+	m, err := s.repository.Metric(ctx, id, domain.Counter, labels)
+	if err != nil {
+		return nil, err
+	}
+	reset := &domain.Metrics{ID: m.ID, MType: domain.Counter, Labels: m.Labels, Delta: new(int64)}
+	if err := s.repository.SaveOrUpdate(ctx, reset); err != nil {
+		return nil, err
+	}
+	if s.store != nil && s.store.IsActive() && s.store.IsSyncMode() {
+		if err := s.store.WriteMetric(*reset); err != nil {
+			zl.Log.Error("failed to save metric to store", zap.Error(err))
+			return nil, err
+		}
+	}
+	zl.Log.Info("counter reset", zap.String("id", reset.SeriesID()))
+	s.publish(*reset)
+	return reset, nil
+}
+
+// publish отправляет обновленные метрики подписчикам потока.
+func (s *MetricService) publish(metrics ...domain.Metrics) {
+	if s.stream != nil {
+		s.stream.Publish(metrics...)
+	}
+}
+
+// publishBatch отправляет подписчикам значения метрик после записи батча.
+// Для counter, histogram и summary в батче лежат приращения, а итог
+// считает репозиторий, поэтому значения перечитываются - и только если
+// поток кто-то читает. Повторы серии в батче публикуются один раз.
+func (s *MetricService) publishBatch(ctx context.Context, metrics []*domain.Metrics) {
+	if s.stream == nil || !s.stream.HasSubscribers() {
+		return
+	}
+	seen := make(map[string]struct{}, len(metrics))
+	updated := make([]domain.Metrics, 0, len(metrics))
+	for _, m := range metrics {
+		key := m.MType + " " + m.SeriesID()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		stored, err := s.repository.Metric(ctx, m.ID, m.MType, m.Labels)
+		if err != nil {
+			zl.Log.Warn("failed to read metric for stream", zap.String("id", m.SeriesID()), zap.Error(err))
+			continue
+		}
+		updated = append(updated, *stored)
+	}
+	s.stream.Publish(updated...)
+}
+
+// rewriteStore перезаписывает файловое хранилище в синхронном режиме:
+// журнал обновлений не умеет выражать удаление, поэтому файл сохраняется
+// целиком. В периодическом режиме удаление попадет в файл при ближайшем
+// сохранении.
+func (s *MetricService) rewriteStore(ctx context.Context) error {
+	if s.store == nil || !s.store.IsActive() || !s.store.IsSyncMode() {
+		return nil
+	}
+	if err := s.store.SaveAllMetrics(ctx); err != nil {
+		zl.Log.Error("failed to rewrite store after delete", zap.Error(err))
+		return err
+	}
+	return nil
 }
 
 // Ping проверяет доступность нижележащего хранилища.
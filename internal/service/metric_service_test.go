@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfgstore "github.com/bigsm0uk/metrics-alert-server/internal/app/config/store"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/server/store"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestMetricService_DeleteAndResetPersisted(t *testing.T) {
+	ctx := context.Background()
+	cfg := &cfgstore.StoreConfig{StoreInterval: "0", FileStoragePath: filepath.Join(t.TempDir(), "store.json"), UseStore: true}
+
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	st, err := store.NewJSONStore(repo, cfg)
+	require.NoError(t, err)
+	s := NewService(repo, st)
+
+	for _, m := range []*domain.Metrics{
+		{ID: "PollCount", MType: domain.Counter, Delta: lo.ToPtr(int64(7))},
+		{ID: "RandomValue", MType: domain.Gauge, Value: lo.ToPtr(0.5)},
+		{ID: "Alloc", MType: domain.Gauge, Value: lo.ToPtr(1.5)},
+	} {
+		require.NoError(t, s.SaveOrUpdateMetric(ctx, m))
+	}
+
+	require.NoError(t, s.DeleteMetric(ctx, "Alloc", domain.Gauge, nil))
+	assert.ErrorIs(t, s.DeleteMetric(ctx, "Alloc", domain.Gauge, nil), domain.ErrMetricNotFound)
+
+	deleted, err := s.DeleteMetricsByPattern(ctx, regexp.MustCompile("^Random"), domain.Gauge)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, "RandomValue", deleted[0].ID)
+
+	reset, err := s.ResetCounter(ctx, "PollCount", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), *reset.Delta)
+	_, err = s.ResetCounter(ctx, "RandomValue", nil)
+	assert.ErrorIs(t, err, domain.ErrMetricNotFound)
+
+	// После перезапуска из файла восстанавливаются только оставшиеся метрики
+	restoredRepo := mem.NewMemRepository(storage.NewMemStorage())
+	restored, err := store.NewJSONStore(restoredRepo, cfg)
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(ctx))
+	metrics, err := restoredRepo.MetricList(ctx)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "PollCount", metrics[0].ID)
+	assert.Equal(t, int64(0), *metrics[0].Delta)
+}
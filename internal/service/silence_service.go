@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+)
+
+// SilenceService управляет подавлениями уведомлений алертинга
+// (например, на время выкладки) и хранит их в репозитории подавлений.
+type SilenceService struct {
+	repository interfaces.SilenceRepository
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewSilenceService создает сервис подавлений.
+func NewSilenceService(repository interfaces.SilenceRepository, log *zap.Logger) *SilenceService {
+	return &SilenceService{
+		repository: repository,
+		logger:     log.Named("silence-service"),
+		now:        time.Now,
+	}
+}
+
+// Create валидирует и сохраняет подавление, присваивая ему идентификатор.
+// Если StartsAt не задан, подавление начинает действовать сразу.
+func (s *SilenceService) Create(ctx context.Context, silence *domain.Silence) error {
+	now := s.now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if err := silence.Validate(); err != nil {
+		return err
+	}
+
+	id, err := newSilenceID()
+	if err != nil {
+		return fmt.Errorf("generate silence id: %w", err)
+	}
+	silence.ID = id
+	silence.CreatedAt = now
+
+	if err := s.repository.SaveSilence(ctx, silence); err != nil {
+		return err
+	}
+	s.logger.Info("silence created",
+		zap.String("id", silence.ID),
+		zap.String("metric_id", silence.MetricID),
+		zap.Time("ends_at", silence.EndsAt),
+	)
+	return nil
+}
+
+// List возвращает все подавления, включая истекшие.
+func (s *SilenceService) List(ctx context.Context) ([]domain.Silence, error) {
+	silences, err := s.repository.Silences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if silences == nil {
+		silences = []domain.Silence{}
+	}
+	return silences, nil
+}
+
+// Delete удаляет подавление по идентификатору.
+// Возвращает domain.ErrSilenceNotFound, если подавление не найдено.
+func (s *SilenceService) Delete(ctx context.Context, id string) error {
+	if err := s.repository.DeleteSilence(ctx, id); err != nil {
+		return err
+	}
+	s.logger.Info("silence deleted", zap.String("id", id))
+	return nil
+}
+
+// IsSilenced возвращает true, если для метрики id действует хотя бы одно подавление.
+func (s *SilenceService) IsSilenced(ctx context.Context, id string) (bool, error) {
+	return s.AllSilenced(ctx, []string{id})
+}
+
+// AllSilenced возвращает true, если для каждой метрики из ids действует
+// хотя бы одно подавление. Для пустого ids возвращает false.
+func (s *SilenceService) AllSilenced(ctx context.Context, ids []string) (bool, error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+	silences, err := s.repository.Silences(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := s.now()
+	for _, id := range ids {
+		if !slices.ContainsFunc(silences, func(sl domain.Silence) bool { return sl.IsActive(now) && sl.Matches(id) }) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func newSilenceID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/alert"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain/interfaces"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestSilenceService_CreateDelete(t *testing.T) {
+	ctx := context.Background()
+	repo, err := mem.NewSilenceRepository("")
+	require.NoError(t, err)
+	s := NewSilenceService(repo, zap.NewNop())
+
+	silence := &domain.Silence{MetricID: "Heap.*", IsRegex: true, EndsAt: time.Now().Add(time.Hour)}
+	require.NoError(t, s.Create(ctx, silence))
+	assert.NotEmpty(t, silence.ID)
+	assert.False(t, silence.StartsAt.IsZero())
+
+	silenced, err := s.IsSilenced(ctx, "HeapAlloc")
+	require.NoError(t, err)
+	assert.True(t, silenced)
+	silenced, err = s.IsSilenced(ctx, "Alloc")
+	require.NoError(t, err)
+	assert.False(t, silenced)
+
+	// Выражение должно совпасть с id целиком
+	require.NoError(t, s.Create(ctx, &domain.Silence{MetricID: "cpu", IsRegex: true, EndsAt: time.Now().Add(time.Hour)}))
+	for id, want := range map[string]bool{"cpu": true, "cpu_temp": false, "gpu_cpu_load": false} {
+		silenced, err = s.IsSilenced(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, silenced, id)
+	}
+
+	list, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	require.NoError(t, s.Delete(ctx, silence.ID))
+	assert.ErrorIs(t, s.Delete(ctx, silence.ID), domain.ErrSilenceNotFound)
+
+	// Некорректные подавления
+	assert.ErrorIs(t, s.Create(ctx, &domain.Silence{MetricID: "(", IsRegex: true, EndsAt: time.Now().Add(time.Hour)}), domain.ErrInvalidSilence)
+	assert.ErrorIs(t, s.Create(ctx, &domain.Silence{MetricID: "Alloc", EndsAt: time.Now().Add(-time.Hour)}), domain.ErrInvalidSilence)
+}
+
+func TestAlertService_NotifySilenced(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	silenceRepo, err := mem.NewSilenceRepository("")
+	require.NoError(t, err)
+	silences := NewSilenceService(silenceRepo, zap.NewNop())
+	notifier := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		Rules: []alert.RuleConfig{
+			{
+				Name:       "heap",
+				MetricID:   "HeapAlloc",
+				MetricType: domain.Gauge,
+				Comparison: domain.CompareGreater,
+				Threshold:  100,
+				Channels:   []string{"ops"},
+			},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"ops": notifier}, silences, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, silences.Create(ctx, &domain.Silence{MetricID: "HeapAlloc", EndsAt: time.Now().Add(time.Hour)}))
+
+	v := 200.0
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "HeapAlloc", MType: domain.Gauge, Value: &v}))
+	evaluate(ctx, s)
+
+	// Алерт сработал, но уведомление подавлено
+	assert.Empty(t, notifier.messages)
+	require.Len(t, s.Alerts(), 1)
+	assert.Equal(t, domain.AlertFiring, s.Alerts()[0].State)
+}
+
+func TestAlertService_AbsencePatternSilenced(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	silenceRepo, err := mem.NewSilenceRepository("")
+	require.NoError(t, err)
+	silences := NewSilenceService(silenceRepo, zap.NewNop())
+	notifier := &fakeNotifier{}
+	cfg := &alert.AlertConfig{
+		Absence: []alert.AbsenceRuleConfig{
+			{Name: "heap_absent", Pattern: "^Heap.*", Timeout: time.Minute, Channels: []string{"ops"}},
+		},
+	}
+	s, err := NewAlertService(repo, cfg, map[string]interfaces.AlertNotifier{"ops": notifier}, silences, zap.NewNop())
+	require.NoError(t, err)
+
+	v := 1.0
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "HeapAlloc", MType: domain.Gauge, Value: &v}))
+	require.NoError(t, silences.Create(ctx, &domain.Silence{MetricID: "HeapAlloc", EndsAt: time.Now().Add(time.Hour)}))
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	evaluate(ctx, s)
+
+	// Правило сработало для HeapAlloc, которая подавлена по точному id
+	now = now.Add(2 * time.Minute)
+	evaluate(ctx, s)
+	require.Len(t, s.Alerts(), 1)
+	assert.Equal(t, domain.AlertFiring, s.Alerts()[0].State)
+	assert.Empty(t, notifier.messages)
+
+	// Новая метрика набора не подавлена - уведомление о восстановлении отправляется
+	require.NoError(t, repo.SaveOrUpdate(ctx, &domain.Metrics{ID: "HeapInuse", MType: domain.Gauge, Value: &v}))
+	now = time.Now()
+	evaluate(ctx, s)
+	require.Len(t, notifier.messages, 1)
+	assert.Equal(t, domain.AlertResolved, notifier.messages[0].Status)
+	assert.Equal(t, []string{"HeapAlloc", "HeapInuse"}, notifier.messages[0].MetricIDs)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/ingest/statsd"
+)
+
+// maxDatagramSize - максимальный размер UDP-датаграммы.
+const maxDatagramSize = 64 * 1024
+
+// StatsdService принимает метрики StatsD по UDP, агрегирует их за интервал
+// FlushInterval и записывает батчем через MetricService.
+type StatsdService struct {
+	metrics *MetricService
+	cfg     *ingest.StatsdConfig
+	agg     *statsd.Aggregator
+	logger  *zap.Logger
+
+	dropped  atomic.Uint64 // Значения, отброшенные из-за лимита серий
+	conn     net.PacketConn
+	wg       sync.WaitGroup
+	ticker   *time.Ticker
+	stopChan chan struct{}
+}
+
+// NewStatsdService создает сервис приема StatsD.
+func NewStatsdService(metrics *MetricService, cfg *ingest.StatsdConfig, log *zap.Logger) *StatsdService {
+	return &StatsdService{
+		metrics:  metrics,
+		cfg:      cfg,
+		agg:      statsd.NewAggregator(cfg.MaxKeys),
+		logger:   log.Named("statsd-service"),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// StartProcess открывает UDP-сокет и запускает чтение пакетов и периодическую запись.
+// Если listener отключен в конфигурации, ничего не делает.
+func (s *StatsdService) StartProcess(ctx context.Context) error {
+	if !s.cfg.IsEnabled() {
+		return nil
+	}
+	conn, err := net.ListenPacket("udp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	s.wg.Add(1)
+	go s.readLoop()
+	s.startPeriodicFlush(ctx)
+
+	s.logger.Info("statsd listener started",
+		zap.String("addr", conn.LocalAddr().String()),
+		zap.Duration("flush_interval", s.cfg.FlushInterval),
+	)
+	return nil
+}
+
+// Dropped возвращает число значений, отброшенных с запуска из-за лимита серий.
+func (s *StatsdService) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Addr возвращает фактический адрес сокета (nil, если listener не запущен).
+func (s *StatsdService) Addr() net.Addr {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+func (s *StatsdService) readLoop() {
+	defer s.wg.Done()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Warn("failed to read statsd packet", zap.Error(err))
+			continue
+		}
+		s.handlePacket(string(buf[:n]))
+	}
+}
+
+// handlePacket разбирает пакет, строки которого разделены переводом строки.
+func (s *StatsdService) handlePacket(packet string) {
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sample, err := statsd.ParseLine(line)
+		if err != nil {
+			s.logger.Debug("invalid statsd line", zap.String("line", line), zap.Error(err))
+			continue
+		}
+		s.agg.Add(sample)
+	}
+}
+
+func (s *StatsdService) startPeriodicFlush(ctx context.Context) {
+	s.ticker = time.NewTicker(s.cfg.FlushInterval)
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				if err := s.Flush(ctx); err != nil {
+					s.logger.Error("failed to flush statsd metrics", zap.Error(err))
+				}
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Flush записывает накопленные за интервал метрики.
+func (s *StatsdService) Flush(ctx context.Context) error {
+	metrics, dropped := s.agg.Flush()
+	if dropped > 0 {
+		s.dropped.Add(uint64(dropped))
+		s.logger.Warn("statsd series limit reached, values dropped",
+			zap.Int("dropped", dropped),
+			zap.Int("max_keys", s.cfg.MaxKeys),
+		)
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+	if err := s.metrics.SaveOrUpdateMetricsBatch(ctx, metrics); err != nil {
+		return err
+	}
+	s.logger.Debug("statsd metrics flushed", zap.Int("count", len(metrics)))
+	return nil
+}
+
+// Close закрывает сокет, останавливает периодическую запись
+// и записывает значения, накопленные с последнего сброса.
+func (s *StatsdService) Close(ctx context.Context) error {
+	if s.conn == nil {
+		return nil
+	}
+	s.ticker.Stop()
+	close(s.stopChan)
+	err := s.conn.Close()
+	s.wg.Wait()
+	s.conn = nil
+	return errors.Join(err, s.Flush(ctx))
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/ingest"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestStatsdService(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewMemRepository(storage.NewMemStorage())
+	ms := NewService(repo, nil)
+	s := NewStatsdService(ms, &ingest.StatsdConfig{Addr: "127.0.0.1:0", FlushInterval: time.Hour}, zap.NewNop())
+	require.NoError(t, s.StartProcess(ctx))
+
+	conn, err := net.Dial("udp", s.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hits:2|c\ntemp:21.5|g\nbroken\nreq:10|ms"))
+	require.NoError(t, err)
+
+	// Пакет обрабатывается асинхронно: сбрасываем агрегатор, пока метрики не появятся.
+	require.Eventually(t, func() bool {
+		require.NoError(t, s.Flush(ctx))
+		_, err := ms.GetMetric(ctx, "req.count", domain.Gauge, nil)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	hits, err := ms.GetMetric(ctx, "hits", domain.Counter, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), *hits.Delta)
+	temp, err := ms.GetMetric(ctx, "temp", domain.Gauge, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 21.5, *temp.Value)
+
+	require.NoError(t, s.Close(ctx))
+	assert.Nil(t, s.Addr())
+}
+
+func TestStatsdService_MaxKeys(t *testing.T) {
+	ctx := context.Background()
+	ms := NewService(mem.NewMemRepository(storage.NewMemStorage()), nil)
+	s := NewStatsdService(ms, &ingest.StatsdConfig{Addr: "127.0.0.1:0", FlushInterval: time.Hour, MaxKeys: 1}, zap.NewNop())
+	require.NoError(t, s.StartProcess(ctx))
+	defer s.Close(ctx)
+
+	conn, err := net.Dial("udp", s.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hits:2|c\nmisses:1|c"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		require.NoError(t, s.Flush(ctx))
+		return s.Dropped() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = ms.GetMetric(ctx, "hits", domain.Counter, nil)
+	require.NoError(t, err)
+	_, err = ms.GetMetric(ctx, "misses", domain.Counter, nil)
+	assert.ErrorIs(t, err, domain.ErrMetricNotFound)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/stream"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+)
+
+// StreamFilter отбирает обновления для подписчика; пустое поле не
+// ограничивает выборку.
+type StreamFilter struct {
+	ID   string
+	Type string
+}
+
+// Match сообщает, подходит ли метрика под фильтр.
+func (f StreamFilter) Match(m *domain.Metrics) bool {
+	return (f.ID == "" || m.ID == f.ID) && (f.Type == "" || m.MType == f.Type)
+}
+
+// Subscription - подписка на поток обновлений. Канал Updates закрывается
+// при отписке, при остановке потока и при отключении подписчика, который
+// не успевает читать обновления.
+type Subscription struct {
+	Updates <-chan domain.Metrics
+	updates chan domain.Metrics
+	filter  StreamFilter
+	dropped bool
+}
+
+// Dropped сообщает, что подписчик отключен из-за переполнения буфера.
+// Имеет смысл после закрытия Updates.
+func (s *Subscription) Dropped() bool {
+	return s.dropped
+}
+
+// StreamService рассылает принятые обновления метрик подписчикам.
+// Публикация не блокируется: у каждого подписчика буфер ограниченного
+// размера, и подписчик, который его переполнил, отключается, чтобы
+// медленный клиент не задерживал прием метрик и не копил память.
+type StreamService struct {
+	mu        sync.Mutex
+	subs      map[*Subscription]struct{}
+	closed    bool
+	buffer    int
+	heartbeat time.Duration
+	logger    *zap.Logger
+}
+
+// NewStreamService создает поток обновлений с размером буфера подписчика
+// и периодичностью heartbeat из конфигурации.
+func NewStreamService(cfg *stream.StreamConfig, log *zap.Logger) *StreamService {
+	return &StreamService{
+		subs:      map[*Subscription]struct{}{},
+		buffer:    max(cfg.Buffer, 1),
+		heartbeat: cfg.Heartbeat,
+		logger:    log.Named("stream-service"),
+	}
+}
+
+// Heartbeat возвращает периодичность сообщений, поддерживающих соединение;
+// ноль - сообщения не отправляются.
+func (s *StreamService) Heartbeat() time.Duration {
+	return s.heartbeat
+}
+
+// Subscribe регистрирует подписчика с фильтром f. После остановки потока
+// возвращает подписку с уже закрытым каналом.
+func (s *StreamService) Subscribe(f StreamFilter) *Subscription {
+	ch := make(chan domain.Metrics, s.buffer)
+	sub := &Subscription{Updates: ch, updates: ch, filter: f}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		close(ch)
+		return sub
+	}
+	s.subs[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe отменяет подписку; повторный вызов ничего не делает.
+func (s *StreamService) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+// HasSubscribers сообщает, есть ли активные подписчики.
+func (s *StreamService) HasSubscribers() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs) > 0
+}
+
+// Publish рассылает обновления подписчикам, фильтр которых им соответствует.
+func (s *StreamService) Publish(metrics ...domain.Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		for i := range metrics {
+			if !sub.filter.Match(&metrics[i]) {
+				continue
+			}
+			select {
+			case sub.updates <- metrics[i]:
+				continue
+			default:
+			}
+			sub.dropped = true
+			s.remove(sub)
+			s.logger.Warn("slow stream subscriber dropped", zap.Int("buffer", s.buffer))
+			break
+		}
+	}
+}
+
+// Close отключает всех подписчиков; новые подписки сразу закрываются.
+func (s *StreamService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subs {
+		s.remove(sub)
+	}
+}
+
+func (s *StreamService) remove(sub *Subscription) {
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.updates)
+	}
+}
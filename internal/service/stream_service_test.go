@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/config/stream"
+	"github.com/bigsm0uk/metrics-alert-server/internal/app/storage"
+	"github.com/bigsm0uk/metrics-alert-server/internal/domain"
+	"github.com/bigsm0uk/metrics-alert-server/internal/repository/mem"
+)
+
+func TestStreamService_FilterAndDrop(t *testing.T) {
+	s := NewStreamService(&stream.StreamConfig{Buffer: 2}, zap.NewNop())
+	all := s.Subscribe(StreamFilter{})
+	heap := s.Subscribe(StreamFilter{ID: "HeapAlloc", Type: domain.Gauge})
+	assert.True(t, s.HasSubscribers())
+
+	s.Publish(
+		domain.Metrics{ID: "HeapAlloc", MType: domain.Gauge},
+		domain.Metrics{ID: "PollCount", MType: domain.Counter},
+	)
+	assert.Equal(t, "HeapAlloc", (<-heap.Updates).ID)
+	assert.Len(t, all.Updates, 2)
+
+	// Третье непрочитанное обновление переполняет буфер all
+	s.Publish(domain.Metrics{ID: "Alloc", MType: domain.Gauge})
+	_, ok := <-all.Updates
+	assert.True(t, ok, "уже принятые обновления дочитываются")
+	<-all.Updates
+	_, ok = <-all.Updates
+	assert.False(t, ok)
+	assert.True(t, all.Dropped())
+
+	s.Unsubscribe(heap)
+	s.Unsubscribe(heap)
+	_, ok = <-heap.Updates
+	assert.False(t, ok)
+	assert.False(t, heap.Dropped())
+	assert.False(t, s.HasSubscribers())
+
+	s.Close()
+	_, ok = <-s.Subscribe(StreamFilter{}).Updates
+	assert.False(t, ok, "после остановки подписка сразу закрыта")
+}
+
+func TestMetricService_PublishesStoredValues(t *testing.T) {
+	ctx := context.Background()
+	s := NewStreamService(&stream.StreamConfig{Buffer: 16}, zap.NewNop())
+	svc := NewService(mem.NewMemRepository(storage.NewMemStorage()), nil, WithStream(s))
+	sub := s.Subscribe(StreamFilter{Type: domain.Counter})
+	defer s.Unsubscribe(sub)
+
+	delta := func(d int64) *int64 { return &d }
+	require.NoError(t, svc.SaveOrUpdateMetric(ctx, &domain.Metrics{ID: "PollCount", MType: domain.Counter, Delta: delta(2)}))
+	assert.Equal(t, int64(2), *(<-sub.Updates).Delta)
+
+	// В батче counter приходит приращением, а в поток - итоговым значением, по разу на серию
+	require.NoError(t, svc.SaveOrUpdateMetricsBatch(ctx, []*domain.Metrics{
+		{ID: "PollCount", MType: domain.Counter, Delta: delta(3)},
+		{ID: "PollCount", MType: domain.Counter, Delta: delta(5)},
+		{ID: "Alloc", MType: domain.Gauge, Value: new(float64)},
+	}))
+	assert.Equal(t, int64(10), *(<-sub.Updates).Delta)
+	assert.Empty(t, sub.Updates)
+}
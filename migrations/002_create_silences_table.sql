@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS silences (
+    id VARCHAR(64) PRIMARY KEY,
+    metric_id VARCHAR(255) NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_silences_ends_at ON silences(ends_at);
+
+-- Комментарии для документации
+COMMENT ON TABLE silences IS 'Подавления уведомлений алертинга';
+COMMENT ON COLUMN silences.metric_id IS 'Идентификатор метрики или регулярное выражение';
+COMMENT ON COLUMN silences.is_regex IS 'Признак того, что metric_id - регулярное выражение';
+COMMENT ON COLUMN silences.starts_at IS 'Начало действия подавления';
+COMMENT ON COLUMN silences.ends_at IS 'Окончание действия подавления';
+COMMENT ON COLUMN silences.comment IS 'Причина подавления';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS silences;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_samples (
+    id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    ts TIMESTAMP WITH TIME ZONE NOT NULL,
+    value DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_metric_samples_series_ts ON metric_samples(id, type, ts);
+CREATE INDEX IF NOT EXISTS idx_metric_samples_ts ON metric_samples(ts);
+
+-- Комментарии для документации
+COMMENT ON TABLE metric_samples IS 'История значений метрик';
+COMMENT ON COLUMN metric_samples.ts IS 'Время обновления метрики';
+COMMENT ON COLUMN metric_samples.value IS 'Значение gauge или накопленное значение counter после обновления';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metric_samples;
+-- +goose StatementEnd
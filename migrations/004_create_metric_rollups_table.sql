@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_rollups (
+    id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    resolution INTEGER NOT NULL,
+    bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    min DOUBLE PRECISION NOT NULL,
+    max DOUBLE PRECISION NOT NULL,
+    sum DOUBLE PRECISION NOT NULL,
+    count BIGINT NOT NULL,
+    last DOUBLE PRECISION NOT NULL,
+
+    PRIMARY KEY (id, type, resolution, bucket_start)
+);
+
+-- Комментарии для документации
+COMMENT ON TABLE metric_rollups IS 'Агрегированная история метрик (1m и 1h)';
+COMMENT ON COLUMN metric_rollups.resolution IS 'Длина интервала агрегации в секундах';
+COMMENT ON COLUMN metric_rollups.bucket_start IS 'Начало интервала агрегации';
+COMMENT ON COLUMN metric_rollups.count IS 'Количество сэмплов в интервале';
+COMMENT ON COLUMN metric_rollups.last IS 'Последнее значение в интервале';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metric_rollups;
+-- +goose StatementEnd
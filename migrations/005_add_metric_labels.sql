@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS series_id TEXT;
+UPDATE metrics SET series_id = id WHERE series_id IS NULL;
+ALTER TABLE metrics ALTER COLUMN series_id SET NOT NULL;
+
+-- Метрика идентифицируется id + тип + лейблы; jsonb сравнивается без учета порядка ключей
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (id, type, labels);
+
+-- История и агрегаты хранятся по идентификатору серии, который длиннее id метрики
+ALTER TABLE metric_samples ALTER COLUMN id TYPE TEXT;
+ALTER TABLE metric_rollups ALTER COLUMN id TYPE TEXT;
+
+-- Комментарии для документации
+COMMENT ON COLUMN metrics.labels IS 'Лейблы метрики; пустой объект - метрика без лейблов';
+COMMENT ON COLUMN metrics.series_id IS 'Идентификатор серии вида id{k="v"} для metric_samples и metric_rollups';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE labels <> '{}';
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (id, type);
+ALTER TABLE metrics DROP COLUMN IF EXISTS series_id;
+ALTER TABLE metrics DROP COLUMN IF EXISTS labels;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS histogram JSONB;
+
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+ALTER TABLE metrics ADD CONSTRAINT metrics_type_check
+    CHECK (type IN ('counter', 'gauge', 'histogram'));
+
+ALTER TABLE metrics ADD CONSTRAINT chk_histogram_has_value
+    CHECK ((type = 'histogram' AND histogram IS NOT NULL) OR type != 'histogram');
+
+-- Комментарии для документации
+COMMENT ON COLUMN metrics.type IS 'Тип метрики: counter, gauge или histogram';
+COMMENT ON COLUMN metrics.histogram IS 'Значение histogram: границы бакетов, числа наблюдений, count и sum';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE type = 'histogram';
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS chk_histogram_has_value;
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+ALTER TABLE metrics ADD CONSTRAINT metrics_type_check CHECK (type IN ('counter', 'gauge'));
+ALTER TABLE metrics DROP COLUMN IF EXISTS histogram;
+-- +goose StatementEnd
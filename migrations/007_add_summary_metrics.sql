@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics ADD COLUMN IF NOT EXISTS summary JSONB;
+
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+ALTER TABLE metrics ADD CONSTRAINT metrics_type_check
+    CHECK (type IN ('counter', 'gauge', 'histogram', 'summary'));
+
+ALTER TABLE metrics ADD CONSTRAINT chk_summary_has_value
+    CHECK ((type = 'summary' AND summary IS NOT NULL) OR type != 'summary');
+
+-- Комментарии для документации
+COMMENT ON COLUMN metrics.type IS 'Тип метрики: counter, gauge, histogram или summary';
+COMMENT ON COLUMN metrics.summary IS 'Значение summary: бины DDSketch, точность, count, sum, min и max';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE type = 'summary';
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS chk_summary_has_value;
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_type_check;
+ALTER TABLE metrics ADD CONSTRAINT metrics_type_check CHECK (type IN ('counter', 'gauge', 'histogram'));
+ALTER TABLE metrics DROP COLUMN IF EXISTS summary;
+-- +goose StatementEnd
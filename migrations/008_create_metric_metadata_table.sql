@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_metadata (
+    id VARCHAR(255) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    unit VARCHAR(255) NOT NULL DEFAULT '',
+    owner VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Комментарии для документации
+COMMENT ON TABLE metric_metadata IS 'Описания метрик: назначение, единица измерения и владелец';
+COMMENT ON COLUMN metric_metadata.id IS 'Идентификатор метрики; описание относится ко всем ее типам и сериям';
+COMMENT ON COLUMN metric_metadata.unit IS 'Единица измерения, например bytes или seconds';
+COMMENT ON COLUMN metric_metadata.owner IS 'Команда или человек, отвечающие за метрику';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metric_metadata;
+-- +goose StatementEnd
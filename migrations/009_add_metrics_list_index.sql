@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Индекс для постраничного списка метрик: сортировка по id и фильтр по
+-- префиксу (LIKE 'prefix%' использует btree только с побайтовой сортировкой)
+CREATE INDEX IF NOT EXISTS idx_metrics_list ON metrics((id COLLATE "C"), (series_id COLLATE "C"), (type COLLATE "C"));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_metrics_list;
+-- +goose StatementEnd
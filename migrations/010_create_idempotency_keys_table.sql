@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    hash VARCHAR(255) NOT NULL DEFAULT '',
+    body BYTEA,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Комментарии для документации
+COMMENT ON TABLE idempotency_keys IS 'Ключи идемпотентности запросов /update и /updates и ответы на них';
+COMMENT ON COLUMN idempotency_keys.fingerprint IS 'SHA-256 метода, пути и тела запроса; повтор ключа с другим запросом отклоняется';
+COMMENT ON COLUMN idempotency_keys.status IS 'HTTP статус сохраненного ответа; 0 - запрос еще выполняется';
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Момент, после которого ключ можно использовать заново';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
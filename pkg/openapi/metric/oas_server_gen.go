@@ -16,33 +16,84 @@ type ServerInterface interface {
 	// Получить все метрики
 	// (GET /)
 	GetAllMetrics(w http.ResponseWriter, r *http.Request)
+	// Получить активные алерты
+	// (GET /api/alerts)
+	GetAlerts(w http.ResponseWriter, r *http.Request)
+	// Получить описания метрик
+	// (GET /api/metadata)
+	GetMetadata(w http.ResponseWriter, r *http.Request)
+	// Задать описание метрики
+	// (PUT /api/metadata/{id})
+	PutMetadata(w http.ResponseWriter, r *http.Request, id string)
+	// Получить список метрик
+	// (GET /api/metrics)
+	ListMetrics(w http.ResponseWriter, r *http.Request, params ListMetricsParams)
+	// Получить подавления уведомлений
+	// (GET /api/silences)
+	GetSilences(w http.ResponseWriter, r *http.Request)
+	// Создать подавление уведомлений
+	// (POST /api/silences)
+	CreateSilence(w http.ResponseWriter, r *http.Request)
+	// Удалить подавление уведомлений
+	// (DELETE /api/silences/{id})
+	DeleteSilence(w http.ResponseWriter, r *http.Request, id string)
+	// Поток обновлений метрик
+	// (GET /api/stream)
+	StreamMetrics(w http.ResponseWriter, r *http.Request, params StreamMetricsParams)
+	// Получить историю метрики за интервал
+	// (GET /api/v1/query_range)
+	GetQueryRange(w http.ResponseWriter, r *http.Request, params GetQueryRangeParams)
+	// Прием метрик по протоколу Prometheus remote_write
+	// (POST /api/v1/write)
+	RemoteWrite(w http.ResponseWriter, r *http.Request, params RemoteWriteParams)
 	// HTML-страница с документацией API
 	// (GET /docs)
 	GetDocs(w http.ResponseWriter, r *http.Request)
 	// Health check
 	// (GET /health)
 	HealthCheck(w http.ResponseWriter, r *http.Request)
+	// Удалить метрики по шаблону
+	// (DELETE /metric)
+	DeleteMetrics(w http.ResponseWriter, r *http.Request, params DeleteMetricsParams)
+	// Удалить метрику
+	// (DELETE /metric/{type}/{id})
+	DeleteMetric(w http.ResponseWriter, r *http.Request, mType DeleteMetricParamsType, id ID, params DeleteMetricParams)
+	// Экспорт метрик в формате Prometheus
+	// (GET /metrics)
+	GetPrometheusMetrics(w http.ResponseWriter, r *http.Request, params GetPrometheusMetricsParams)
 	// OpenAPI specification
 	// (GET /openapi)
 	GetOpenAPI(w http.ResponseWriter, r *http.Request)
 	// Ping
 	// (GET /ping)
 	Ping(w http.ResponseWriter, r *http.Request)
+	// Сбросить counter метрику
+	// (POST /reset/counter/{id})
+	ResetCounter(w http.ResponseWriter, r *http.Request, id ID, params ResetCounterParams)
 	// Обновить/создать метрику по телу запроса
 	// (POST /update)
-	UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request)
+	UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request, params UpdateOrCreateMetricByBodyParams)
 	// Обновить/создать метрику по параметрам
 	// (POST /update/{type}/{id}/{value})
 	UpdateOrCreateMetricByParam(w http.ResponseWriter, r *http.Request, mType UpdateOrCreateMetricByParamParamsType, id ID, value Value)
 	// Обновить/создать несколько метрик по телу запроса
 	// (POST /updates)
-	UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request)
+	UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request, params UpdateOrCreateMetricsBatchParams)
+	// Прием батчей метрик по WebSocket
+	// (GET /updates/ws)
+	UpdateMetricsWebSocket(w http.ResponseWriter, r *http.Request)
+	// Прием метрик по протоколу OTLP/HTTP
+	// (POST /v1/metrics)
+	OtlpMetrics(w http.ResponseWriter, r *http.Request)
 	// Получить значение метрики по телу запроса
 	// (POST /value)
 	GetValueByBody(w http.ResponseWriter, r *http.Request)
 	// Получить значение метрики по параметрам
 	// (GET /value/{type}/{id})
-	GetValueByParam(w http.ResponseWriter, r *http.Request, mType GetValueByParamParamsType, id ID)
+	GetValueByParam(w http.ResponseWriter, r *http.Request, mType GetValueByParamParamsType, id ID, params GetValueByParamParams)
+	// Прием метрик в формате InfluxDB line protocol
+	// (POST /write)
+	InfluxWrite(w http.ResponseWriter, r *http.Request, params InfluxWriteParams)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
@@ -55,6 +106,66 @@ func (_ Unimplemented) GetAllMetrics(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Получить активные алерты
+// (GET /api/alerts)
+func (_ Unimplemented) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить описания метрик
+// (GET /api/metadata)
+func (_ Unimplemented) GetMetadata(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Задать описание метрики
+// (PUT /api/metadata/{id})
+func (_ Unimplemented) PutMetadata(w http.ResponseWriter, r *http.Request, id string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить список метрик
+// (GET /api/metrics)
+func (_ Unimplemented) ListMetrics(w http.ResponseWriter, r *http.Request, params ListMetricsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить подавления уведомлений
+// (GET /api/silences)
+func (_ Unimplemented) GetSilences(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Создать подавление уведомлений
+// (POST /api/silences)
+func (_ Unimplemented) CreateSilence(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить подавление уведомлений
+// (DELETE /api/silences/{id})
+func (_ Unimplemented) DeleteSilence(w http.ResponseWriter, r *http.Request, id string) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Поток обновлений метрик
+// (GET /api/stream)
+func (_ Unimplemented) StreamMetrics(w http.ResponseWriter, r *http.Request, params StreamMetricsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить историю метрики за интервал
+// (GET /api/v1/query_range)
+func (_ Unimplemented) GetQueryRange(w http.ResponseWriter, r *http.Request, params GetQueryRangeParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Прием метрик по протоколу Prometheus remote_write
+// (POST /api/v1/write)
+func (_ Unimplemented) RemoteWrite(w http.ResponseWriter, r *http.Request, params RemoteWriteParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // HTML-страница с документацией API
 // (GET /docs)
 func (_ Unimplemented) GetDocs(w http.ResponseWriter, r *http.Request) {
@@ -67,6 +178,24 @@ func (_ Unimplemented) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Удалить метрики по шаблону
+// (DELETE /metric)
+func (_ Unimplemented) DeleteMetrics(w http.ResponseWriter, r *http.Request, params DeleteMetricsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Удалить метрику
+// (DELETE /metric/{type}/{id})
+func (_ Unimplemented) DeleteMetric(w http.ResponseWriter, r *http.Request, mType DeleteMetricParamsType, id ID, params DeleteMetricParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Экспорт метрик в формате Prometheus
+// (GET /metrics)
+func (_ Unimplemented) GetPrometheusMetrics(w http.ResponseWriter, r *http.Request, params GetPrometheusMetricsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // OpenAPI specification
 // (GET /openapi)
 func (_ Unimplemented) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
@@ -79,9 +208,15 @@ func (_ Unimplemented) Ping(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Сбросить counter метрику
+// (POST /reset/counter/{id})
+func (_ Unimplemented) ResetCounter(w http.ResponseWriter, r *http.Request, id ID, params ResetCounterParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Обновить/создать метрику по телу запроса
 // (POST /update)
-func (_ Unimplemented) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request) {
+func (_ Unimplemented) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request, params UpdateOrCreateMetricByBodyParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -93,7 +228,19 @@ func (_ Unimplemented) UpdateOrCreateMetricByParam(w http.ResponseWriter, r *htt
 
 // Обновить/создать несколько метрик по телу запроса
 // (POST /updates)
-func (_ Unimplemented) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request) {
+func (_ Unimplemented) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request, params UpdateOrCreateMetricsBatchParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Прием батчей метрик по WebSocket
+// (GET /updates/ws)
+func (_ Unimplemented) UpdateMetricsWebSocket(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Прием метрик по протоколу OTLP/HTTP
+// (POST /v1/metrics)
+func (_ Unimplemented) OtlpMetrics(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -105,7 +252,13 @@ func (_ Unimplemented) GetValueByBody(w http.ResponseWriter, r *http.Request) {
 
 // Получить значение метрики по параметрам
 // (GET /value/{type}/{id})
-func (_ Unimplemented) GetValueByParam(w http.ResponseWriter, r *http.Request, mType GetValueByParamParamsType, id ID) {
+func (_ Unimplemented) GetValueByParam(w http.ResponseWriter, r *http.Request, mType GetValueByParamParamsType, id ID, params GetValueByParamParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Прием метрик в формате InfluxDB line protocol
+// (POST /write)
+func (_ Unimplemented) InfluxWrite(w http.ResponseWriter, r *http.Request, params InfluxWriteParams) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
@@ -131,6 +284,338 @@ func (siw *ServerInterfaceWrapper) GetAllMetrics(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r)
 }
 
+// GetAlerts operation middleware
+func (siw *ServerInterfaceWrapper) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAlerts(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetMetadata(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetMetadata(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PutMetadata operation middleware
+func (siw *ServerInterfaceWrapper) PutMetadata(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutMetadata(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListMetrics operation middleware
+func (siw *ServerInterfaceWrapper) ListMetrics(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListMetricsParams
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "prefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "prefix", r.URL.Query(), &params.Prefix)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "prefix", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "match" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "match", r.URL.Query(), &params.Match)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "match", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListMetrics(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetSilences operation middleware
+func (siw *ServerInterfaceWrapper) GetSilences(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSilences(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateSilence operation middleware
+func (siw *ServerInterfaceWrapper) CreateSilence(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateSilence(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteSilence operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSilence(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSilence(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// StreamMetrics operation middleware
+func (siw *ServerInterfaceWrapper) StreamMetrics(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamMetricsParams
+
+	// ------------- Optional query parameter "id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "id", r.URL.Query(), &params.Id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamMetrics(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetQueryRange operation middleware
+func (siw *ServerInterfaceWrapper) GetQueryRange(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetQueryRangeParams
+
+	// ------------- Required query parameter "id" -------------
+
+	if paramValue := r.URL.Query().Get("id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "id", r.URL.Query(), &params.Id)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "type" -------------
+
+	if paramValue := r.URL.Query().Get("type"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "type"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "start" -------------
+
+	if paramValue := r.URL.Query().Get("start"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "start"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "start", r.URL.Query(), &params.Start)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "end" -------------
+
+	if paramValue := r.URL.Query().Get("end"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "end"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "end", r.URL.Query(), &params.End)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "end", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "step" -------------
+
+	if paramValue := r.URL.Query().Get("step"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "step"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "step", r.URL.Query(), &params.Step)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "step", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetQueryRange(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// RemoteWrite operation middleware
+func (siw *ServerInterfaceWrapper) RemoteWrite(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RemoteWriteParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Content-Encoding" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Content-Encoding")]; found {
+		var ContentEncoding string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Content-Encoding", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Content-Encoding", valueList[0], &ContentEncoding, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Content-Encoding", Err: err})
+			return
+		}
+
+		params.ContentEncoding = &ContentEncoding
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RemoteWrite(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetDocs operation middleware
 func (siw *ServerInterfaceWrapper) GetDocs(w http.ResponseWriter, r *http.Request) {
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -157,6 +642,117 @@ func (siw *ServerInterfaceWrapper) HealthCheck(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
+// DeleteMetrics operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMetrics(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteMetricsParams
+
+	// ------------- Required query parameter "pattern" -------------
+
+	if paramValue := r.URL.Query().Get("pattern"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pattern"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pattern", r.URL.Query(), &params.Pattern)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pattern", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteMetrics(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// DeleteMetric operation middleware
+func (siw *ServerInterfaceWrapper) DeleteMetric(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// ------------- Path parameter "type" -------------
+	var mType DeleteMetricParamsType
+
+	err = runtime.BindStyledParameterWithOptions("simple", "type", chi.URLParam(r, "type"), &mType, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteMetricParams
+
+	// ------------- Optional query parameter "labels" -------------
+
+	err = runtime.BindQueryParameter("deepObject", true, false, "labels", r.URL.Query(), &params.Labels)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "labels", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteMetric(w, r, mType, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPrometheusMetrics operation middleware
+func (siw *ServerInterfaceWrapper) GetPrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPrometheusMetricsParams
+
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPrometheusMetrics(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetOpenAPI operation middleware
 func (siw *ServerInterfaceWrapper) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -183,10 +779,71 @@ func (siw *ServerInterfaceWrapper) Ping(w http.ResponseWriter, r *http.Request)
 	handler.ServeHTTP(w, r)
 }
 
+// ResetCounter operation middleware
+func (siw *ServerInterfaceWrapper) ResetCounter(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id ID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ResetCounterParams
+
+	// ------------- Optional query parameter "labels" -------------
+
+	err = runtime.BindQueryParameter("deepObject", true, false, "labels", r.URL.Query(), &params.Labels)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "labels", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ResetCounter(w, r, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // UpdateOrCreateMetricByBody operation middleware
 func (siw *ServerInterfaceWrapper) UpdateOrCreateMetricByBody(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateOrCreateMetricByBodyParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.UpdateOrCreateMetricByBody(w, r)
+		siw.Handler.UpdateOrCreateMetricByBody(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -240,8 +897,68 @@ func (siw *ServerInterfaceWrapper) UpdateOrCreateMetricByParam(w http.ResponseWr
 
 // UpdateOrCreateMetricsBatch operation middleware
 func (siw *ServerInterfaceWrapper) UpdateOrCreateMetricsBatch(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateOrCreateMetricsBatchParams
+
+	// ------------- Optional query parameter "partial" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "partial", r.URL.Query(), &params.Partial)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "partial", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.UpdateOrCreateMetricsBatch(w, r)
+		siw.Handler.UpdateOrCreateMetricsBatch(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// UpdateMetricsWebSocket operation middleware
+func (siw *ServerInterfaceWrapper) UpdateMetricsWebSocket(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.UpdateMetricsWebSocket(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// OtlpMetrics operation middleware
+func (siw *ServerInterfaceWrapper) OtlpMetrics(w http.ResponseWriter, r *http.Request) {
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.OtlpMetrics(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -286,8 +1003,53 @@ func (siw *ServerInterfaceWrapper) GetValueByParam(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetValueByParamParams
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetValueByParam(w, r, mType, id)
+		siw.Handler.GetValueByParam(w, r, mType, id, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// InfluxWrite operation middleware
+func (siw *ServerInterfaceWrapper) InfluxWrite(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params InfluxWriteParams
+
+	// ------------- Optional query parameter "db" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "db", r.URL.Query(), &params.Db)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "db", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "precision" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "precision", r.URL.Query(), &params.Precision)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "precision", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.InfluxWrite(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -413,18 +1175,60 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/", wrapper.GetAllMetrics)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/alerts", wrapper.GetAlerts)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/metadata", wrapper.GetMetadata)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/api/metadata/{id}", wrapper.PutMetadata)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/metrics", wrapper.ListMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/silences", wrapper.GetSilences)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/api/silences", wrapper.CreateSilence)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/api/silences/{id}", wrapper.DeleteSilence)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/stream", wrapper.StreamMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/api/v1/query_range", wrapper.GetQueryRange)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/api/v1/write", wrapper.RemoteWrite)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/docs", wrapper.GetDocs)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/health", wrapper.HealthCheck)
 	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/metric", wrapper.DeleteMetrics)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/metric/{type}/{id}", wrapper.DeleteMetric)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/metrics", wrapper.GetPrometheusMetrics)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/openapi", wrapper.GetOpenAPI)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/ping", wrapper.Ping)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/reset/counter/{id}", wrapper.ResetCounter)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/update", wrapper.UpdateOrCreateMetricByBody)
 	})
@@ -434,12 +1238,21 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/updates", wrapper.UpdateOrCreateMetricsBatch)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/updates/ws", wrapper.UpdateMetricsWebSocket)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/v1/metrics", wrapper.OtlpMetrics)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/value", wrapper.GetValueByBody)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/value/{type}/{id}", wrapper.GetValueByParam)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/write", wrapper.InfluxWrite)
+	})
 
 	return r
 }
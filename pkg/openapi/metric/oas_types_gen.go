@@ -3,24 +3,85 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.0 DO NOT EDIT.
 package openapi
 
+import (
+	"time"
+)
+
+// Defines values for AlertState.
+const (
+	Firing   AlertState = "firing"
+	Pending  AlertState = "pending"
+	Resolved AlertState = "resolved"
+)
+
 // Defines values for MType.
 const (
-	MTypeCounter MType = "counter"
-	MTypeGauge   MType = "gauge"
+	MTypeCounter   MType = "counter"
+	MTypeGauge     MType = "gauge"
+	MTypeHistogram MType = "histogram"
+	MTypeSummary   MType = "summary"
+)
+
+// Defines values for DeleteMetricParamsType.
+const (
+	DeleteMetricParamsTypeCounter   DeleteMetricParamsType = "counter"
+	DeleteMetricParamsTypeGauge     DeleteMetricParamsType = "gauge"
+	DeleteMetricParamsTypeHistogram DeleteMetricParamsType = "histogram"
+	DeleteMetricParamsTypeSummary   DeleteMetricParamsType = "summary"
 )
 
 // Defines values for UpdateOrCreateMetricByParamParamsType.
 const (
-	UpdateOrCreateMetricByParamParamsTypeCounter UpdateOrCreateMetricByParamParamsType = "counter"
-	UpdateOrCreateMetricByParamParamsTypeGauge   UpdateOrCreateMetricByParamParamsType = "gauge"
+	UpdateOrCreateMetricByParamParamsTypeCounter   UpdateOrCreateMetricByParamParamsType = "counter"
+	UpdateOrCreateMetricByParamParamsTypeGauge     UpdateOrCreateMetricByParamParamsType = "gauge"
+	UpdateOrCreateMetricByParamParamsTypeHistogram UpdateOrCreateMetricByParamParamsType = "histogram"
+	UpdateOrCreateMetricByParamParamsTypeSummary   UpdateOrCreateMetricByParamParamsType = "summary"
 )
 
 // Defines values for GetValueByParamParamsType.
 const (
-	Counter GetValueByParamParamsType = "counter"
-	Gauge   GetValueByParamParamsType = "gauge"
+	GetValueByParamParamsTypeCounter   GetValueByParamParamsType = "counter"
+	GetValueByParamParamsTypeGauge     GetValueByParamParamsType = "gauge"
+	GetValueByParamParamsTypeHistogram GetValueByParamParamsType = "histogram"
+	GetValueByParamParamsTypeSummary   GetValueByParamParamsType = "summary"
 )
 
+// Alert defines model for alert.
+type Alert struct {
+	// ActiveAt Время, когда условие начало выполняться
+	ActiveAt *time.Time `json:"active_at,omitempty"`
+
+	// Comparison Оператор сравнения с порогом (>, >=, <, <=, ==, !=) или absent для правил отсутствия (порог и значение в секундах)
+	Comparison string `json:"comparison"`
+
+	// FiredAt Время перехода в firing
+	FiredAt *time.Time `json:"fired_at,omitempty"`
+
+	// MetricId Идентификатор метрики
+	MetricId string `json:"metric_id"`
+
+	// MetricType Тип метрики
+	MetricType string `json:"metric_type"`
+
+	// ResolvedAt Время перехода в resolved
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// Rule Имя правила алертинга
+	Rule string `json:"rule"`
+
+	// State Состояние алерта
+	State AlertState `json:"state"`
+
+	// Threshold Пороговое значение
+	Threshold float64 `json:"threshold"`
+
+	// Value Последнее вычисленное значение метрики
+	Value *float64 `json:"value,omitempty"`
+}
+
+// AlertState Состояние алерта
+type AlertState string
+
 // BadRequestError defines model for bad_request_error.
 type BadRequestError struct {
 	// Code HTTP-код ошибки
@@ -30,6 +91,33 @@ type BadRequestError struct {
 	Message string `json:"message"`
 }
 
+// BatchItemResult defines model for batch_item_result.
+type BatchItemResult struct {
+	// Error Причина отклонения метрики
+	Error *string `json:"error,omitempty"`
+
+	// Id Идентификатор метрики
+	Id string `json:"id"`
+
+	// Index Позиция метрики в батче (с нуля)
+	Index int `json:"index"`
+
+	// Status Результат обработки метрики - ok или error
+	Status string `json:"status"`
+
+	// Type Тип метрики
+	Type string `json:"type"`
+}
+
+// DeleteResult defines model for delete_result.
+type DeleteResult struct {
+	// Deleted Число удаленных серий
+	Deleted int `json:"deleted"`
+
+	// Metrics Идентификаторы удаленных серий вида id{k="v"}
+	Metrics []string `json:"metrics"`
+}
+
 // GenericError defines model for generic_error.
 type GenericError struct {
 	// Message Описание ошибки
@@ -39,6 +127,21 @@ type GenericError struct {
 	Status int `json:"status"`
 }
 
+// Histogram Значение histogram метрик. При обновлении числа наблюдений складываются с сохраненными, если границы бакетов совпадают
+type Histogram struct {
+	// Bounds Верхние границы бакетов, строго возрастают
+	Bounds []float64 `json:"bounds"`
+
+	// Count Общее число наблюдений
+	Count uint64 `json:"count"`
+
+	// Counts Числа наблюдений по бакетам; последний элемент - бакет +Inf
+	Counts []uint64 `json:"counts"`
+
+	// Sum Сумма наблюдений
+	Sum float64 `json:"sum"`
+}
+
 // InternalServerError defines model for internal_server_error.
 type InternalServerError struct {
 	// Code HTTP-код ошибки
@@ -48,6 +151,36 @@ type InternalServerError struct {
 	Message string `json:"message"`
 }
 
+// Metadata defines model for metadata.
+type Metadata struct {
+	// Description Что означает метрика
+	Description *string `json:"description,omitempty"`
+
+	// Id Идентификатор метрики; описание относится ко всем ее типам и сериям
+	Id string `json:"id"`
+
+	// Owner Команда или человек, отвечающие за метрику
+	Owner *string `json:"owner,omitempty"`
+
+	// Unit Единица измерения
+	Unit *string `json:"unit,omitempty"`
+
+	// UpdatedAt Время последнего изменения описания
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MetadataRequest defines model for metadata_request.
+type MetadataRequest struct {
+	// Description Что означает метрика
+	Description *string `json:"description,omitempty"`
+
+	// Owner Команда или человек, отвечающие за метрику
+	Owner *string `json:"owner,omitempty"`
+
+	// Unit Единица измерения, например bytes или seconds
+	Unit *string `json:"unit,omitempty"`
+}
+
 // Metric defines model for metric.
 type Metric struct {
 	// Delta Значение для counter метрик (накопительное)
@@ -56,9 +189,20 @@ type Metric struct {
 	// Hash Хеш для проверки целостности данных
 	Hash *string `json:"hash,omitempty"`
 
+	// Histogram Значение histogram метрик. При обновлении числа наблюдений складываются с сохраненными, если границы бакетов совпадают
+	Histogram *Histogram `json:"histogram,omitempty"`
+
 	// Id Идентификатор метрики
 	Id string `json:"id"`
 
+	// Labels Лейблы метрики; вместе с id и type определяют серию. Необязательны
+	Labels *map[string]string `json:"labels,omitempty"`
+
+	// Summary Значение summary метрик - DDSketch. Положительное наблюдение x учитывается в бине ceil(log_g(x)),
+	// где g = (1+accuracy)/(1-accuracy); отрицательные - по модулю в negative, близкие к нулю - в zero.
+	// При обновлении sketch с одинаковой точностью объединяются
+	Summary *Summary `json:"summary,omitempty"`
+
 	// Type Тип метрики
 	Type string `json:"type"`
 
@@ -71,18 +215,41 @@ type MetricByBodyRequest struct {
 	// Id Идентификатор метрики
 	Id string `json:"id"`
 
+	// Labels Лейблы метрики; вместе с id и type определяют серию. Необязательны
+	Labels *map[string]string `json:"labels,omitempty"`
+
 	// Type Тип метрики
 	Type string `json:"type"`
 }
 
+// MetricList defines model for metric_list.
+type MetricList struct {
+	// Metrics Метрики страницы
+	Metrics []Metric `json:"metrics"`
+
+	// NextCursor Курсор следующей страницы; отсутствует на последней странице
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
 // MetricRequest defines model for metric_request.
 type MetricRequest struct {
 	// Delta Значение для counter метрик (накопительное)
 	Delta *int `json:"delta,omitempty"`
 
+	// Histogram Значение histogram метрик. При обновлении числа наблюдений складываются с сохраненными, если границы бакетов совпадают
+	Histogram *Histogram `json:"histogram,omitempty"`
+
 	// Id Идентификатор метрики
 	Id string `json:"id"`
 
+	// Labels Лейблы метрики; вместе с id и type определяют серию. Необязательны
+	Labels *map[string]string `json:"labels,omitempty"`
+
+	// Summary Значение summary метрик - DDSketch. Положительное наблюдение x учитывается в бине ceil(log_g(x)),
+	// где g = (1+accuracy)/(1-accuracy); отрицательные - по модулю в negative, близкие к нулю - в zero.
+	// При обновлении sketch с одинаковой точностью объединяются
+	Summary *Summary `json:"summary,omitempty"`
+
 	// Type Тип метрики
 	Type string `json:"type"`
 
@@ -97,29 +264,282 @@ type NotFoundError struct {
 	Status  int    `json:"status"`
 }
 
+// PartialWriteError defines model for partial_write_error.
+type PartialWriteError struct {
+	// Code HTTP-код ошибки
+	Code int `json:"code"`
+
+	// Errors Отклоненные строки
+	Errors []struct {
+		// Error Причина отклонения строки
+		Error string `json:"error"`
+
+		// Line Номер строки (с единицы)
+		Line int `json:"line"`
+	} `json:"errors"`
+
+	// Message Описание ошибки
+	Message string `json:"message"`
+}
+
+// RangeResult defines model for range_result.
+type RangeResult struct {
+	// Datapoints Точки в формате [значение, unix-время в миллисекундах]
+	Datapoints [][]float64 `json:"datapoints"`
+
+	// Target Идентификатор метрики
+	Target string `json:"target"`
+
+	// Type Тип метрики
+	Type string `json:"type"`
+}
+
+// Silence defines model for silence.
+type Silence struct {
+	// Comment Причина подавления
+	Comment *string `json:"comment,omitempty"`
+
+	// CreatedAt Время создания подавления
+	CreatedAt time.Time `json:"created_at"`
+
+	// EndsAt Окончание действия подавления
+	EndsAt time.Time `json:"ends_at"`
+
+	// Id Идентификатор подавления
+	Id string `json:"id"`
+
+	// IsRegex Признак того, что metric_id - регулярное выражение, совпадающее с идентификатором целиком
+	IsRegex bool `json:"is_regex"`
+
+	// MetricId Идентификатор метрики или регулярное выражение
+	MetricId string `json:"metric_id"`
+
+	// StartsAt Начало действия подавления
+	StartsAt time.Time `json:"starts_at"`
+}
+
+// SilenceRequest defines model for silence_request.
+type SilenceRequest struct {
+	// Comment Причина подавления
+	Comment *string `json:"comment,omitempty"`
+
+	// EndsAt Окончание действия подавления
+	EndsAt time.Time `json:"ends_at"`
+
+	// IsRegex Признак того, что metric_id - регулярное выражение, совпадающее с идентификатором целиком
+	IsRegex *bool `json:"is_regex,omitempty"`
+
+	// MetricId Идентификатор метрики или регулярное выражение
+	MetricId string `json:"metric_id"`
+
+	// StartsAt Начало действия подавления (по умолчанию - текущее время)
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+}
+
+// SketchBins Плотное хранилище бинов sketch, не более 2048 бинов
+type SketchBins struct {
+	// Counts Числа наблюдений в бинах offset, offset+1, ...
+	Counts []uint64 `json:"counts"`
+
+	// Offset Индекс первого бина
+	Offset int `json:"offset"`
+}
+
+// Summary Значение summary метрик - DDSketch. Положительное наблюдение x учитывается в бине ceil(log_g(x)),
+// где g = (1+accuracy)/(1-accuracy); отрицательные - по модулю в negative, близкие к нулю - в zero.
+// При обновлении sketch с одинаковой точностью объединяются
+type Summary struct {
+	// Accuracy Относительная точность квантилей, из (0, 1)
+	Accuracy float64 `json:"accuracy"`
+
+	// Count Общее число наблюдений
+	Count uint64 `json:"count"`
+
+	// Max Максимальное наблюдение
+	Max float64 `json:"max"`
+
+	// Min Минимальное наблюдение
+	Min float64 `json:"min"`
+
+	// Negative Плотное хранилище бинов sketch, не более 2048 бинов
+	Negative SketchBins `json:"negative"`
+
+	// Positive Плотное хранилище бинов sketch, не более 2048 бинов
+	Positive SketchBins `json:"positive"`
+
+	// Sum Сумма наблюдений
+	Sum float64 `json:"sum"`
+
+	// Zero Число наблюдений, близких к нулю
+	Zero uint64 `json:"zero"`
+}
+
 // ID defines model for id.
 type ID = string
 
+// IdempotencyKey defines model for idempotency_key.
+type IdempotencyKey = string
+
+// Labels defines model for labels.
+type Labels map[string]string
+
 // MType defines model for type.
 type MType string
 
 // Value defines model for value.
 type Value = string
 
+// ListMetricsParams defines parameters for ListMetrics.
+type ListMetricsParams struct {
+	// Type Тип метрики
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Prefix Префикс id метрики
+	Prefix *string `form:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// Match Регулярное выражение для id метрики. Экранировать можно только знаки пунктуации, из групп (?...) допускается только (?:...), классы вроде \d и [[:alpha:]] не поддерживаются
+	Match *string `form:"match,omitempty" json:"match,omitempty"`
+
+	// Sort Поле сортировки (id, type или updated_at); префикс "-" - по убыванию
+	Sort *string `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit Размер страницы
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor Курсор следующей страницы из предыдущего ответа
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// StreamMetricsParams defines parameters for StreamMetrics.
+type StreamMetricsParams struct {
+	// Id Идентификатор метрики
+	Id *string `form:"id,omitempty" json:"id,omitempty"`
+
+	// Type Тип метрики
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+}
+
+// GetQueryRangeParams defines parameters for GetQueryRange.
+type GetQueryRangeParams struct {
+	// Id Идентификатор метрики
+	Id string `form:"id" json:"id"`
+
+	// Type Тип метрики (gauge или counter)
+	Type string `form:"type" json:"type"`
+
+	// Start Начало интервала (unix-время в секундах или RFC 3339)
+	Start string `form:"start" json:"start"`
+
+	// End Конец интервала (unix-время в секундах или RFC 3339)
+	End string `form:"end" json:"end"`
+
+	// Step Шаг (длительность, например 15s, или число секунд)
+	Step string `form:"step" json:"step"`
+}
+
+// RemoteWriteParams defines parameters for RemoteWrite.
+type RemoteWriteParams struct {
+	// ContentEncoding Ожидается snappy
+	ContentEncoding *string `json:"Content-Encoding,omitempty"`
+}
+
+// DeleteMetricsParams defines parameters for DeleteMetrics.
+type DeleteMetricsParams struct {
+	// Pattern Регулярное выражение (синтаксис RE2) для id метрик, например ^Random
+	Pattern string `form:"pattern" json:"pattern"`
+
+	// Type Удалять только метрики этого типа
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+}
+
+// DeleteMetricParams defines parameters for DeleteMetric.
+type DeleteMetricParams struct {
+	// Labels Лейблы серии в виде labels[имя]=значение; без них - серия без лейблов
+	Labels *Labels `form:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// DeleteMetricParamsType defines parameters for DeleteMetric.
+type DeleteMetricParamsType string
+
+// GetPrometheusMetricsParams defines parameters for GetPrometheusMetrics.
+type GetPrometheusMetricsParams struct {
+	// Type Фильтр по типу метрики (gauge или counter)
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+}
+
+// ResetCounterParams defines parameters for ResetCounter.
+type ResetCounterParams struct {
+	// Labels Лейблы серии в виде labels[имя]=значение; без них - серия без лейблов
+	Labels *Labels `form:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// UpdateOrCreateMetricByBodyParams defines parameters for UpdateOrCreateMetricByBody.
+type UpdateOrCreateMetricByBodyParams struct {
+	// IdempotencyKey Ключ идемпотентности. Повтор запроса с тем же ключом в течение срока
+	// хранения ключа получает сохраненный ответ, а метрики не обновляются
+	// повторно
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
 // UpdateOrCreateMetricByParamParamsType defines parameters for UpdateOrCreateMetricByParam.
 type UpdateOrCreateMetricByParamParamsType string
 
 // UpdateOrCreateMetricsBatchJSONBody defines parameters for UpdateOrCreateMetricsBatch.
 type UpdateOrCreateMetricsBatchJSONBody = []MetricRequest
 
+// UpdateOrCreateMetricsBatchParams defines parameters for UpdateOrCreateMetricsBatch.
+type UpdateOrCreateMetricsBatchParams struct {
+	// Partial Частичная запись: корректные метрики сохраняются, некорректные
+	// пропускаются, а в ответе возвращается результат по каждой метрике
+	Partial *bool `form:"partial,omitempty" json:"partial,omitempty"`
+
+	// IdempotencyKey Ключ идемпотентности. Повтор запроса с тем же ключом в течение срока
+	// хранения ключа получает сохраненный ответ, а метрики не обновляются
+	// повторно
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// OtlpMetricsJSONBody defines parameters for OtlpMetrics.
+type OtlpMetricsJSONBody = map[string]interface{}
+
+// GetValueByParamParams defines parameters for GetValueByParam.
+type GetValueByParamParams struct {
+	// Q Квантиль из [0, 1] для summary метрик, например 0.99; без него возвращаются count и sum
+	Q *float64 `form:"q,omitempty" json:"q,omitempty"`
+}
+
 // GetValueByParamParamsType defines parameters for GetValueByParam.
 type GetValueByParamParamsType string
 
+// InfluxWriteTextBody defines parameters for InfluxWrite.
+type InfluxWriteTextBody = string
+
+// InfluxWriteParams defines parameters for InfluxWrite.
+type InfluxWriteParams struct {
+	// Db Имя базы InfluxDB (игнорируется, поддерживается для совместимости)
+	Db *string `form:"db,omitempty" json:"db,omitempty"`
+
+	// Precision Единица timestamp (ns, us, ms, s), по умолчанию ns
+	Precision *string `form:"precision,omitempty" json:"precision,omitempty"`
+}
+
+// PutMetadataJSONRequestBody defines body for PutMetadata for application/json ContentType.
+type PutMetadataJSONRequestBody = MetadataRequest
+
+// CreateSilenceJSONRequestBody defines body for CreateSilence for application/json ContentType.
+type CreateSilenceJSONRequestBody = SilenceRequest
+
 // UpdateOrCreateMetricByBodyJSONRequestBody defines body for UpdateOrCreateMetricByBody for application/json ContentType.
 type UpdateOrCreateMetricByBodyJSONRequestBody = MetricRequest
 
 // UpdateOrCreateMetricsBatchJSONRequestBody defines body for UpdateOrCreateMetricsBatch for application/json ContentType.
 type UpdateOrCreateMetricsBatchJSONRequestBody = UpdateOrCreateMetricsBatchJSONBody
 
+// OtlpMetricsJSONRequestBody defines body for OtlpMetrics for application/json ContentType.
+type OtlpMetricsJSONRequestBody = OtlpMetricsJSONBody
+
 // GetValueByBodyJSONRequestBody defines body for GetValueByBody for application/json ContentType.
 type GetValueByBodyJSONRequestBody = MetricByBodyRequest
+
+// InfluxWriteTextRequestBody defines body for InfluxWrite for text/plain ContentType.
+type InfluxWriteTextRequestBody = InfluxWriteTextBody
@@ -0,0 +1,45 @@
+package metricpb
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bigsm0uk/metrics-alert-server/pkg/util/hasher"
+)
+
+// HashMetadataKey - ключ gRPC-метаданных с хешем ответа (аналог заголовка HashSHA256).
+const HashMetadataKey = "hashsha256"
+
+// MessageHash считает HashSHA256 от детерминированной сериализации сообщения.
+func MessageHash(m proto.Message, key string) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(string(b), key), nil
+}
+
+// Sign заполняет Hash запроса.
+func (x *UpdateMetricsRequest) Sign(key string) error {
+	x.Hash = ""
+	h, err := MessageHash(x, key)
+	if err != nil {
+		return err
+	}
+	x.Hash = h
+	return nil
+}
+
+// Verify проверяет Hash запроса. Запрос без хеша считается корректным,
+// как и HTTP-запрос без заголовка HashSHA256.
+func (x *UpdateMetricsRequest) Verify(key string) (bool, error) {
+	if x.GetHash() == "" {
+		return true, nil
+	}
+	unsigned := proto.Clone(x).(*UpdateMetricsRequest)
+	unsigned.Hash = ""
+	h, err := MessageHash(unsigned, key)
+	if err != nil {
+		return false, err
+	}
+	return h == x.GetHash(), nil
+}
@@ -0,0 +1,5 @@
+package metricpb
+
+// IdempotencyKeyMetadataKey - ключ gRPC-метаданных с ключом идемпотентности
+// запроса (аналог заголовка Idempotency-Key).
+const IdempotencyKeyMetadataKey = "idempotency-key"